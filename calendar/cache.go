@@ -31,6 +31,9 @@ type CalendarCacheInterface interface {
 	// The third return value is any errors
 	Get(url string) ([]byte, error)
 
+	// GetStale finds a cached calendar entry, however old it is
+	GetStale(url string) ([]byte, error)
+
 	// Put stores a calendar content in the cache
 	Put(url string, content []byte) error
 }
@@ -80,6 +83,27 @@ func (c *CalendarCache) Get(url string) ([]byte, error) {
 	}
 }
 
+// GetStale finds a cached calendar entry, even when it has expired,
+// for when an old copy of a calendar is better than fetching it again.
+// It returns an ErrCacheMiss when there isn't an entry.
+func (c *CalendarCache) GetStale(url string) ([]byte, error) {
+	key := c.CacheKey(url)
+	content, err := ioutil.ReadFile(path.Join(c.dir, key))
+	if err != nil {
+		reason := "unknown"
+		if errors.Is(err, os.ErrNotExist) {
+			reason = "not found"
+		}
+
+		return []byte{}, &ErrCacheMiss{
+			Key:    key,
+			Reason: reason,
+		}
+	}
+
+	return content, nil
+}
+
 // Put stores a calendar content in the cache
 func (c *CalendarCache) Put(url string, content []byte) error {
 	key := c.CacheKey(url)
@@ -106,4 +106,26 @@ var _ = Describe("CalendarCache", func() {
 			})
 		})
 	})
+
+	Describe("GetStale", func() {
+		It("returns the content of the cached calendar, even when it has expired", func() {
+			url := "https://example.com"
+			content := "BEGIN:VCALENDAR END:VCALENDAR"
+			cachePath := path.Join(cacheDir, cache.CacheKey(url))
+
+			err := ioutil.WriteFile(cachePath, []byte(content), 0600)
+			Expect(err).ToNot(HaveOccurred())
+			os.Chtimes(cachePath, time.Now(), time.Now().Add(-6*time.Hour))
+
+			cacheContent, err := cache.GetStale(url)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(cacheContent)).To(Equal(content))
+		})
+
+		It("returns an ErrCacheMiss with a reason of 'not found' when the key doesn't exist", func() {
+			_, err := cache.GetStale("unknown")
+			Expect(err).To(BeAssignableToTypeOf(&ErrCacheMiss{}))
+			Expect(err.(*ErrCacheMiss).Reason).To(Equal("not found"))
+		})
+	})
 })
@@ -1,7 +1,14 @@
 package calendar
 
+import "time"
+
 type CalendarRecord struct {
 	Id          int
 	DisplayName string `db:"display_name"`
 	URL         string `db:"calendar_url"`
+
+	// The buffers before and after this calendar's meetings.
+	// The global buffers are used when they are nil.
+	BufferBefore *time.Duration `db:"buffer_before"`
+	BufferAfter  *time.Duration `db:"buffer_after"`
 }
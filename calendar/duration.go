@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Duration format as defined in RFC 5545
+// https://www.rfc-editor.org/rfc/rfc5545#section-3.3.6
+var RegexIcalDuration *regexp.Regexp = regexp.MustCompile(
+	"^([+-])?P(?:([0-9]+)W|([0-9]+)D(?:T([0-9]+H(?:[0-9]+M(?:[0-9]+S)?)?|[0-9]+M(?:[0-9]+S)?|[0-9]+S))?|T([0-9]+H(?:[0-9]+M(?:[0-9]+S)?)?|[0-9]+M(?:[0-9]+S)?|[0-9]+S))$",
+)
+
+var regexIcalDurationTimePart *regexp.Regexp = regexp.MustCompile("([0-9]+)([HMS])")
+
+// Duration is a parsed RFC 5545 DURATION value.
+//
+// Weeks and days are nominal durations, which means that adding them to
+// a time keeps its wall clock time, even across daylight saving changes.
+// Hours, minutes and seconds are exact durations.
+type Duration struct {
+	Negative bool
+	Weeks    int
+	Days     int
+	Time     time.Duration
+}
+
+// ParseDuration parses the value of a DURATION property, such as
+// "PT1H30M", "P1D", "P1DT12H", "P2W" or "-PT15M"
+func ParseDuration(value string) (Duration, error) {
+	matched := RegexIcalDuration.FindStringSubmatch(value)
+	if matched == nil {
+		return Duration{}, fmt.Errorf("invalid duration '%s'", value)
+	}
+
+	duration := Duration{
+		Negative: matched[1] == "-",
+	}
+
+	var err error
+	if matched[2] != "" {
+		duration.Weeks, err = strconv.Atoi(matched[2])
+		if err != nil {
+			return Duration{}, fmt.Errorf("invalid duration '%s': %s", value, err)
+		}
+	}
+
+	if matched[3] != "" {
+		duration.Days, err = strconv.Atoi(matched[3])
+		if err != nil {
+			return Duration{}, fmt.Errorf("invalid duration '%s': %s", value, err)
+		}
+	}
+
+	timePart := matched[4] + matched[5]
+	units := map[string]time.Duration{
+		"H": time.Hour,
+		"M": time.Minute,
+		"S": time.Second,
+	}
+	for _, component := range regexIcalDurationTimePart.FindAllStringSubmatch(timePart, -1) {
+		amount, err := strconv.Atoi(component[1])
+		if err != nil {
+			return Duration{}, fmt.Errorf("invalid duration '%s': %s", value, err)
+		}
+
+		duration.Time += time.Duration(amount) * units[component[2]]
+	}
+
+	return duration, nil
+}
+
+// AddTo returns the time at the end of the duration starting at t
+func (d Duration) AddTo(t time.Time) time.Time {
+	sign := 1
+	if d.Negative {
+		sign = -1
+	}
+
+	return t.AddDate(0, 0, sign*(d.Weeks*7+d.Days)).Add(time.Duration(sign) * d.Time)
+}
+
+// IsWholeDays returns true when the duration is made only of weeks and
+// days, which is the only form allowed for events that start on a DATE
+func (d Duration) IsWholeDays() bool {
+	return d.Time == 0
+}
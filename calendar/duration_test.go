@@ -0,0 +1,72 @@
+package calendar_test
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duration", func() {
+	DescribeTable("ParseDuration",
+		func(input string, expected calendar.Duration) {
+			actual, err := calendar.ParseDuration(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("hours and minutes", "PT1H30M", calendar.Duration{Time: 90 * time.Minute}),
+		Entry("minutes", "PT15M", calendar.Duration{Time: 15 * time.Minute}),
+		Entry("seconds", "PT45S", calendar.Duration{Time: 45 * time.Second}),
+		Entry("hours, minutes and seconds", "PT1H2M3S", calendar.Duration{Time: time.Hour + 2*time.Minute + 3*time.Second}),
+		Entry("days", "P1D", calendar.Duration{Days: 1}),
+		Entry("days and time", "P1DT12H", calendar.Duration{Days: 1, Time: 12 * time.Hour}),
+		Entry("weeks", "P2W", calendar.Duration{Weeks: 2}),
+		Entry("an explicitly positive duration", "+PT10M", calendar.Duration{Time: 10 * time.Minute}),
+		Entry("a negative duration", "-PT15M", calendar.Duration{Negative: true, Time: 15 * time.Minute}),
+	)
+
+	DescribeTable("ParseDuration rejects values that aren't RFC 5545 durations",
+		func(input string) {
+			_, err := calendar.ParseDuration(input)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("no designator", "1H"),
+		Entry("time without the T separator", "P1H"),
+		Entry("T separator without time", "P1DT"),
+		Entry("weeks mixed with days", "P1W2D"),
+		Entry("units out of order", "PT30M1H"),
+		Entry("Go duration syntax", "1h30m"),
+	)
+
+	Describe("AddTo", func() {
+		It("adds days as calendar days, keeping the wall clock time", func() {
+			london, err := time.LoadLocation("Europe/London")
+			Expect(err).ToNot(HaveOccurred())
+
+			start := time.Date(2022, time.March, 26, 9, 0, 0, 0, london)
+			duration := calendar.Duration{Days: 1}
+
+			Expect(duration.AddTo(start)).To(Equal(time.Date(2022, time.March, 27, 9, 0, 0, 0, london)))
+		})
+
+		It("adds hours as exact durations", func() {
+			london, err := time.LoadLocation("Europe/London")
+			Expect(err).ToNot(HaveOccurred())
+
+			start := time.Date(2022, time.March, 26, 9, 0, 0, 0, london)
+			duration := calendar.Duration{Time: 24 * time.Hour}
+
+			Expect(duration.AddTo(start)).To(Equal(time.Date(2022, time.March, 27, 10, 0, 0, 0, london)))
+		})
+
+		It("subtracts negative durations", func() {
+			start := time.Date(2022, time.January, 10, 9, 0, 0, 0, time.UTC)
+			duration := calendar.Duration{Negative: true, Weeks: 1, Time: 30 * time.Minute}
+
+			Expect(duration.AddTo(start)).To(Equal(time.Date(2022, time.January, 3, 8, 30, 0, 0, time.UTC)))
+		})
+	})
+})
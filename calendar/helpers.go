@@ -11,33 +11,45 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-var midnightToday = time.Now().Truncate(24 * time.Hour)
-var midnightTomorrow = midnightToday.Add(24 * time.Hour)
-
 // Date format as defined in RFC 5545
 // https://www.rfc-editor.org/rfc/rfc5545#section-3.3.4
 var RegexIcalDate *regexp.Regexp = regexp.MustCompile("^[0-9]{4}(0[1-9]|1[0-2])([0-2][0-9]|3[0-1])$")
 
-func EventStartsToday(evt *ical.VEvent) (bool, error) {
+// StartOfDay returns midnight at the start of the day
+// that t falls on, in the location of t
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// EventStartsOnDay returns true when the event starts during the
+// day that begins at the given midnight, as returned by StartOfDay
+func EventStartsOnDay(evt *ical.VEvent, day time.Time) (bool, error) {
 	start, _, err := EventStartAndEnd(evt)
 	if err != nil {
 		return false, err
 	}
 
-	startsAtMidnight := start.Equal(midnightToday)
-	startsDuringToday := start.After(midnightToday) && start.Before(midnightTomorrow)
-	return (startsAtMidnight || startsDuringToday), nil
+	return timeIsOnDay(start, day), nil
 }
 
-func EventEndsToday(evt *ical.VEvent) (bool, error) {
+// EventEndsOnDay returns true when the event ends during the day
+// that begins at the given midnight, as returned by StartOfDay
+func EventEndsOnDay(evt *ical.VEvent, day time.Time) (bool, error) {
 	_, end, err := EventStartAndEnd(evt)
 	if err != nil {
 		return false, err
 	}
 
-	endsAtMidnight := end.Equal(midnightToday)
-	endsDuringToday := end.After(midnightToday) && end.Before(midnightTomorrow)
-	return (endsAtMidnight || endsDuringToday), nil
+	return timeIsOnDay(end, day), nil
+}
+
+func timeIsOnDay(t time.Time, day time.Time) bool {
+	nextDay := day.AddDate(0, 0, 1)
+
+	isMidnight := t.Equal(day)
+	isDuringDay := t.After(day) && t.Before(nextDay)
+	return isMidnight || isDuringDay
 }
 
 func EventIsCurrentlyHappening(evt *ical.VEvent, now time.Time) (bool, error) {
@@ -65,17 +77,17 @@ func EventStartAndEnd(evt *ical.VEvent) (time.Time, time.Time, error) {
 
 	if DTSTART == nil {
 		// -DSTART
-		return tZero, tZero, fmt.Errorf("no start time specified")
+		return tZero, tZero, fmt.Errorf("no start time specified for event %s", evt.Id())
 	} else if DTSTART != nil && DTEND != nil {
 		// DTSTART + DTEND
 
 		var err error = nil
-		start, startErr := evt.GetStartAt()
+		start, _, startErr := propertyTime(DTSTART)
 		if startErr != nil {
 			err = fmt.Errorf("cannot fetch start time: %s", startErr)
 		}
 
-		end, endErr := evt.GetEndAt()
+		end, _, endErr := propertyTime(DTEND)
 		if endErr != nil {
 			// The specification in RFC 5545 says that an event
 			// with a DTSTART of type DATE-TIME and no DTEND
@@ -91,7 +103,7 @@ func EventStartAndEnd(evt *ical.VEvent) (time.Time, time.Time, error) {
 
 		startIsDate := RegexIcalDate.Match([]byte(DTSTART.Value))
 		if startIsDate {
-			start, err := evt.GetAllDayStartAt()
+			start, _, err := propertyTime(DTSTART)
 			if err != nil {
 				return tZero, tZero, err
 			}
@@ -102,7 +114,7 @@ func EventStartAndEnd(evt *ical.VEvent) (time.Time, time.Time, error) {
 			end := carbon.Time2Carbon(start).Tomorrow().StartOfDay().Carbon2Time()
 			return start, end, nil
 		} else {
-			start, err := evt.GetStartAt()
+			start, _, err := propertyTime(DTSTART)
 			if err != nil {
 				return tZero, tZero, err
 			}
@@ -115,17 +127,27 @@ func EventStartAndEnd(evt *ical.VEvent) (time.Time, time.Time, error) {
 	} else if DTSTART != nil && DURATION != nil {
 		// DTSTART + DURATION
 
-		duration, err := time.ParseDuration(strings.ToLower(DURATION.Value))
+		duration, err := ParseDuration(DURATION.Value)
 		if err != nil {
-			return tZero, tZero, fmt.Errorf("parsing duration: %s", err)
+			return tZero, tZero, fmt.Errorf("parsing duration of event %s: %s", evt.Id(), err)
+		}
+
+		if duration.Negative {
+			return tZero, tZero, fmt.Errorf("event %s has a negative duration '%s'", evt.Id(), DURATION.Value)
+		}
+
+		// https://www.rfc-editor.org/rfc/rfc5545#section-3.6.1
+		startIsDate := RegexIcalDate.Match([]byte(DTSTART.Value))
+		if startIsDate && !duration.IsWholeDays() {
+			return tZero, tZero, fmt.Errorf("event %s starts on a date but has a duration '%s' that is not in days or weeks", evt.Id(), DURATION.Value)
 		}
 
-		start, err := evt.GetStartAt()
+		start, _, err := propertyTime(DTSTART)
 		if err != nil {
 			return tZero, tZero, err
 		}
 
-		return start, start.Add(duration), nil
+		return start, duration.AddTo(start), nil
 	}
 
 	return tZero, tZero, nil
@@ -146,9 +168,17 @@ func IsAllDayEvent(evt *ical.VEvent) (bool, error) {
 
 		return startMatches && endMatches, nil
 	} else {
-		// DURATION + DTSTART replaces DTSTART + DTEND
+		// DURATION + DTSTART replaces DTSTART + DTEND,
+		// and is all day when it's a whole number of
+		// days from a start of type DATE
 		if DURATION != nil {
-			return false, nil
+			duration, err := ParseDuration(DURATION.Value)
+			if err != nil {
+				return false, fmt.Errorf("parsing duration of event %s: %s", evt.Id(), err)
+			}
+
+			startIsDate := RegexIcalDate.Match([]byte(DTSTART.Value))
+			return startIsDate && duration.IsWholeDays(), nil
 		}
 
 		// DTSTART with no DTEND or DURATION is an all day event
@@ -203,7 +233,8 @@ func FilterEvents(events []*ical.VEvent, filter func(evt *ical.VEvent) bool) []*
 	return filtered
 }
 
-func IsRecurringEventDefinition(evt *ical.VEvent) bool {
-	prop := evt.GetProperty(ical.ComponentProperty("RRULE"))
-	return prop != nil
+// IsCancelledEvent returns true when the event has a STATUS of CANCELLED
+func IsCancelledEvent(evt *ical.VEvent) bool {
+	status := evt.GetProperty(ical.ComponentPropertyStatus)
+	return status != nil && strings.EqualFold(status.Value, string(ical.ObjectStatusCancelled))
 }
@@ -33,14 +33,25 @@ func fmtDATE(t time.Time) string {
 }
 
 var _ = Describe("Helpers", func() {
-	midnightToday := time.Now().Truncate(24 * time.Hour)
+	midnightToday := calendar.StartOfDay(time.Now())
 
-	Describe("EventStartsToday", func() {
+	Describe("StartOfDay", func() {
+		It("returns midnight in the location of the time, not UTC midnight", func() {
+			newYork, err := time.LoadLocation("America/New_York")
+			Expect(err).ToNot(HaveOccurred())
+
+			actual := calendar.StartOfDay(time.Date(2022, time.October, 17, 22, 30, 0, 0, newYork))
+
+			Expect(actual).To(Equal(time.Date(2022, time.October, 17, 0, 0, 0, 0, newYork)))
+		})
+	})
+
+	Describe("EventStartsOnDay", func() {
 		It("returns false if event starts before midnight today", func() {
 			evt := ical.NewEvent("evt")
 			evt.SetStartAt(midnightToday.Add(-4 * time.Hour))
 
-			actual, err := calendar.EventStartsToday(evt)
+			actual, err := calendar.EventStartsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
@@ -49,7 +60,7 @@ var _ = Describe("Helpers", func() {
 			evt := ical.NewEvent("evt")
 			evt.SetStartAt(midnightToday.Add(36 * time.Hour))
 
-			actual, err := calendar.EventStartsToday(evt)
+			actual, err := calendar.EventStartsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
@@ -58,7 +69,7 @@ var _ = Describe("Helpers", func() {
 			evt := ical.NewEvent("evt")
 			evt.SetStartAt(midnightToday.Add(24 * time.Hour))
 
-			actual, err := calendar.EventStartsToday(evt)
+			actual, err := calendar.EventStartsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
@@ -67,7 +78,7 @@ var _ = Describe("Helpers", func() {
 			evt := ical.NewEvent("evt")
 			evt.SetStartAt(midnightToday.Add(6 * time.Hour))
 
-			actual, err := calendar.EventStartsToday(evt)
+			actual, err := calendar.EventStartsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeTrue())
 		})
@@ -76,19 +87,19 @@ var _ = Describe("Helpers", func() {
 			evt := ical.NewEvent("evt")
 			evt.SetStartAt(midnightToday)
 
-			actual, err := calendar.EventStartsToday(evt)
+			actual, err := calendar.EventStartsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeTrue())
 		})
 	})
 
-	Describe("EventEndsToday", func() {
+	Describe("EventEndsOnDay", func() {
 		It("returns false if event ends before midnight today", func() {
 			evt := ical.NewEvent("evt")
 			evt.SetStartAt(midnightToday.Add(-5 * time.Hour))
 			evt.SetEndAt(midnightToday.Add(-4 * time.Hour))
 
-			actual, err := calendar.EventEndsToday(evt)
+			actual, err := calendar.EventEndsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
@@ -98,7 +109,7 @@ var _ = Describe("Helpers", func() {
 			evt.SetStartAt(midnightToday.Add(1 * time.Hour))
 			evt.SetEndAt(midnightToday.Add(36 * time.Hour))
 
-			actual, err := calendar.EventEndsToday(evt)
+			actual, err := calendar.EventEndsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
@@ -108,7 +119,7 @@ var _ = Describe("Helpers", func() {
 			evt.SetStartAt(midnightToday.Add(1 * time.Hour))
 			evt.SetEndAt(midnightToday.Add(24 * time.Hour))
 
-			actual, err := calendar.EventEndsToday(evt)
+			actual, err := calendar.EventEndsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
@@ -118,7 +129,7 @@ var _ = Describe("Helpers", func() {
 			evt.SetStartAt(midnightToday.Add(4 * time.Hour))
 			evt.SetEndAt(midnightToday.Add(6 * time.Hour))
 
-			actual, err := calendar.EventEndsToday(evt)
+			actual, err := calendar.EventEndsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeTrue())
 		})
@@ -128,7 +139,7 @@ var _ = Describe("Helpers", func() {
 			evt.SetStartAt(midnightToday.Add(-2 * time.Hour))
 			evt.SetEndAt(midnightToday)
 
-			actual, err := calendar.EventEndsToday(evt)
+			actual, err := calendar.EventEndsOnDay(evt, midnightToday)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeTrue())
 		})
@@ -153,14 +164,14 @@ var _ = Describe("Helpers", func() {
 		})
 
 		Context("when an event has a start time of type DATE but no end time or duration", func() {
-			It("will return the start as midnight of the date, and end as the midnight of the next day", func() {
+			It("will return the start as local midnight of the date, and end as the local midnight of the next day", func() {
 				evt := ical.NewEvent("evt")
 
 				start := time.Date(2020, 01, 30, 12, 20, 00, 00, time.UTC)
 				evt.SetProperty(ical.ComponentPropertyDtStart, fmtDATE(start))
 
-				expectedStart := time.Date(2020, 01, 30, 00, 00, 00, 00, time.UTC)
-				expectedEnd := time.Date(2020, 01, 31, 00, 00, 00, 0, time.UTC)
+				expectedStart := time.Date(2020, 01, 30, 00, 00, 00, 00, time.Local)
+				expectedEnd := time.Date(2020, 01, 31, 00, 00, 00, 0, time.Local)
 
 				actualStart, actualEnd, err := calendar.EventStartAndEnd(evt)
 				Expect(err).ToNot(HaveOccurred())
@@ -176,7 +187,7 @@ var _ = Describe("Helpers", func() {
 				start := time.Date(2020, 01, 30, 12, 20, 00, 00, time.UTC)
 				evt.SetProperty(ical.ComponentPropertyDtStart, fmtDATETIME(start))
 
-				evt.SetProperty("DURATION", "30M")
+				evt.SetProperty("DURATION", "PT30M")
 
 				expectedStart := time.Date(2020, 01, 30, 12, 20, 00, 00, time.UTC)
 				expectedEnd := time.Date(2020, 01, 30, 12, 50, 00, 0, time.UTC)
@@ -188,6 +199,59 @@ var _ = Describe("Helpers", func() {
 			})
 		})
 
+		Context("when an event has a start time of type DATETIME and a duration in days", func() {
+			It("will return the end time at the same time of day, even across a daylight saving change", func() {
+				london, err := time.LoadLocation("Europe/London")
+				Expect(err).ToNot(HaveOccurred())
+
+				evt := ical.NewEvent("evt")
+				evt.SetProperty(
+					ical.ComponentPropertyDtStart,
+					"20220326T090000",
+					&ical.KeyValues{Key: "TZID", Value: []string{"Europe/London"}},
+				)
+				evt.SetProperty("DURATION", "P1DT1H")
+
+				_, actualEnd, err := calendar.EventStartAndEnd(evt)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualEnd).To(Equal(time.Date(2022, 03, 27, 10, 00, 00, 00, london)))
+			})
+		})
+
+		Context("when an event has a start time of type DATE and a duration", func() {
+			It("will return the end time as midnight at the end of the duration", func() {
+				evt := ical.NewEvent("evt")
+				evt.SetProperty(ical.ComponentPropertyDtStart, "20200130")
+				evt.SetProperty("DURATION", "P1W")
+
+				_, actualEnd, err := calendar.EventStartAndEnd(evt)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualEnd).To(Equal(time.Date(2020, 02, 06, 00, 00, 00, 00, time.Local)))
+			})
+
+			It("will return an error naming the event if the duration is not in days or weeks", func() {
+				evt := ical.NewEvent("all-day-evt")
+				evt.SetProperty(ical.ComponentPropertyDtStart, "20200130")
+				evt.SetProperty("DURATION", "PT1H")
+
+				_, _, err := calendar.EventStartAndEnd(evt)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("all-day-evt"))
+			})
+		})
+
+		Context("when an event has a duration that can't be parsed", func() {
+			It("will return an error naming the event", func() {
+				evt := ical.NewEvent("broken-evt")
+				evt.SetProperty(ical.ComponentPropertyDtStart, "20200130T122000Z")
+				evt.SetProperty("DURATION", "1 hour")
+
+				_, _, err := calendar.EventStartAndEnd(evt)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("broken-evt"))
+			})
+		})
+
 		Context("when an event has a start time of type DATETIME but no end time or duration", func() {
 			It("will return the start and end time as the same time", func() {
 				evt := ical.NewEvent("evt")
@@ -249,20 +313,31 @@ var _ = Describe("Helpers", func() {
 			Expect(actual).To(BeTrue())
 		})
 
-		It("returns false when the event has start and duration properties", func() {
+		It("returns false when the event has a DATETIME start and a duration", func() {
 			evt := ical.NewEvent("evt")
-			evt.SetProperty(ical.ComponentPropertyDtStart, "20200130")
-			evt.SetProperty("DURATION", "30M")
+			evt.SetProperty(ical.ComponentPropertyDtStart, "20200130T013055Z")
+			evt.SetProperty("DURATION", "PT30M")
 
 			actual, err := calendar.IsAllDayEvent(evt)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(actual).To(BeFalse())
 		})
+
+		It("returns true when the event has a DATE start and a duration in days", func() {
+			evt := ical.NewEvent("evt")
+			evt.SetProperty(ical.ComponentPropertyDtStart, "20200130")
+			evt.SetProperty("DURATION", "P2D")
+
+			actual, err := calendar.IsAllDayEvent(evt)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(BeTrue())
+		})
 	})
 
 	Describe("IsRecurringEventDefinition", func() {
-		It("will return false when the event doesn't have an RRULE property", func() {
+		It("will return false when the event doesn't have an RRULE or RDATE property", func() {
 			evt := ical.NewEvent("evt")
 
 			actual := calendar.IsRecurringEventDefinition(evt)
@@ -278,5 +353,31 @@ var _ = Describe("Helpers", func() {
 
 			Expect(actual).To(BeTrue())
 		})
+
+		It("will return true when the event has an RDATE property", func() {
+			evt := ical.NewEvent("evt")
+			evt.AddRdate("20200130T120000Z")
+
+			actual := calendar.IsRecurringEventDefinition(evt)
+
+			Expect(actual).To(BeTrue())
+		})
+	})
+
+	Describe("IsCancelledEvent", func() {
+		It("will return true when the event has a STATUS of CANCELLED", func() {
+			evt := ical.NewEvent("evt")
+			evt.SetStatus(ical.ObjectStatusCancelled)
+
+			Expect(calendar.IsCancelledEvent(evt)).To(BeTrue())
+		})
+
+		It("will return false when the event has any other STATUS, or none at all", func() {
+			confirmed := ical.NewEvent("evt")
+			confirmed.SetStatus(ical.ObjectStatusConfirmed)
+
+			Expect(calendar.IsCancelledEvent(confirmed)).To(BeFalse())
+			Expect(calendar.IsCancelledEvent(ical.NewEvent("evt"))).To(BeFalse())
+		})
 	})
 })
@@ -0,0 +1,672 @@
+package calendar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	ComponentPropertyRrule        = ical.ComponentProperty("RRULE")
+	ComponentPropertyRdate        = ical.ComponentProperty("RDATE")
+	ComponentPropertyExdate       = ical.ComponentProperty("EXDATE")
+	ComponentPropertyExrule       = ical.ComponentProperty("EXRULE")
+	ComponentPropertyRecurrenceId = ical.ComponentProperty("RECURRENCE-ID")
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a single entry in the BYDAY part of a recurrence rule,
+// such as "TU" or "-1FR". An Ordinal of 0 means every matching weekday.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RecurrenceRule is a parsed RRULE value
+// https://www.rfc-editor.org/rfc/rfc5545#section-3.3.10
+type RecurrenceRule struct {
+	Frequency  Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// ParseRecurrenceRule parses the value of an RRULE property.
+// Floating UNTIL values are interpreted in the given location.
+func ParseRecurrenceRule(value string, loc *time.Location) (*RecurrenceRule, error) {
+	rule := &RecurrenceRule{
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		key, val, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("malformed recurrence rule part '%s'", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq := Frequency(strings.ToUpper(val))
+			switch freq {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+				rule.Frequency = freq
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency '%s'", val)
+			}
+
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval '%s'", val)
+			}
+			rule.Interval = interval
+
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid recurrence count '%s'", val)
+			}
+			rule.Count = count
+
+		case "UNTIL":
+			until, isDate, err := parseIcalDateTime(val, loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recurrence end '%s': %s", val, err)
+			}
+
+			// An UNTIL of type DATE includes every occurrence on that date
+			if isDate {
+				until = until.AddDate(0, 0, 1).Add(-1 * time.Nanosecond)
+			}
+			rule.Until = &until
+
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekdayNum, err := parseWeekdayNum(day)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, weekdayNum)
+			}
+
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("invalid day of month '%s'", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+
+		case "BYMONTH":
+			for _, month := range strings.Split(val, ",") {
+				monthNum, err := strconv.Atoi(month)
+				if err != nil || monthNum < 1 || monthNum > 12 {
+					return nil, fmt.Errorf("invalid month '%s'", month)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(monthNum))
+			}
+
+		case "WKST":
+			weekday, ok := icalWeekdays[strings.ToUpper(val)]
+			if !ok {
+				return nil, fmt.Errorf("invalid week start '%s'", val)
+			}
+			rule.WeekStart = weekday
+
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part '%s'", key)
+		}
+	}
+
+	if rule.Frequency == "" {
+		return nil, fmt.Errorf("recurrence rule '%s' has no frequency", value)
+	}
+
+	return rule, nil
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday '%s'", value)
+	}
+
+	weekday, ok := icalWeekdays[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday '%s'", value)
+	}
+
+	ordinal := 0
+	if ordinalStr := value[:len(value)-2]; ordinalStr != "" {
+		var err error
+		ordinal, err = strconv.Atoi(ordinalStr)
+		if err != nil || ordinal == 0 || ordinal < -53 || ordinal > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday '%s'", value)
+		}
+	}
+
+	return WeekdayNum{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+// Occurrences returns the start time of every occurrence of the rule
+// which starts before the given end time. The first occurrence is
+// always dtstart, as required by RFC 5545, and every other occurrence
+// has the same wall clock time and location as dtstart.
+func (r *RecurrenceRule) Occurrences(dtstart time.Time, end time.Time) []time.Time {
+	rule := r.withDefaultsFrom(dtstart)
+	occurrences := []time.Time{}
+
+	accept := func(t time.Time) bool {
+		if !t.Before(end) {
+			return false
+		}
+
+		if rule.Until != nil && t.After(*rule.Until) {
+			return false
+		}
+
+		if rule.Count > 0 && len(occurrences) >= rule.Count {
+			return false
+		}
+
+		occurrences = append(occurrences, t)
+		return true
+	}
+
+	if !accept(dtstart) {
+		return occurrences
+	}
+
+	for period := 0; ; period++ {
+		periodStart, scopes := rule.period(dtstart, period)
+		if !periodStart.Before(end) {
+			return occurrences
+		}
+
+		for _, scope := range scopes {
+			for _, candidate := range rule.selectDays(scope) {
+				if !candidate.After(dtstart) {
+					continue
+				}
+
+				if !accept(candidate) {
+					return occurrences
+				}
+			}
+		}
+	}
+}
+
+// withDefaultsFrom fills in the parts of the rule which RFC 5545 says
+// are taken from DTSTART when they are not given explicitly
+func (r RecurrenceRule) withDefaultsFrom(dtstart time.Time) RecurrenceRule {
+	noDaySelector := len(r.ByDay) == 0 && len(r.ByMonthDay) == 0
+
+	switch r.Frequency {
+	case FrequencyWeekly:
+		if len(r.ByDay) == 0 {
+			r.ByDay = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+
+	case FrequencyMonthly:
+		if noDaySelector {
+			r.ByMonthDay = []int{dtstart.Day()}
+		}
+
+	case FrequencyYearly:
+		if noDaySelector {
+			if len(r.ByMonth) == 0 {
+				r.ByMonth = []time.Month{dtstart.Month()}
+			}
+			r.ByMonthDay = []int{dtstart.Day()}
+		}
+	}
+
+	return r
+}
+
+// period returns the start of the nth period of the rule, and the days
+// within that period which are candidates for an occurrence. The days are
+// grouped in to the scopes against which BYDAY ordinals are counted.
+func (r *RecurrenceRule) period(dtstart time.Time, n int) (time.Time, [][]time.Time) {
+	step := n * r.Interval
+	day := func(year int, month time.Month, dayOfMonth int) time.Time {
+		return time.Date(
+			year, month, dayOfMonth,
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(),
+			dtstart.Location(),
+		)
+	}
+
+	switch r.Frequency {
+	case FrequencyWeekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := day(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*step)
+		return weekStart, [][]time.Time{daysFrom(weekStart, 7)}
+
+	case FrequencyMonthly:
+		monthStart := day(dtstart.Year(), dtstart.Month()+time.Month(step), 1)
+		return monthStart, [][]time.Time{daysInMonth(monthStart)}
+
+	case FrequencyYearly:
+		yearStart := day(dtstart.Year()+step, time.January, 1)
+		if len(r.ByMonth) == 0 {
+			return yearStart, [][]time.Time{daysFrom(yearStart, yearStart.AddDate(1, 0, -1).YearDay())}
+		}
+
+		scopes := [][]time.Time{}
+		for month := time.January; month <= time.December; month++ {
+			scopes = append(scopes, daysInMonth(day(yearStart.Year(), month, 1)))
+		}
+		return yearStart, scopes
+
+	default:
+		periodDay := day(dtstart.Year(), dtstart.Month(), dtstart.Day()+step)
+		return periodDay, [][]time.Time{{periodDay}}
+	}
+}
+
+func daysFrom(start time.Time, n int) []time.Time {
+	days := make([]time.Time, n)
+	for i := range days {
+		days[i] = start.AddDate(0, 0, i)
+	}
+
+	return days
+}
+
+func daysInMonth(monthStart time.Time) []time.Time {
+	return daysFrom(monthStart, monthStart.AddDate(0, 1, -1).Day())
+}
+
+// selectDays filters a scope of days down to those which match
+// the BYMONTH, BYMONTHDAY and BYDAY parts of the rule
+func (r *RecurrenceRule) selectDays(scope []time.Time) []time.Time {
+	selected := []time.Time{}
+
+	for i, day := range scope {
+		if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, day.Month()) {
+			continue
+		}
+
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(day) {
+			continue
+		}
+
+		if len(r.ByDay) > 0 && !r.matchesWeekday(scope, i) {
+			continue
+		}
+
+		selected = append(selected, day)
+	}
+
+	return selected
+}
+
+func (r *RecurrenceRule) matchesMonthDay(day time.Time) bool {
+	lastDayOfMonth := day.AddDate(0, 1, -day.Day()).Day()
+
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay > 0 && day.Day() == monthDay {
+			return true
+		}
+
+		if monthDay < 0 && day.Day() == lastDayOfMonth+monthDay+1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *RecurrenceRule) matchesWeekday(scope []time.Time, index int) bool {
+	day := scope[index]
+
+	for _, weekdayNum := range r.ByDay {
+		if weekdayNum.Weekday != day.Weekday() {
+			continue
+		}
+
+		if weekdayNum.Ordinal == 0 {
+			return true
+		}
+
+		// Ordinals count occurrences of the weekday from
+		// the start (positive) or end (negative) of the scope
+		fromStart := index/7 + 1
+		fromEnd := -((len(scope) - 1 - index) / 7) - 1
+		if weekdayNum.Ordinal == fromStart || weekdayNum.Ordinal == fromEnd {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRecurringEventDefinition returns true when the event defines
+// a set of occurrences through an RRULE or RDATE property
+func IsRecurringEventDefinition(evt *ical.VEvent) bool {
+	return evt.GetProperty(ComponentPropertyRrule) != nil || evt.GetProperty(ComponentPropertyRdate) != nil
+}
+
+// IsRecurrenceOverride returns true when the event replaces a single
+// occurrence of a recurring event, which it identifies with a RECURRENCE-ID
+func IsRecurrenceOverride(evt *ical.VEvent) bool {
+	return evt.GetProperty(ComponentPropertyRecurrenceId) != nil && !IsRecurringEventDefinition(evt)
+}
+
+// ExpandRecurringEvents replaces every recurring event definition in
+// the list with its occurrences that overlap the window between from
+// and to. Events which are not recurring are returned unchanged.
+//
+// Occurrences which have been moved or cancelled by an override in the
+// list are replaced by that override, and cancelled events are removed.
+//
+// Definitions which cannot be expanded, such as those with RRULE parts
+// which aren't supported, are shown at their DTSTART rather than being
+// dropped, so that they don't disappear from the schedule altogether.
+func ExpandRecurringEvents(events []*ical.VEvent, from time.Time, to time.Time) []*ical.VEvent {
+	overrides := recurrenceOverrides(events)
+	expanded := []*ical.VEvent{}
+
+	for _, evt := range events {
+		if IsCancelledEvent(evt) {
+			continue
+		}
+
+		if !IsRecurringEventDefinition(evt) {
+			expanded = append(expanded, evt)
+			continue
+		}
+
+		occurrences, err := ExpandRecurringEvent(evt, from, to)
+		if err != nil {
+			occurrences = firstOccurrence(evt, from, to)
+		}
+
+		for _, occurrence := range occurrences {
+			start, _, err := EventStartAndEnd(occurrence)
+			if err != nil {
+				continue
+			}
+
+			isOverridden := slices.IndexFunc(overrides[evt.Id()], func(recurrenceId icalTime) bool {
+				return recurrenceId.matches(start)
+			}) >= 0
+
+			if !isOverridden {
+				expanded = append(expanded, occurrence)
+			}
+		}
+	}
+
+	return expanded
+}
+
+// firstOccurrence returns the occurrence of the event at its DTSTART
+// when it overlaps the window between from and to. Events with
+// unreadable start and end times have no occurrences.
+func firstOccurrence(evt *ical.VEvent, from time.Time, to time.Time) []*ical.VEvent {
+	start, end, err := EventStartAndEnd(evt)
+	if err != nil || !start.Before(to) || end.Before(from) {
+		return []*ical.VEvent{}
+	}
+
+	return []*ical.VEvent{newOccurrence(evt, start, end)}
+}
+
+// recurrenceOverrides finds the RECURRENCE-ID of every
+// override in the list, grouped by the UID they override
+func recurrenceOverrides(events []*ical.VEvent) map[string][]icalTime {
+	overrides := map[string][]icalTime{}
+
+	for _, evt := range events {
+		if !IsRecurrenceOverride(evt) {
+			continue
+		}
+
+		loc := time.Local
+		if start, _, err := EventStartAndEnd(evt); err == nil {
+			loc = start.Location()
+		}
+
+		recurrenceIds, err := propertyTimes(&evt.ComponentBase, ComponentPropertyRecurrenceId, loc)
+		if err != nil {
+			continue
+		}
+
+		overrides[evt.Id()] = append(overrides[evt.Id()], recurrenceIds...)
+	}
+
+	return overrides
+}
+
+// ExpandRecurringEvent turns a recurring event definition in to one
+// event per occurrence that overlaps the window between from and to.
+//
+// Each occurrence is a copy of the definition without its RRULE, RDATE and
+// EXDATE properties, with its start and end moved to the occurrence, and
+// with a RECURRENCE-ID identifying the occurrence it represents.
+func ExpandRecurringEvent(evt *ical.VEvent, from time.Time, to time.Time) ([]*ical.VEvent, error) {
+	start, end, err := EventStartAndEnd(evt)
+	if err != nil {
+		return nil, fmt.Errorf("expanding event %s: %s", evt.Id(), err)
+	}
+	duration := end.Sub(start)
+	isAllDay := RegexIcalDate.MatchString(evt.GetProperty(ical.ComponentPropertyDtStart).Value)
+
+	starts := []time.Time{}
+	for _, prop := range propertiesNamed(&evt.ComponentBase, ComponentPropertyRrule) {
+		rule, err := ParseRecurrenceRule(prop.Value, start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("expanding event %s: %s", evt.Id(), err)
+		}
+
+		starts = append(starts, rule.Occurrences(start, to)...)
+	}
+
+	if len(starts) == 0 {
+		// A definition with only RDATEs still includes DTSTART
+		starts = append(starts, start)
+	}
+
+	rdates, err := propertyTimes(&evt.ComponentBase, ComponentPropertyRdate, start.Location())
+	if err != nil {
+		return nil, fmt.Errorf("expanding event %s: %s", evt.Id(), err)
+	}
+	for _, rdate := range rdates {
+		starts = append(starts, rdate.Time)
+	}
+
+	exdates, err := propertyTimes(&evt.ComponentBase, ComponentPropertyExdate, start.Location())
+	if err != nil {
+		return nil, fmt.Errorf("expanding event %s: %s", evt.Id(), err)
+	}
+
+	slices.SortFunc(starts, func(a time.Time, b time.Time) bool {
+		return a.Before(b)
+	})
+
+	occurrences := []*ical.VEvent{}
+	for i, occurrenceStart := range starts {
+		if i > 0 && occurrenceStart.Equal(starts[i-1]) {
+			continue
+		}
+
+		if slices.IndexFunc(exdates, func(exdate icalTime) bool { return exdate.matches(occurrenceStart) }) >= 0 {
+			continue
+		}
+
+		occurrenceEnd := occurrenceStart.Add(duration)
+		if isAllDay {
+			occurrenceEnd = occurrenceStart.AddDate(0, 0, int(math.Round(duration.Hours()/24)))
+		}
+
+		if !occurrenceStart.Before(to) || occurrenceEnd.Before(from) {
+			continue
+		}
+
+		occurrences = append(occurrences, newOccurrence(evt, occurrenceStart, occurrenceEnd))
+	}
+
+	return occurrences, nil
+}
+
+func newOccurrence(definition *ical.VEvent, start time.Time, end time.Time) *ical.VEvent {
+	occurrence := &ical.VEvent{}
+	occurrence.Components = definition.Components
+
+	for _, prop := range definition.Properties {
+		switch ical.ComponentProperty(prop.IANAToken) {
+		case ComponentPropertyRrule, ComponentPropertyRdate, ComponentPropertyExdate, ComponentPropertyExrule:
+			continue
+		}
+
+		params := map[string][]string{}
+		for k, v := range prop.ICalParameters {
+			params[k] = append([]string{}, v...)
+		}
+
+		copied := prop
+		copied.ICalParameters = params
+		occurrence.Properties = append(occurrence.Properties, copied)
+	}
+
+	dtstart := occurrence.GetProperty(ical.ComponentPropertyDtStart)
+	dtstart.Value = formatIcalTimeLike(dtstart.Value, start)
+
+	if dtend := occurrence.GetProperty(ical.ComponentPropertyDtEnd); dtend != nil {
+		if originalEnd, _, err := propertyTime(dtend); err == nil {
+			end = end.In(originalEnd.Location())
+		}
+		dtend.Value = formatIcalTimeLike(dtend.Value, end)
+	}
+
+	recurrenceIdParams := []ical.PropertyParameter{}
+	for k, v := range dtstart.ICalParameters {
+		recurrenceIdParams = append(recurrenceIdParams, &ical.KeyValues{Key: k, Value: v})
+	}
+	occurrence.SetProperty(ComponentPropertyRecurrenceId, dtstart.Value, recurrenceIdParams...)
+
+	return occurrence
+}
+
+// icalTime is a single DATE or DATE-TIME value
+type icalTime struct {
+	Time   time.Time
+	IsDate bool
+}
+
+func (t icalTime) matches(other time.Time) bool {
+	if t.IsDate {
+		y1, m1, d1 := t.Time.Date()
+		y2, m2, d2 := other.In(t.Time.Location()).Date()
+		return y1 == y2 && m1 == m2 && d1 == d2
+	}
+
+	return t.Time.Equal(other)
+}
+
+func propertiesNamed(component *ical.ComponentBase, name ical.ComponentProperty) []ical.IANAProperty {
+	props := []ical.IANAProperty{}
+	for _, prop := range component.Properties {
+		if prop.IANAToken == string(name) {
+			props = append(props, prop)
+		}
+	}
+
+	return props
+}
+
+// propertyTimes reads every value of every instance of a
+// multi-valued date property such as RDATE or EXDATE
+func propertyTimes(component *ical.ComponentBase, name ical.ComponentProperty, defaultLoc *time.Location) ([]icalTime, error) {
+	times := []icalTime{}
+
+	for _, prop := range propertiesNamed(component, name) {
+		prop := prop
+		loc, err := propertyLocation(&prop, defaultLoc)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %s", name, err)
+		}
+
+		for _, value := range strings.Split(prop.Value, ",") {
+			// PERIOD values are a start and an end or duration.
+			// Only the start is relevant to the recurrence set.
+			value, _, _ = strings.Cut(value, "/")
+
+			t, isDate, err := parseIcalDateTime(strings.TrimSpace(value), loc)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %s", name, err)
+			}
+
+			times = append(times, icalTime{Time: t, IsDate: isDate})
+		}
+	}
+
+	return times, nil
+}
+
+// parseIcalDateTime parses a DATE or DATE-TIME value, as defined in
+// RFC 5545, interpreting dates and floating times in the given location
+func parseIcalDateTime(value string, loc *time.Location) (time.Time, bool, error) {
+	if RegexIcalDate.MatchString(value) {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// formatIcalTimeLike formats a time using the same
+// DATE or DATE-TIME form as an existing value
+func formatIcalTimeLike(existing string, t time.Time) string {
+	if RegexIcalDate.MatchString(existing) {
+		return t.Format("20060102")
+	}
+
+	if strings.HasSuffix(existing, "Z") {
+		return t.UTC().Format("20060102T150405Z")
+	}
+
+	return t.Format("20060102T150405")
+}
@@ -0,0 +1,397 @@
+package calendar_test
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func utcDate(year int, month time.Month, day int, hour int, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+var _ = Describe("Recurrence", func() {
+	Describe("ParseRecurrenceRule", func() {
+		It("parses each of the supported rule parts", func() {
+			rule, err := calendar.ParseRecurrenceRule("FREQ=MONTHLY;INTERVAL=2;COUNT=5;BYDAY=MO,-1FR;BYMONTHDAY=1,-1;BYMONTH=3;WKST=SU", time.UTC)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(rule.Frequency).To(Equal(calendar.FrequencyMonthly))
+			Expect(rule.Interval).To(Equal(2))
+			Expect(rule.Count).To(Equal(5))
+			Expect(rule.ByDay).To(Equal([]calendar.WeekdayNum{
+				{Ordinal: 0, Weekday: time.Monday},
+				{Ordinal: -1, Weekday: time.Friday},
+			}))
+			Expect(rule.ByMonthDay).To(Equal([]int{1, -1}))
+			Expect(rule.ByMonth).To(Equal([]time.Month{time.March}))
+			Expect(rule.WeekStart).To(Equal(time.Sunday))
+		})
+
+		It("treats an UNTIL of type DATE as including the whole of that day", func() {
+			rule, err := calendar.ParseRecurrenceRule("FREQ=DAILY;UNTIL=20220110", time.UTC)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*rule.Until).To(BeTemporally(">", utcDate(2022, time.January, 10, 23, 59)))
+			Expect(*rule.Until).To(BeTemporally("<", utcDate(2022, time.January, 11, 0, 0)))
+		})
+
+		DescribeTable("rejects rules it cannot honour",
+			func(value string) {
+				_, err := calendar.ParseRecurrenceRule(value, time.UTC)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("no frequency", "COUNT=2"),
+			Entry("sub-daily frequency", "FREQ=HOURLY"),
+			Entry("unsupported part", "FREQ=MONTHLY;BYSETPOS=-1"),
+			Entry("invalid weekday", "FREQ=WEEKLY;BYDAY=XX"),
+			Entry("invalid interval", "FREQ=DAILY;INTERVAL=0"),
+		)
+	})
+
+	Describe("Occurrences", func() {
+		// Monday 3rd January 2022
+		dtstart := utcDate(2022, time.January, 3, 9, 0)
+		farFuture := utcDate(2030, time.January, 1, 0, 0)
+
+		DescribeTable("generates the start time of each occurrence",
+			func(value string, start time.Time, expected []time.Time) {
+				rule, err := calendar.ParseRecurrenceRule(value, time.UTC)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(rule.Occurrences(start, farFuture)).To(Equal(expected))
+			},
+			Entry("daily with a count", "FREQ=DAILY;COUNT=3", dtstart, []time.Time{
+				utcDate(2022, time.January, 3, 9, 0),
+				utcDate(2022, time.January, 4, 9, 0),
+				utcDate(2022, time.January, 5, 9, 0),
+			}),
+			Entry("every other day until a date-time", "FREQ=DAILY;INTERVAL=2;UNTIL=20220109T090000Z", dtstart, []time.Time{
+				utcDate(2022, time.January, 3, 9, 0),
+				utcDate(2022, time.January, 5, 9, 0),
+				utcDate(2022, time.January, 7, 9, 0),
+				utcDate(2022, time.January, 9, 9, 0),
+			}),
+			Entry("weekly on several days", "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", dtstart, []time.Time{
+				utcDate(2022, time.January, 3, 9, 0),
+				utcDate(2022, time.January, 5, 9, 0),
+				utcDate(2022, time.January, 10, 9, 0),
+				utcDate(2022, time.January, 12, 9, 0),
+			}),
+			Entry("fortnightly on the day of DTSTART", "FREQ=WEEKLY;INTERVAL=2;COUNT=3", dtstart, []time.Time{
+				utcDate(2022, time.January, 3, 9, 0),
+				utcDate(2022, time.January, 17, 9, 0),
+				utcDate(2022, time.January, 31, 9, 0),
+			}),
+			Entry("monthly on the first Monday", "FREQ=MONTHLY;BYDAY=1MO;COUNT=3", dtstart, []time.Time{
+				utcDate(2022, time.January, 3, 9, 0),
+				utcDate(2022, time.February, 7, 9, 0),
+				utcDate(2022, time.March, 7, 9, 0),
+			}),
+			Entry("monthly on the last day", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3", utcDate(2022, time.January, 31, 9, 0), []time.Time{
+				utcDate(2022, time.January, 31, 9, 0),
+				utcDate(2022, time.February, 28, 9, 0),
+				utcDate(2022, time.March, 31, 9, 0),
+			}),
+			Entry("monthly on a day which some months don't have", "FREQ=MONTHLY;COUNT=3", utcDate(2022, time.January, 31, 9, 0), []time.Time{
+				utcDate(2022, time.January, 31, 9, 0),
+				utcDate(2022, time.March, 31, 9, 0),
+				utcDate(2022, time.May, 31, 9, 0),
+			}),
+			Entry("yearly", "FREQ=YEARLY;COUNT=2", dtstart, []time.Time{
+				utcDate(2022, time.January, 3, 9, 0),
+				utcDate(2023, time.January, 3, 9, 0),
+			}),
+		)
+
+		It("stops at the given end time", func() {
+			rule, err := calendar.ParseRecurrenceRule("FREQ=DAILY", time.UTC)
+			Expect(err).ToNot(HaveOccurred())
+
+			occurrences := rule.Occurrences(dtstart, utcDate(2022, time.January, 6, 0, 0))
+			Expect(occurrences).To(HaveLen(3))
+		})
+
+		It("keeps the wall clock time of DTSTART across daylight saving changes", func() {
+			london, err := time.LoadLocation("Europe/London")
+			Expect(err).ToNot(HaveOccurred())
+
+			rule, err := calendar.ParseRecurrenceRule("FREQ=DAILY;COUNT=2", london)
+			Expect(err).ToNot(HaveOccurred())
+
+			start := time.Date(2022, time.March, 26, 9, 0, 0, 0, london)
+			occurrences := rule.Occurrences(start, farFuture)
+
+			Expect(occurrences[1]).To(Equal(time.Date(2022, time.March, 27, 9, 0, 0, 0, london)))
+		})
+	})
+
+	Describe("ExpandRecurringEvent", func() {
+		var evt *ical.VEvent
+
+		BeforeEach(func() {
+			evt = ical.NewEvent("standup")
+			evt.SetProperty(ical.ComponentProperty(ical.PropertySummary), "Standup")
+			evt.SetStartAt(utcDate(2022, time.January, 3, 9, 0))
+			evt.SetEndAt(utcDate(2022, time.January, 3, 9, 15))
+			evt.AddRrule("FREQ=DAILY")
+		})
+
+		It("returns only the occurrences within the window", func() {
+			occurrences, err := calendar.ExpandRecurringEvent(
+				evt,
+				utcDate(2022, time.January, 10, 0, 0),
+				utcDate(2022, time.January, 11, 0, 0),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(occurrences).To(HaveLen(1))
+
+			start, end, err := calendar.EventStartAndEnd(occurrences[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start).To(Equal(utcDate(2022, time.January, 10, 9, 0)))
+			Expect(end).To(Equal(utcDate(2022, time.January, 10, 9, 15)))
+		})
+
+		It("produces occurrences which are not themselves recurring, and which identify the occurrence they represent", func() {
+			occurrences, err := calendar.ExpandRecurringEvent(
+				evt,
+				utcDate(2022, time.January, 10, 0, 0),
+				utcDate(2022, time.January, 11, 0, 0),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			occurrence := occurrences[0]
+			Expect(calendar.IsRecurringEventDefinition(occurrence)).To(BeFalse())
+			Expect(occurrence.Id()).To(Equal("standup"))
+			Expect(occurrence.GetProperty(ical.ComponentProperty(ical.PropertySummary)).Value).To(Equal("Standup"))
+			Expect(occurrence.GetProperty(calendar.ComponentPropertyRecurrenceId).Value).To(Equal("20220110T090000Z"))
+
+			Expect(evt.GetProperty(ical.ComponentPropertyDtStart).Value).To(Equal("20220103T090000Z"), "the definition should not be modified")
+		})
+
+		It("includes occurrences which started before the window but end within it", func() {
+			evt.SetEndAt(utcDate(2022, time.January, 3, 23, 0).Add(2 * time.Hour))
+
+			occurrences, err := calendar.ExpandRecurringEvent(
+				evt,
+				utcDate(2022, time.January, 10, 0, 0),
+				utcDate(2022, time.January, 11, 0, 0),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(occurrences).To(HaveLen(2))
+		})
+
+		It("excludes occurrences listed in EXDATE", func() {
+			evt.AddExdate("20220110T090000Z")
+
+			occurrences, err := calendar.ExpandRecurringEvent(
+				evt,
+				utcDate(2022, time.January, 9, 0, 0),
+				utcDate(2022, time.January, 12, 0, 0),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(occurrences).To(HaveLen(2))
+
+			for _, occurrence := range occurrences {
+				Expect(occurrence.GetProperty(calendar.ComponentPropertyRecurrenceId).Value).ToNot(Equal("20220110T090000Z"))
+			}
+		})
+
+		It("includes additional occurrences listed in RDATE", func() {
+			evt = ical.NewEvent("review")
+			evt.SetStartAt(utcDate(2022, time.January, 3, 14, 0))
+			evt.SetEndAt(utcDate(2022, time.January, 3, 15, 0))
+			evt.AddRdate("20220105T100000Z,20220107T100000Z")
+
+			occurrences, err := calendar.ExpandRecurringEvent(
+				evt,
+				utcDate(2022, time.January, 1, 0, 0),
+				utcDate(2022, time.February, 1, 0, 0),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(occurrences).To(HaveLen(3))
+
+			start, end, err := calendar.EventStartAndEnd(occurrences[1])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start).To(Equal(utcDate(2022, time.January, 5, 10, 0)))
+			Expect(end).To(Equal(utcDate(2022, time.January, 5, 11, 0)))
+		})
+
+		It("keeps all day events as all day events", func() {
+			evt = ical.NewEvent("holiday")
+			evt.SetProperty(ical.ComponentPropertyDtStart, "20220103", ical.WithValue(string(ical.ValueDataTypeDate)))
+			evt.SetProperty(ical.ComponentPropertyDtEnd, "20220104", ical.WithValue(string(ical.ValueDataTypeDate)))
+			evt.AddRrule("FREQ=WEEKLY")
+
+			occurrences, err := calendar.ExpandRecurringEvent(
+				evt,
+				utcDate(2022, time.January, 9, 0, 0),
+				utcDate(2022, time.January, 16, 0, 0),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(occurrences).To(HaveLen(1))
+
+			Expect(occurrences[0].GetProperty(ical.ComponentPropertyDtStart).Value).To(Equal("20220110"))
+			Expect(occurrences[0].GetProperty(ical.ComponentPropertyDtEnd).Value).To(Equal("20220111"))
+
+			isAllDay, err := calendar.IsAllDayEvent(occurrences[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isAllDay).To(BeTrue())
+		})
+
+		It("returns an error naming the event when the rule can't be understood", func() {
+			evt = ical.NewEvent("broken")
+			evt.SetStartAt(utcDate(2022, time.January, 3, 9, 0))
+			evt.AddRrule("FREQ=SECONDLY")
+
+			_, err := calendar.ExpandRecurringEvent(evt, utcDate(2022, time.January, 1, 0, 0), utcDate(2022, time.January, 2, 0, 0))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("broken"))
+		})
+	})
+
+	Describe("ExpandRecurringEvents", func() {
+		It("passes non-recurring events through, replaces definitions with occurrences, and leaves out definitions it can't expand away from their DTSTART", func() {
+			single := ical.NewEvent("single")
+			single.SetStartAt(utcDate(2022, time.January, 10, 12, 0))
+			single.SetEndAt(utcDate(2022, time.January, 10, 13, 0))
+
+			recurring := ical.NewEvent("recurring")
+			recurring.SetStartAt(utcDate(2022, time.January, 3, 9, 0))
+			recurring.SetEndAt(utcDate(2022, time.January, 3, 10, 0))
+			recurring.AddRrule("FREQ=WEEKLY;BYDAY=MO")
+
+			broken := ical.NewEvent("broken")
+			broken.SetStartAt(utcDate(2022, time.January, 3, 9, 0))
+			broken.AddRrule("FREQ=NEVER")
+
+			expanded := calendar.ExpandRecurringEvents(
+				[]*ical.VEvent{single, recurring, broken},
+				utcDate(2022, time.January, 10, 0, 0),
+				utcDate(2022, time.January, 11, 0, 0),
+			)
+
+			Expect(expanded).To(HaveLen(2))
+			Expect(expanded[0]).To(BeIdenticalTo(single))
+			Expect(expanded[1].Id()).To(Equal("recurring"))
+		})
+
+		It("shows definitions with RRULE parts which aren't supported at their DTSTART", func() {
+			monthly := ical.NewEvent("monthly")
+			monthly.SetStartAt(utcDate(2022, time.January, 31, 15, 0))
+			monthly.SetEndAt(utcDate(2022, time.January, 31, 16, 0))
+			monthly.AddRrule("FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1")
+
+			expanded := calendar.ExpandRecurringEvents(
+				[]*ical.VEvent{monthly},
+				utcDate(2022, time.January, 31, 0, 0),
+				utcDate(2022, time.February, 1, 0, 0),
+			)
+
+			Expect(expanded).To(HaveLen(1))
+			Expect(expanded[0].Id()).To(Equal("monthly"))
+			Expect(calendar.IsRecurringEventDefinition(expanded[0])).To(BeFalse())
+
+			start, end, err := calendar.EventStartAndEnd(expanded[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start).To(Equal(utcDate(2022, time.January, 31, 15, 0)))
+			Expect(end).To(Equal(utcDate(2022, time.January, 31, 16, 0)))
+		})
+
+		Context("when the list contains overrides of individual occurrences", func() {
+			var (
+				definition *ical.VEvent
+				from       = utcDate(2022, time.January, 10, 0, 0)
+				to         = utcDate(2022, time.January, 12, 0, 0)
+			)
+
+			BeforeEach(func() {
+				definition = ical.NewEvent("standup")
+				definition.SetStartAt(utcDate(2022, time.January, 3, 9, 0))
+				definition.SetEndAt(utcDate(2022, time.January, 3, 9, 15))
+				definition.AddRrule("FREQ=DAILY")
+			})
+
+			It("replaces a moved occurrence with the override", func() {
+				moved := ical.NewEvent("standup")
+				moved.SetStartAt(utcDate(2022, time.January, 10, 11, 0))
+				moved.SetEndAt(utcDate(2022, time.January, 10, 11, 15))
+				moved.SetProperty(calendar.ComponentPropertyRecurrenceId, "20220110T090000Z")
+
+				expanded := calendar.ExpandRecurringEvents([]*ical.VEvent{definition, moved}, from, to)
+
+				starts := []time.Time{}
+				for _, evt := range expanded {
+					start, _, err := calendar.EventStartAndEnd(evt)
+					Expect(err).ToNot(HaveOccurred())
+					starts = append(starts, start)
+				}
+
+				Expect(starts).To(ConsistOf(
+					utcDate(2022, time.January, 10, 11, 0),
+					utcDate(2022, time.January, 11, 9, 0),
+				))
+				Expect(expanded).To(ContainElement(BeIdenticalTo(moved)))
+			})
+
+			It("matches overrides which use a TZID rather than UTC", func() {
+				moved := ical.NewEvent("standup")
+				moved.SetStartAt(utcDate(2022, time.January, 10, 11, 0))
+				moved.SetEndAt(utcDate(2022, time.January, 10, 11, 15))
+				moved.SetProperty(
+					calendar.ComponentPropertyRecurrenceId,
+					"20220110T040000",
+					&ical.KeyValues{Key: "TZID", Value: []string{"America/New_York"}},
+				)
+
+				expanded := calendar.ExpandRecurringEvents([]*ical.VEvent{definition, moved}, from, to)
+
+				Expect(expanded).To(HaveLen(2))
+			})
+
+			It("removes a cancelled occurrence", func() {
+				cancelled := ical.NewEvent("standup")
+				cancelled.SetStartAt(utcDate(2022, time.January, 10, 9, 0))
+				cancelled.SetEndAt(utcDate(2022, time.January, 10, 9, 15))
+				cancelled.SetProperty(calendar.ComponentPropertyRecurrenceId, "20220110T090000Z")
+				cancelled.SetStatus(ical.ObjectStatusCancelled)
+
+				expanded := calendar.ExpandRecurringEvents([]*ical.VEvent{definition, cancelled}, from, to)
+
+				Expect(expanded).To(HaveLen(1))
+				start, _, err := calendar.EventStartAndEnd(expanded[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(start).To(Equal(utcDate(2022, time.January, 11, 9, 0)))
+			})
+
+			It("only overrides occurrences of the event with the same UID", func() {
+				other := ical.NewEvent("other")
+				other.SetStartAt(utcDate(2022, time.January, 10, 11, 0))
+				other.SetEndAt(utcDate(2022, time.January, 10, 11, 15))
+				other.SetProperty(calendar.ComponentPropertyRecurrenceId, "20220110T090000Z")
+
+				expanded := calendar.ExpandRecurringEvents([]*ical.VEvent{definition, other}, from, to)
+
+				Expect(expanded).To(HaveLen(3))
+			})
+		})
+
+		It("removes cancelled events which are not recurring", func() {
+			cancelled := ical.NewEvent("cancelled")
+			cancelled.SetStartAt(utcDate(2022, time.January, 10, 12, 0))
+			cancelled.SetEndAt(utcDate(2022, time.January, 10, 13, 0))
+			cancelled.SetStatus(ical.ObjectStatusCancelled)
+
+			expanded := calendar.ExpandRecurringEvents(
+				[]*ical.VEvent{cancelled},
+				utcDate(2022, time.January, 10, 0, 0),
+				utcDate(2022, time.January, 11, 0, 0),
+			)
+
+			Expect(expanded).To(BeEmpty())
+		})
+	})
+})
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"time"
 
 	"github.com/AP-Hunt/what-next/m/db"
 	ical "github.com/arran4/golang-ical"
@@ -20,10 +21,12 @@ import (
 //counterfeiter:generate -o fakes/ . CalendarServiceInterface
 type CalendarServiceInterface interface {
 	OpenCalendar(url string) (*ical.Calendar, error)
+	OpenCachedCalendar(url string) (*ical.Calendar, error)
 	AddCalendar(url string, displayName string) (*CalendarRecord, error)
 	GetCalendarByDisplayName(displayName string) (*CalendarRecord, error)
 	GetAllCalendars() ([]CalendarRecord, error)
 	RemoveById(id int) error
+	SetBuffers(id int, before *time.Duration, after *time.Duration) (*CalendarRecord, error)
 }
 
 type CalendarService struct {
@@ -81,6 +84,23 @@ func (c *CalendarService) OpenCalendar(url string) (*ical.Calendar, error) {
 	return c.parseCalFromBytes(calBytes)
 }
 
+// OpenCachedCalendar opens the copy of the calendar in the cache, however
+// old it is, and never fetches it. It returns an ErrCacheMiss when the
+// calendar has never been fetched.
+func (c *CalendarService) OpenCachedCalendar(url string) (*ical.Calendar, error) {
+	qualifiedUrl, err := c.resolveUrl(url)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedCal, err := c.cache.GetStale(qualifiedUrl.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parseCalFromBytes(cachedCal)
+}
+
 func (c *CalendarService) resolveUrl(maybeUrl string) (*url.URL, error) {
 	u, err := url.Parse(maybeUrl)
 	if err != nil {
@@ -138,6 +158,8 @@ func (c *CalendarService) parseCalFromBytes(bs []byte) (*ical.Calendar, error) {
 		return nil, fmt.Errorf("parse calendar: %s", err)
 	}
 
+	RegisterTimezones(cal)
+
 	return cal, nil
 }
 
@@ -243,3 +265,30 @@ func (c *CalendarService) RemoveById(id int) error {
 
 	return err
 }
+
+// SetBuffers sets the buffers before and after the meetings in
+// a calendar. A nil buffer means the global buffer is used.
+func (c *CalendarService) SetBuffers(id int, before *time.Duration, after *time.Duration) (*CalendarRecord, error) {
+	return db.InTransaction(
+		func(tx *sqlx.Tx) (*CalendarRecord, error) {
+			row := tx.QueryRowx(
+				`
+				UPDATE calendars
+				SET buffer_before = ?,
+					buffer_after = ?
+				WHERE id = ?
+				RETURNING *
+				`,
+				before,
+				after,
+				id,
+			)
+
+			record := CalendarRecord{}
+			err := row.StructScan(&record)
+			return &record, err
+		},
+		c.db,
+		c.ctx,
+	)
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"time"
 
 	. "github.com/AP-Hunt/what-next/m/calendar"
 	"github.com/AP-Hunt/what-next/m/calendar/fakes"
@@ -176,6 +177,33 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("OpenCachedCalendar", func() {
+		It("returns the cached calendar, however old it is", func() {
+			cachedCalendar := ical.NewCalendar()
+			cachedCalendar.AddEvent("cached-event")
+			calendarCache.GetStaleReturns([]byte(cachedCalendar.Serialize()), nil)
+
+			cal, err := calendarSvc.OpenCachedCalendar("https://example.com/calendar.ical")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cal.Events()).To(HaveLen(1))
+			Expect(cal.Events()[0].Id()).To(Equal("cached-event"))
+
+			Expect(calendarCache.GetStaleArgsForCall(0)).To(Equal("https://example.com/calendar.ical"))
+			Expect(calendarCache.GetCallCount()).To(Equal(0))
+		})
+
+		It("returns the cache miss, rather than fetching the calendar, when it isn't cached", func() {
+			calendarCache.GetStaleReturns(nil, &ErrCacheMiss{Key: "test", Reason: "not found"})
+
+			calPath, err := fakeCalFilePath()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = calendarSvc.OpenCachedCalendar("file://" + calPath)
+			Expect(err).To(BeAssignableToTypeOf(&ErrCacheMiss{}))
+			Expect(calendarCache.PutCallCount()).To(Equal(0))
+		})
+	})
+
 	Describe("AddCalendar", func() {
 		It("will throw an error if the calendar URL can't be reached", func() {
 			_, err := calendarSvc.AddCalendar("file://not.a.thing", "display")
@@ -266,4 +294,25 @@ var _ = Describe("Service", func() {
 			Expect(shouldNotBeFound).To(BeNil())
 		})
 	})
+
+	Describe("SetBuffers", func() {
+		It("will store the buffers for a calendar, which have no buffers of their own by default", func() {
+			calPath, err := fakeCalFilePath()
+			Expect(err).ToNot(HaveOccurred())
+
+			addedRecord, err := calendarSvc.AddCalendar("file://"+calPath, "test name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(addedRecord.BufferBefore).To(BeNil())
+			Expect(addedRecord.BufferAfter).To(BeNil())
+
+			before := 10 * time.Minute
+			_, err = calendarSvc.SetBuffers(addedRecord.Id, &before, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			fetchedRecord, err := calendarSvc.GetCalendarByDisplayName("test name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*fetchedRecord.BufferBefore).To(Equal(10 * time.Minute))
+			Expect(fetchedRecord.BufferAfter).To(BeNil())
+		})
+	})
 })
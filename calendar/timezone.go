@@ -0,0 +1,347 @@
+package calendar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+var (
+	timezonesLock sync.RWMutex
+	timezones     = map[string]*time.Location{}
+)
+
+// UTC offset format as defined in RFC 5545
+// https://www.rfc-editor.org/rfc/rfc5545#section-3.3.14
+var RegexIcalUtcOffset *regexp.Regexp = regexp.MustCompile("^([+-])([0-9]{2})([0-9]{2})([0-9]{2})?$")
+
+// Transitions can only be described up to the end
+// of the 32-bit time range used by version 1 TZif data
+var lastTimezoneTransition = time.Date(2037, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// LoadTimezone resolves the value of a TZID parameter to a location.
+//
+// IANA timezone names, such as "Europe/London", are loaded from the
+// system's timezone database. Any other name must be one given to a
+// VTIMEZONE by RegisterTimezones.
+func LoadTimezone(tzid string) (*time.Location, error) {
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc, nil
+	}
+
+	timezonesLock.RLock()
+	defer timezonesLock.RUnlock()
+
+	if loc, ok := timezones[tzid]; ok {
+		return loc, nil
+	}
+
+	return nil, fmt.Errorf("unknown timezone '%s'", tzid)
+}
+
+// RegisterTimezones makes the VTIMEZONE definitions in a calendar
+// available to LoadTimezone. This is how calendars which use their own
+// timezone names, such as the Windows names used by Office 365, are read.
+//
+// Calendars can define the same TZID differently, such as Outlook's
+// "Customized Time Zone", so each definition is registered under its
+// TZID and a hash of what it defines, and the TZID parameters in the
+// calendar are changed to that name. The locations keep the TZID as
+// their name.
+//
+// Definitions which can't be understood are skipped. Events which
+// refer to them will fail to parse and name the unknown timezone.
+func RegisterTimezones(cal *ical.Calendar) {
+	registered := map[string]string{}
+
+	for _, component := range cal.Components {
+		vtimezone, ok := component.(*ical.VTimezone)
+		if !ok {
+			continue
+		}
+
+		tzidProp := vtimezone.GetProperty(ical.ComponentProperty(ical.PropertyTzid))
+		if tzidProp == nil {
+			continue
+		}
+
+		tzid := tzidProp.Value
+		if _, err := time.LoadLocation(tzid); err == nil {
+			// Prefer the system's definition of IANA timezones
+			continue
+		}
+
+		tzdata, err := tzdataFromVTimezone(tzid, vtimezone)
+		if err != nil {
+			continue
+		}
+
+		loc, err := time.LoadLocationFromTZData(tzid, tzdata)
+		if err != nil {
+			continue
+		}
+
+		hash := sha256.Sum256(tzdata)
+		name := fmt.Sprintf("%s#%x", tzid, hash[:8])
+		registered[tzid] = name
+
+		timezonesLock.Lock()
+		timezones[name] = loc
+		timezonesLock.Unlock()
+	}
+
+	for _, component := range cal.Components {
+		if _, ok := component.(*ical.VTimezone); !ok {
+			renameTimezones(component, registered)
+		}
+	}
+}
+
+// renameTimezones changes the TZID parameters of the properties in the
+// component, and in its subcomponents, to the names they're registered
+// under
+func renameTimezones(component ical.Component, names map[string]string) {
+	for _, prop := range component.UnknownPropertiesIANAProperties() {
+		tzid, ok := prop.ICalParameters["TZID"]
+		if !ok || len(tzid) != 1 {
+			continue
+		}
+
+		if name, ok := names[tzid[0]]; ok {
+			prop.ICalParameters["TZID"] = []string{name}
+		}
+	}
+
+	for _, subcomponent := range component.SubComponents() {
+		renameTimezones(subcomponent, names)
+	}
+}
+
+type timezoneTransition struct {
+	at     time.Time
+	offset int
+	isDST  bool
+	name   string
+}
+
+// tzdataFromVTimezone describes the STANDARD and DAYLIGHT observances of
+// a VTIMEZONE as TZif data for the time package, by expanding each of
+// them in to the instants at which they begin
+func tzdataFromVTimezone(tzid string, vtimezone *ical.VTimezone) ([]byte, error) {
+	transitions := []timezoneTransition{}
+	initialOffset := 0
+
+	for _, component := range vtimezone.Components {
+		var observance *ical.ComponentBase
+		isDST := false
+		switch c := component.(type) {
+		case *ical.Standard:
+			observance = &c.ComponentBase
+		case *ical.Daylight:
+			observance = &c.ComponentBase
+			isDST = true
+		default:
+			continue
+		}
+
+		observanceTransitions, offsetFrom, err := expandObservance(observance, isDST)
+		if err != nil {
+			return nil, fmt.Errorf("reading timezone '%s': %s", tzid, err)
+		}
+
+		if len(observanceTransitions) > 0 && (len(transitions) == 0 || observanceTransitions[0].at.Before(transitions[0].at)) {
+			initialOffset = offsetFrom
+		}
+
+		transitions = append(transitions, observanceTransitions...)
+	}
+
+	if len(transitions) == 0 {
+		return nil, fmt.Errorf("timezone '%s' has no observances", tzid)
+	}
+
+	sort.Slice(transitions, func(i, j int) bool {
+		return transitions[i].at.Before(transitions[j].at)
+	})
+
+	return encodeTZif(initialOffset, transitions), nil
+}
+
+func expandObservance(observance *ical.ComponentBase, isDST bool) ([]timezoneTransition, int, error) {
+	dtstartProp := observance.GetProperty(ical.ComponentPropertyDtStart)
+	offsetFromProp := observance.GetProperty(ical.ComponentProperty(ical.PropertyTzoffsetfrom))
+	offsetToProp := observance.GetProperty(ical.ComponentProperty(ical.PropertyTzoffsetto))
+	if dtstartProp == nil || offsetFromProp == nil || offsetToProp == nil {
+		return nil, 0, fmt.Errorf("observance is missing DTSTART, TZOFFSETFROM or TZOFFSETTO")
+	}
+
+	offsetFrom, err := parseUtcOffset(offsetFromProp.Value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offsetTo, err := parseUtcOffset(offsetToProp.Value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	name := strconv.Itoa(offsetTo / 3600)
+	if nameProp := observance.GetProperty(ical.ComponentProperty(ical.PropertyTzname)); nameProp != nil {
+		name = nameProp.Value
+	}
+
+	// Onsets are local times in the offset being transitioned from.
+	// They are expanded as if they were UTC, and then corrected.
+	onset, _, err := parseIcalDateTime(dtstartProp.Value, time.UTC)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	onsets := []time.Time{onset}
+	for _, rruleProp := range propertiesNamed(observance, ComponentPropertyRrule) {
+		rule, err := ParseRecurrenceRule(rruleProp.Value, time.UTC)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if rule.Until != nil {
+			until := rule.Until.Add(time.Duration(offsetFrom) * time.Second)
+			rule.Until = &until
+		}
+
+		onsets = append(onsets, rule.Occurrences(onset, lastTimezoneTransition)[1:]...)
+	}
+
+	rdates, err := propertyTimes(observance, ComponentPropertyRdate, time.UTC)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, rdate := range rdates {
+		onsets = append(onsets, rdate.Time)
+	}
+
+	transitions := []timezoneTransition{}
+	for _, localOnset := range onsets {
+		transitions = append(transitions, timezoneTransition{
+			at:     localOnset.Add(-time.Duration(offsetFrom) * time.Second),
+			offset: offsetTo,
+			isDST:  isDST,
+			name:   name,
+		})
+	}
+
+	return transitions, offsetFrom, nil
+}
+
+func parseUtcOffset(value string) (int, error) {
+	matched := RegexIcalUtcOffset.FindStringSubmatch(value)
+	if matched == nil {
+		return 0, fmt.Errorf("invalid UTC offset '%s'", value)
+	}
+
+	hours, _ := strconv.Atoi(matched[2])
+	minutes, _ := strconv.Atoi(matched[3])
+	seconds, _ := strconv.Atoi("0" + matched[4])
+
+	offset := hours*3600 + minutes*60 + seconds
+	if matched[1] == "-" {
+		offset = -offset
+	}
+
+	return offset, nil
+}
+
+// encodeTZif writes version 1 TZif data, as described in RFC 8536
+func encodeTZif(initialOffset int, transitions []timezoneTransition) []byte {
+	type zoneType struct {
+		offset int
+		isDST  bool
+		name   string
+	}
+
+	// The first zone type is used for times before the first transition
+	types := []zoneType{{offset: initialOffset, name: strconv.Itoa(initialOffset / 3600)}}
+	typeIndex := func(t zoneType) int {
+		for i, existing := range types[1:] {
+			if existing == t {
+				return i + 1
+			}
+		}
+		types = append(types, t)
+		return len(types) - 1
+	}
+
+	times := []int32{}
+	indices := []uint8{}
+	for _, transition := range transitions {
+		unix := transition.at.Unix()
+		if unix < math.MinInt32 || unix > math.MaxInt32 {
+			continue
+		}
+
+		times = append(times, int32(unix))
+		indices = append(indices, uint8(typeIndex(zoneType{transition.offset, transition.isDST, transition.name})))
+	}
+
+	abbreviations := []byte{}
+	abbreviationIndices := []uint8{}
+	for _, t := range types {
+		abbreviationIndices = append(abbreviationIndices, uint8(len(abbreviations)))
+		abbreviations = append(abbreviations, append([]byte(t.name), 0)...)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("TZif")
+	buf.Write(make([]byte, 16))
+	for _, count := range []int{0, 0, 0, len(times), len(types), len(abbreviations)} {
+		binary.Write(buf, binary.BigEndian, uint32(count))
+	}
+	binary.Write(buf, binary.BigEndian, times)
+	buf.Write(indices)
+	for i, t := range types {
+		binary.Write(buf, binary.BigEndian, int32(t.offset))
+		isDST := uint8(0)
+		if t.isDST {
+			isDST = 1
+		}
+		buf.Write([]byte{isDST, abbreviationIndices[i]})
+	}
+	buf.Write(abbreviations)
+
+	return buf.Bytes()
+}
+
+// propertyTime reads a DATE or DATE-TIME property. A TZID parameter is
+// resolved with LoadTimezone, and dates and floating times are local.
+func propertyTime(prop *ical.IANAProperty) (time.Time, bool, error) {
+	loc, err := propertyLocation(prop, time.Local)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return parseIcalDateTime(prop.Value, loc)
+}
+
+// propertyLocation resolves the TZID parameter of a property, falling
+// back to the given location when the property doesn't have one
+func propertyLocation(prop *ical.IANAProperty, fallback *time.Location) (*time.Location, error) {
+	tzid, ok := prop.ICalParameters["TZID"]
+	if !ok {
+		return fallback, nil
+	}
+
+	if len(tzid) != 1 {
+		return nil, fmt.Errorf("expected only one TZID")
+	}
+
+	return LoadTimezone(tzid[0])
+}
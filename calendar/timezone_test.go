@@ -0,0 +1,177 @@
+package calendar_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// A VTIMEZONE in the style published by Office 365,
+// which uses Windows timezone names as TZIDs
+const windowsTimezoneCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:what-next-tests
+BEGIN:VTIMEZONE
+TZID:GMT Standard Time
+BEGIN:STANDARD
+DTSTART:16010101T020000
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0000
+RRULE:FREQ=YEARLY;INTERVAL=1;BYDAY=-1SU;BYMONTH=10
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:16010101T010000
+TZOFFSETFROM:+0000
+TZOFFSETTO:+0100
+RRULE:FREQ=YEARLY;INTERVAL=1;BYDAY=-1SU;BYMONTH=3
+END:DAYLIGHT
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:standup
+SUMMARY:Standup
+DTSTART;TZID=GMT Standard Time:20220704T093000
+DTEND;TZID=GMT Standard Time:20220704T094500
+END:VEVENT
+END:VCALENDAR
+`
+
+// A VTIMEZONE in the style published by Outlook, which names
+// timezones it has customised "Customized Time Zone"
+const customizedTokyoCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:what-next-tests
+BEGIN:VTIMEZONE
+TZID:Customized Time Zone
+BEGIN:STANDARD
+DTSTART:16010101T000000
+TZOFFSETFROM:+0900
+TZOFFSETTO:+0900
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:retro
+SUMMARY:Retro
+DTSTART;TZID=Customized Time Zone:20220704T093000
+DTEND;TZID=Customized Time Zone:20220704T103000
+END:VEVENT
+END:VCALENDAR
+`
+
+// registeredTimezone registers the timezones in the calendar,
+// and returns the one its first event starts in
+func registeredTimezone(content string) *time.Location {
+	cal := parseCalendar(content)
+	calendar.RegisterTimezones(cal)
+
+	start, _, err := calendar.EventStartAndEnd(cal.Events()[0])
+	Expect(err).ToNot(HaveOccurred())
+
+	return start.Location()
+}
+
+func parseCalendar(content string) *ical.Calendar {
+	cal, err := ical.ParseCalendar(strings.NewReader(strings.ReplaceAll(content, "\n", "\r\n")))
+	Expect(err).ToNot(HaveOccurred())
+	return cal
+}
+
+var _ = Describe("Timezone", func() {
+	Describe("LoadTimezone", func() {
+		It("loads IANA timezones", func() {
+			loc, err := calendar.LoadTimezone("America/New_York")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loc.String()).To(Equal("America/New_York"))
+		})
+
+		It("returns an error for timezones it doesn't know", func() {
+			_, err := calendar.LoadTimezone("Atlantis Standard Time")
+
+			Expect(err).To(MatchError(ContainSubstring("Atlantis Standard Time")))
+		})
+
+		It("loads timezones defined by a VTIMEZONE in a registered calendar", func() {
+			loc := registeredTimezone(windowsTimezoneCalendar)
+			Expect(loc.String()).To(Equal("GMT Standard Time"))
+
+			winter := time.Date(2022, time.January, 10, 12, 0, 0, 0, loc)
+			summer := time.Date(2022, time.July, 10, 12, 0, 0, 0, loc)
+			Expect(winter.UTC()).To(Equal(time.Date(2022, time.January, 10, 12, 0, 0, 0, time.UTC)))
+			Expect(summer.UTC()).To(Equal(time.Date(2022, time.July, 10, 11, 0, 0, 0, time.UTC)))
+		})
+
+		It("changes offset at the instant described by the VTIMEZONE", func() {
+			loc := registeredTimezone(windowsTimezoneCalendar)
+
+			beforeChange := time.Date(2022, time.March, 27, 0, 59, 0, 0, time.UTC).In(loc)
+			afterChange := time.Date(2022, time.March, 27, 1, 0, 0, 0, time.UTC).In(loc)
+
+			Expect(beforeChange.Hour()).To(Equal(0))
+			Expect(afterChange.Hour()).To(Equal(2))
+		})
+	})
+
+	Describe("EventStartAndEnd", func() {
+		It("resolves IANA TZID parameters", func() {
+			evt := ical.NewEvent("evt")
+			evt.SetProperty(ical.ComponentPropertyDtStart, "20221017T090000", &ical.KeyValues{Key: "TZID", Value: []string{"America/New_York"}})
+			evt.SetProperty(ical.ComponentPropertyDtEnd, "20221017T100000", &ical.KeyValues{Key: "TZID", Value: []string{"America/New_York"}})
+
+			start, end, err := calendar.EventStartAndEnd(evt)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start.UTC()).To(Equal(time.Date(2022, time.October, 17, 13, 0, 0, 0, time.UTC)))
+			Expect(end.UTC()).To(Equal(time.Date(2022, time.October, 17, 14, 0, 0, 0, time.UTC)))
+		})
+
+		It("resolves TZID parameters defined by a VTIMEZONE", func() {
+			cal := parseCalendar(windowsTimezoneCalendar)
+			calendar.RegisterTimezones(cal)
+
+			start, end, err := calendar.EventStartAndEnd(cal.Events()[0])
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start.UTC()).To(Equal(time.Date(2022, time.July, 4, 8, 30, 0, 0, time.UTC)))
+			Expect(end.UTC()).To(Equal(time.Date(2022, time.July, 4, 8, 45, 0, 0, time.UTC)))
+		})
+
+		It("resolves TZID parameters with the VTIMEZONE of their own calendar, when calendars define the same TZID differently", func() {
+			london := parseCalendar(strings.ReplaceAll(windowsTimezoneCalendar, "GMT Standard Time", "Customized Time Zone"))
+			tokyo := parseCalendar(customizedTokyoCalendar)
+			calendar.RegisterTimezones(london)
+			calendar.RegisterTimezones(tokyo)
+
+			londonStart, _, err := calendar.EventStartAndEnd(london.Events()[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(londonStart.UTC()).To(Equal(time.Date(2022, time.July, 4, 8, 30, 0, 0, time.UTC)))
+
+			tokyoStart, _, err := calendar.EventStartAndEnd(tokyo.Events()[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokyoStart.UTC()).To(Equal(time.Date(2022, time.July, 4, 0, 30, 0, 0, time.UTC)))
+			Expect(tokyoStart.Location().String()).To(Equal("Customized Time Zone"))
+		})
+
+		It("treats floating times as local times", func() {
+			evt := ical.NewEvent("evt")
+			evt.SetProperty(ical.ComponentPropertyDtStart, "20221017T090000")
+
+			start, _, err := calendar.EventStartAndEnd(evt)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start).To(Equal(time.Date(2022, time.October, 17, 9, 0, 0, 0, time.Local)))
+		})
+
+		It("returns an error naming a TZID it doesn't know", func() {
+			evt := ical.NewEvent("evt")
+			evt.SetProperty(ical.ComponentPropertyDtStart, "20221017T090000", &ical.KeyValues{Key: "TZID", Value: []string{"Atlantis Standard Time"}})
+
+			_, _, err := calendar.EventStartAndEnd(evt)
+
+			Expect(err).To(MatchError(ContainSubstring("Atlantis Standard Time")))
+		})
+	})
+})
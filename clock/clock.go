@@ -0,0 +1,35 @@
+package clock
+
+import "time"
+
+// Clock tells commands what time it is, so
+// that they can be run as if at another time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock that returns the current time
+type SystemClock struct{}
+
+func NewSystemClock() *SystemClock {
+	return &SystemClock{}
+}
+
+func (c *SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock is a Clock that always returns the same time
+type FixedClock struct {
+	now time.Time
+}
+
+func NewFixedClock(now time.Time) *FixedClock {
+	return &FixedClock{
+		now: now,
+	}
+}
+
+func (c *FixedClock) Now() time.Time {
+	return c.now
+}
@@ -3,12 +3,15 @@ package cmd
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/AP-Hunt/what-next/m/calendar"
 	"github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/todo"
 	"github.com/AP-Hunt/what-next/m/views"
 	ical "github.com/arran4/golang-ical"
+	"github.com/hako/durafmt"
 	"github.com/spf13/cobra"
 )
 
@@ -18,9 +21,10 @@ var CalendarRootCmd = &cobra.Command{
 }
 
 var CalendarViewCmd = &cobra.Command{
-	Use:     "view display_name",
-	Args:    cobra.ExactArgs(1),
-	Aliases: []string{"v"},
+	Use:                   "view display_name [--date date]",
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Aliases:               []string{"v"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
 
@@ -30,38 +34,39 @@ var CalendarViewCmd = &cobra.Command{
 		displayName := args[0]
 		calRecord, err := calService.GetCalendarByDisplayName(displayName)
 		if err != nil {
-			if _, ok := err.(calendar.ErrNotFound); ok {
-				fmt.Printf("cannot find calendar '%s'\n", displayName)
-				return nil
+			if _, ok := err.(*calendar.ErrNotFound); ok {
+				return drawMessage(ctx, fmt.Sprintf("Cannot find calendar with display name '%s'.", displayName))
 			}
 
-			fmt.Printf("error finding calendar: %s\n", err)
-			return nil
+			return fmt.Errorf("error finding calendar: %s", err)
 		}
 
 		cal, err := calService.OpenCalendar(calRecord.URL)
 		if err != nil {
-			fmt.Printf("error opening calendar: %s", err)
+			return fmt.Errorf("error opening calendar: %s", err)
 		}
 
-		startOfTheDay := time.Now().Truncate(24 * time.Hour)
+		startOfTheDay := calendar.StartOfDay(ctx.Clock().Now())
 
-		todayOnlyCal := ical.NewCalendar()
-		for _, evt := range cal.Events() {
-			startsToday, err := calendar.EventStartsToday(evt)
-			if err != nil {
-				continue
-			}
-			endsToday, err := calendar.EventEndsToday(evt)
+		if cmd.Flags().Lookup("date") != nil {
+			dateInput, err := cmd.Flags().GetString("date")
 			if err != nil {
-				continue
+				return err
 			}
 
-			if startsToday || endsToday {
-				todayOnlyCal.AddVEvent(evt)
+			if dateInput != "" {
+				startOfTheDay, err = parseTargetDate(dateInput, ctx.Clock().Now())
+				if err != nil {
+					return err
+				}
 			}
 		}
 
+		todayOnlyCal := ical.NewCalendar()
+		for _, evt := range eventsOnDay(cal, startOfTheDay) {
+			todayOnlyCal.AddVEvent(evt)
+		}
+
 		calendarView := &views.CalendarView{}
 		calendarViwData := &views.CalendarViewData{
 			Calendar:   todayOnlyCal,
@@ -99,15 +104,13 @@ var CalendarAddCmd = &cobra.Command{
 		_, err := calService.AddCalendar(url, displayName)
 		if err != nil {
 			if _, ok := err.(*calendar.ErrDuplicateCalendarDisplayName); ok {
-				fmt.Printf("Calendar with display name '%s' already exists\n", displayName)
-				return nil
+				return drawMessage(ctx, fmt.Sprintf("Calendar with display name '%s' already exists.", displayName))
 			}
 
 			return err
 		}
 
-		fmt.Printf("Added calendar %s (%s)\n", displayName, url)
-		return nil
+		return drawMessage(ctx, fmt.Sprintf("Added calendar %s (%s).", displayName, url))
 	},
 }
 
@@ -124,8 +127,7 @@ var CalendarRemoveCmd = &cobra.Command{
 		cal, err := calService.GetCalendarByDisplayName(displayName)
 		if err != nil {
 			if _, ok := err.(*calendar.ErrNotFound); ok {
-				fmt.Printf("Cannot find calendar with display name '%s'.\n", displayName)
-				return nil
+				return drawMessage(ctx, fmt.Sprintf("Cannot find calendar with display name '%s'.", displayName))
 			}
 
 			return err
@@ -136,8 +138,7 @@ var CalendarRemoveCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("Calendar '%s' removed.\n", displayName)
-		return nil
+		return drawMessage(ctx, fmt.Sprintf("Calendar '%s' removed.", displayName))
 	},
 }
 
@@ -161,9 +162,156 @@ var CalendarListCmd = &cobra.Command{
 	},
 }
 
+var CalendarBuffersCmd = &cobra.Command{
+	Use:                   "buffers display_name [--before duration] [--after duration] [--clear]",
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	Aliases:               []string{"b"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		calService := ctx.CalendarService()
+
+		displayName := args[0]
+
+		cal, err := calService.GetCalendarByDisplayName(displayName)
+		if err != nil {
+			if _, ok := err.(*calendar.ErrNotFound); ok {
+				return drawMessage(ctx, fmt.Sprintf("Cannot find calendar with display name '%s'.", displayName))
+			}
+
+			return err
+		}
+
+		before := cal.BufferBefore
+		after := cal.BufferAfter
+
+		if cmd.Flags().Lookup("clear") != nil {
+			clear, err := cmd.Flags().GetBool("clear")
+			if err != nil {
+				return err
+			}
+
+			if clear {
+				before = nil
+				after = nil
+			}
+		}
+
+		before, err = parseBufferFlag(cmd, "before", before)
+		if err != nil {
+			return err
+		}
+
+		after, err = parseBufferFlag(cmd, "after", after)
+		if err != nil {
+			return err
+		}
+
+		cal, err = calService.SetBuffers(cal.Id, before, after)
+		if err != nil {
+			return err
+		}
+
+		return drawMessage(ctx, fmt.Sprintf(
+			"Calendar '%s' has %s before and %s after its meetings.",
+			displayName,
+			describeBuffer(cal.BufferBefore),
+			describeBuffer(cal.BufferAfter),
+		))
+	},
+}
+
+// parseBufferFlag parses the duration given to the named flag,
+// and returns current when the flag isn't given
+func parseBufferFlag(cmd *cobra.Command, flag string, current *time.Duration) (*time.Duration, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return current, nil
+	}
+
+	bufferInput, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if bufferInput == "" {
+		return current, nil
+	}
+
+	parsedBuffer, err := durafmt.ParseString(bufferInput)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s buffer: %s", flag, err)
+	}
+
+	buffer := parsedBuffer.Duration()
+	return &buffer, nil
+}
+
+func describeBuffer(buffer *time.Duration) string {
+	if buffer == nil {
+		return "the global buffer"
+	}
+
+	return fmt.Sprintf("a %s buffer", durafmt.Parse(*buffer).LimitFirstN(2))
+}
+
+// parseTargetDate parses the day to view, which can be given in any of
+// the ways a due date can, or as yesterday, and returns the midnight it
+// begins at
+func parseTargetDate(input string, now time.Time) (time.Time, error) {
+	if strings.ToLower(strings.TrimSpace(input)) == "yesterday" {
+		return calendar.StartOfDay(now).AddDate(0, 0, -1), nil
+	}
+
+	date, err := todo.ParseDueDate(input, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", err)
+	}
+
+	return calendar.StartOfDay(date), nil
+}
+
+// eventsOnDay returns the events in the calendar which start or end on the
+// day, including the occurrences of recurring events
+func eventsOnDay(cal *ical.Calendar, startOfTheDay time.Time) []*ical.VEvent {
+	events := calendar.ExpandRecurringEvents(cal.Events(), startOfTheDay, startOfTheDay.AddDate(0, 0, 1))
+
+	onDay := []*ical.VEvent{}
+	for _, evt := range events {
+		startsToday, err := calendar.EventStartsOnDay(evt, startOfTheDay)
+		if err != nil {
+			continue
+		}
+		endsToday, err := calendar.EventEndsOnDay(evt, startOfTheDay)
+		if err != nil {
+			continue
+		}
+
+		if startsToday || endsToday {
+			onDay = append(onDay, evt)
+		}
+	}
+
+	return onDay
+}
+
 func init() {
+	CalendarViewCmd.Flags().String("date", "", calendarViewDateHelp)
 	CalendarRootCmd.AddCommand(CalendarViewCmd)
 	CalendarRootCmd.AddCommand(CalendarAddCmd)
 	CalendarRootCmd.AddCommand(CalendarRemoveCmd)
 	CalendarRootCmd.AddCommand(CalendarListCmd)
+
+	CalendarBuffersCmd.Flags().String("before", "", calendarBuffersBeforeHelp)
+	CalendarBuffersCmd.Flags().String("after", "", calendarBuffersAfterHelp)
+	CalendarBuffersCmd.Flags().Bool("clear", false, calendarBuffersClearHelp)
+	CalendarRootCmd.AddCommand(CalendarBuffersCmd)
 }
+
+var calendarViewDateHelp = `Optional. Date to view the calendar for, instead of today.
+
+Dates can be given in any of the ways due dates can, such as friday, next tuesday, in 3 days or 2026-10-23, and are assumed to be local time. yesterday can be given too.
+`
+
+var calendarBuffersBeforeHelp = `Optional. Time to keep free before each meeting in this calendar, instead of the global buffer. Durations can be provided in a human readable form, e.g. '5m' or '0s'.`
+var calendarBuffersAfterHelp = `Optional. Time to keep free after each meeting in this calendar, instead of the global buffer. Durations can be provided in a human readable form, e.g. '5m' or '0s'.`
+var calendarBuffersClearHelp = `Optional. Go back to using the global buffers for this calendar. Can be combined with --before and --after.`
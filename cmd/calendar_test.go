@@ -6,6 +6,7 @@ import (
 
 	"github.com/AP-Hunt/what-next/m/calendar"
 	. "github.com/AP-Hunt/what-next/m/calendar/fakes"
+	"github.com/AP-Hunt/what-next/m/clock"
 	"github.com/AP-Hunt/what-next/m/cmd"
 	commandContext "github.com/AP-Hunt/what-next/m/context"
 	"github.com/AP-Hunt/what-next/m/views"
@@ -21,20 +22,36 @@ var _ = Describe("Calendar", func() {
 			viewEngine      *FakeViewEngineInterface
 			calendarService *FakeCalendarServiceInterface
 			cmdContext      commandContext.CommandContext
+			now             time.Time
 		)
 
 		BeforeEach(func() {
 			viewEngine = &FakeViewEngineInterface{}
 			calendarService = &FakeCalendarServiceInterface{}
+			now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
 
 			cmdContext = commandContext.NewCommandContext(context.Background()).
 				WithCalendarService(calendarService).
-				WithViewEngine(viewEngine)
+				WithViewEngine(viewEngine).
+				WithClock(clock.NewFixedClock(now))
 
 		})
 
+		It("says when there's no calendar with the display name through the view engine", func() {
+			calendarService.GetCalendarByDisplayNameReturns(nil, calendar.NewErrNotFound("no calendar"))
+			PrepareCommandForTest(cmd.CalendarViewCmd, []string{"work"})
+
+			err := cmd.CalendarViewCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(calendarService.OpenCalendarCallCount()).To(Equal(0))
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.MessageView{}))
+			Expect(drawnView.Data()).To(Equal("Cannot find calendar with display name 'work'."))
+		})
+
 		It("restricts the calendar entries to those occurring today", func() {
-			midnight := time.Now().Truncate(24 * time.Hour)
+			midnight := calendar.StartOfDay(now)
 
 			cal := ical.NewCalendar()
 			evtInsideToday := cal.AddEvent("1")
@@ -84,5 +101,232 @@ var _ = Describe("Calendar", func() {
 
 			Expect(selectedIds).To(Equal([]string{"1", "2", "3"}))
 		})
+
+		It("includes today's occurrences of recurring events", func() {
+			midnight := calendar.StartOfDay(now)
+
+			cal := ical.NewCalendar()
+			recurringEvt := cal.AddEvent("standup")
+			recurringEvt.SetStartAt(midnight.Add(-7*24*time.Hour + 9*time.Hour))
+			recurringEvt.SetEndAt(midnight.Add(-7*24*time.Hour + 9*time.Hour + 15*time.Minute))
+			recurringEvt.AddRrule("FREQ=DAILY")
+
+			calendarService.GetCalendarByDisplayNameReturns(&calendar.CalendarRecord{
+				Id:          1,
+				DisplayName: "foo",
+				URL:         "file://an.ical",
+			}, nil)
+
+			calendarService.OpenCalendarReturns(cal, nil)
+
+			PrepareCommandForTest(cmd.CalendarViewCmd, []string{"foo"})
+
+			err := cmd.CalendarViewCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			view := viewEngine.DrawArgsForCall(0)
+			viewData := view.Data().(*views.CalendarViewData)
+
+			passedEvents := viewData.Calendar.Events()
+			Expect(passedEvents).To(HaveLen(1))
+
+			start, _, err := calendar.EventStartAndEnd(passedEvents[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start).To(BeTemporally("==", midnight.Add(9*time.Hour)))
+		})
+
+		It("restricts the calendar entries to those occurring on the --date given", func() {
+			midnight := calendar.StartOfDay(now)
+
+			cal := ical.NewCalendar()
+			evtToday := cal.AddEvent("today")
+			evtToday.SetStartAt(midnight.Add(10 * time.Hour))
+			evtToday.SetEndAt(midnight.Add(11 * time.Hour))
+
+			// "now" is a Monday, so Friday is 4 days later
+			evtFriday := cal.AddEvent("friday")
+			evtFriday.SetStartAt(midnight.AddDate(0, 0, 4).Add(10 * time.Hour))
+			evtFriday.SetEndAt(midnight.AddDate(0, 0, 4).Add(11 * time.Hour))
+
+			calendarService.GetCalendarByDisplayNameReturns(&calendar.CalendarRecord{
+				Id:          1,
+				DisplayName: "foo",
+				URL:         "file://an.ical",
+			}, nil)
+
+			calendarService.OpenCalendarReturns(cal, nil)
+
+			PrepareCommandForTest(cmd.CalendarViewCmd, []string{"foo", "--date", "friday"})
+			cmd.CalendarViewCmd.Flags().String("date", "", "")
+
+			err := cmd.CalendarViewCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			view := viewEngine.DrawArgsForCall(0)
+			viewData := view.Data().(*views.CalendarViewData)
+
+			Expect(viewData.TargetDate).To(Equal(midnight.AddDate(0, 0, 4)))
+
+			passedEvents := viewData.Calendar.Events()
+			Expect(passedEvents).To(HaveLen(1))
+			Expect(passedEvents[0].Id()).To(Equal("friday"))
+		})
+
+		It("understands --date given in any of the ways a due date can be", func() {
+			midnight := calendar.StartOfDay(now)
+
+			calendarService.GetCalendarByDisplayNameReturns(&calendar.CalendarRecord{
+				Id:          1,
+				DisplayName: "foo",
+				URL:         "file://an.ical",
+			}, nil)
+			calendarService.OpenCalendarReturns(ical.NewCalendar(), nil)
+
+			// "now" is a Monday, so next Monday is a week later
+			for input, expected := range map[string]time.Time{
+				"next monday": midnight.AddDate(0, 0, 7),
+				"in 3 days":   midnight.AddDate(0, 0, 3),
+				"+2w":         midnight.AddDate(0, 0, 14),
+				"yesterday":   midnight.AddDate(0, 0, -1),
+				"2022-10-20":  midnight.AddDate(0, 0, 3),
+			} {
+				PrepareCommandForTest(cmd.CalendarViewCmd, []string{"foo", "--date", input})
+				cmd.CalendarViewCmd.Flags().String("date", "", "")
+
+				err := cmd.CalendarViewCmd.ExecuteContext(cmdContext)
+				Expect(err).ToNot(HaveOccurred())
+
+				view := viewEngine.DrawArgsForCall(viewEngine.DrawCallCount() - 1)
+				Expect(view.Data().(*views.CalendarViewData).TargetDate).To(Equal(expected), input)
+			}
+		})
+	})
+
+	Describe("Add and remove", func() {
+		var (
+			viewEngine      *FakeViewEngineInterface
+			calendarService *FakeCalendarServiceInterface
+			cmdContext      commandContext.CommandContext
+		)
+
+		BeforeEach(func() {
+			viewEngine = &FakeViewEngineInterface{}
+			calendarService = &FakeCalendarServiceInterface{}
+
+			cmdContext = commandContext.NewCommandContext(context.Background()).
+				WithCalendarService(calendarService).
+				WithViewEngine(viewEngine)
+		})
+
+		It("says what was added through the view engine", func() {
+			PrepareCommandForTest(cmd.CalendarAddCmd, []string{"work", "file://work.ical"})
+
+			err := cmd.CalendarAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.MessageView{}))
+			Expect(drawnView.Data()).To(Equal("Added calendar work (file://work.ical)."))
+		})
+
+		It("says when a calendar with the display name already exists through the view engine", func() {
+			calendarService.AddCalendarReturns(nil, &calendar.ErrDuplicateCalendarDisplayName{})
+			PrepareCommandForTest(cmd.CalendarAddCmd, []string{"work", "file://work.ical"})
+
+			err := cmd.CalendarAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(viewEngine.DrawArgsForCall(0).Data()).To(Equal("Calendar with display name 'work' already exists."))
+		})
+
+		It("says what was removed through the view engine", func() {
+			calendarService.GetCalendarByDisplayNameReturns(&calendar.CalendarRecord{Id: 3, DisplayName: "work"}, nil)
+			PrepareCommandForTest(cmd.CalendarRemoveCmd, []string{"work"})
+
+			err := cmd.CalendarRemoveCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(calendarService.RemoveByIdArgsForCall(0)).To(Equal(3))
+			Expect(viewEngine.DrawArgsForCall(0).Data()).To(Equal("Calendar 'work' removed."))
+		})
+
+		It("says when there's no calendar to remove through the view engine", func() {
+			calendarService.GetCalendarByDisplayNameReturns(nil, calendar.NewErrNotFound("no calendar"))
+			PrepareCommandForTest(cmd.CalendarRemoveCmd, []string{"work"})
+
+			err := cmd.CalendarRemoveCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(calendarService.RemoveByIdCallCount()).To(Equal(0))
+			Expect(viewEngine.DrawArgsForCall(0).Data()).To(Equal("Cannot find calendar with display name 'work'."))
+		})
+	})
+
+	Describe("Buffers", func() {
+		var (
+			viewEngine      *FakeViewEngineInterface
+			calendarService *FakeCalendarServiceInterface
+			cmdContext      commandContext.CommandContext
+		)
+
+		BeforeEach(func() {
+			viewEngine = &FakeViewEngineInterface{}
+			calendarService = &FakeCalendarServiceInterface{}
+
+			cmdContext = commandContext.NewCommandContext(context.Background()).
+				WithCalendarService(calendarService).
+				WithViewEngine(viewEngine)
+
+			after := 5 * time.Minute
+			calendarService.GetCalendarByDisplayNameReturns(&calendar.CalendarRecord{
+				Id:          1,
+				DisplayName: "foo",
+				URL:         "file://an.ical",
+				BufferAfter: &after,
+			}, nil)
+			calendarService.SetBuffersReturns(&calendar.CalendarRecord{Id: 1}, nil)
+		})
+
+		It("sets the buffers given, and keeps the others", func() {
+			PrepareCommandForTest(cmd.CalendarBuffersCmd, []string{"foo", "--before", "10m"})
+			cmd.CalendarBuffersCmd.Flags().String("before", "", "")
+			cmd.CalendarBuffersCmd.Flags().String("after", "", "")
+			cmd.CalendarBuffersCmd.Flags().Bool("clear", false, "")
+
+			err := cmd.CalendarBuffersCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, before, after := calendarService.SetBuffersArgsForCall(0)
+			Expect(id).To(Equal(1))
+			Expect(*before).To(Equal(10 * time.Minute))
+			Expect(*after).To(Equal(5 * time.Minute))
+
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.MessageView{}))
+			Expect(drawnView.Data()).To(ContainSubstring("Calendar 'foo' has"))
+		})
+
+		It("goes back to the global buffers when cleared", func() {
+			PrepareCommandForTest(cmd.CalendarBuffersCmd, []string{"foo", "--clear"})
+			cmd.CalendarBuffersCmd.Flags().String("before", "", "")
+			cmd.CalendarBuffersCmd.Flags().String("after", "", "")
+			cmd.CalendarBuffersCmd.Flags().Bool("clear", false, "")
+
+			err := cmd.CalendarBuffersCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, before, after := calendarService.SetBuffersArgsForCall(0)
+			Expect(before).To(BeNil())
+			Expect(after).To(BeNil())
+		})
+
+		It("returns an error for buffers which aren't durations", func() {
+			PrepareCommandForTest(cmd.CalendarBuffersCmd, []string{"foo", "--before", "soon"})
+			cmd.CalendarBuffersCmd.Flags().String("before", "", "")
+
+			err := cmd.CalendarBuffersCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("--before")))
+			Expect(calendarService.SetBuffersCallCount()).To(Equal(0))
+		})
 	})
 })
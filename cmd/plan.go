@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/views"
+	"github.com/spf13/cobra"
+)
+
+var PlanCmd = &cobra.Command{
+	Use:     "plan",
+	Aliases: []string{"p"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		viewEngine := ctx.ViewEngine()
+		calService := ctx.CalendarService()
+		repo := ctx.TodoRepository()
+
+		calendars, options, err := openAllCalendars(calService, ctx.SchedulerOptions())
+		if err != nil {
+			return err
+		}
+
+		todoList, err := repo.List()
+		if err != nil {
+			return err
+		}
+
+		plan, err := scheduler.GeneratePlan(ctx.Clock().Now(), calendars, todoList, options)
+		if err != nil {
+			return err
+		}
+
+		planView := views.PlanView{}
+		planView.SetData(plan)
+
+		return viewEngine.Draw(&planView)
+	},
+}
@@ -0,0 +1,70 @@
+package cmd_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	. "github.com/AP-Hunt/what-next/m/calendar/fakes"
+	"github.com/AP-Hunt/what-next/m/clock"
+	"github.com/AP-Hunt/what-next/m/cmd"
+	commandContext "github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/AP-Hunt/what-next/m/todo/fakes"
+	"github.com/AP-Hunt/what-next/m/views"
+	. "github.com/AP-Hunt/what-next/m/views/fakes"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		viewEngine      *FakeViewEngineInterface
+		calendarService *FakeCalendarServiceInterface
+		todoRepo        *FakeTodoRepositoryInterface
+		cmdContext      commandContext.CommandContext
+		now             time.Time
+	)
+
+	BeforeEach(func() {
+		viewEngine = &FakeViewEngineInterface{}
+		calendarService = &FakeCalendarServiceInterface{}
+		todoRepo = &FakeTodoRepositoryInterface{}
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+		cmdContext = commandContext.NewCommandContext(context.Background()).
+			WithCalendarService(calendarService).
+			WithTodoRepository(todoRepo).
+			WithViewEngine(viewEngine).
+			WithClock(clock.NewFixedClock(now)).
+			WithSchedulerOptions(scheduler.Options{})
+	})
+
+	It("plans the rest of the day from now, with events from every calendar, and renders a Plan view", func() {
+		cal := ical.NewCalendar()
+		evt := cal.AddEvent("standup")
+		evt.SetStartAt(now.Add(30 * time.Minute))
+		evt.SetEndAt(now.Add(45 * time.Minute))
+
+		calendarService.GetAllCalendarsReturns([]calendar.CalendarRecord{
+			{Id: 1, DisplayName: "work", URL: "file://an.ical"},
+		}, nil)
+		calendarService.OpenCalendarReturns(cal, nil)
+		todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{}), nil)
+
+		PrepareCommandForTest(cmd.PlanCmd, []string{})
+
+		err := cmd.PlanCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		drawnView := viewEngine.DrawArgsForCall(0)
+		Expect(drawnView).To(BeAssignableToTypeOf(&views.PlanView{}))
+
+		plan := drawnView.Data().(*scheduler.Plan)
+		Expect(plan.Start).To(Equal(now))
+		Expect(plan.Entries[1].Event).To(Equal(evt))
+	})
+})
@@ -1,58 +1,345 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"text/template"
 	"time"
 
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/clock"
 	"github.com/AP-Hunt/what-next/m/context"
 	. "github.com/AP-Hunt/what-next/m/context"
 	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/terminal"
 	"github.com/AP-Hunt/what-next/m/views"
+	"github.com/araddon/dateparse"
 	ical "github.com/arran4/golang-ical"
 	"github.com/spf13/cobra"
 )
 
+// defaultWatchInterval is how often --watch generates the schedule again
+const defaultWatchInterval = time.Minute
+
 var RootCmd = &cobra.Command{
 	Use:     "what-next",
 	Version: Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+
+		ctx, err := withAtFlag(cmd, ctx)
+		if err != nil {
+			return err
+		}
+
+		ctx, err = withOutputFlag(cmd, ctx)
+		if err != nil {
+			return err
+		}
+
+		cmd.SetContext(ctx)
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
-		viewEngine := ctx.ViewEngine()
-		calService := ctx.CalendarService()
-		repo := ctx.TodoRepository()
 
-		allCalendarRecords, err := calService.GetAllCalendars()
+		watch, err := getBoolFlag(cmd, "watch")
 		if err != nil {
 			return err
 		}
 
-		calendars := []*ical.Calendar{}
-		for _, record := range allCalendarRecords {
-			cal, err := calService.OpenCalendar(record.URL)
+		if watch {
+			return watchSchedule(cmd, ctx)
+		}
+
+		if flagChanged(cmd, "interval") {
+			return fmt.Errorf("--interval can only be used with --watch")
+		}
+
+		schedule, err := generateSchedule(cmd, ctx)
+		if err != nil {
+			return err
+		}
+
+		return drawSchedule(ctx, schedule)
+	},
+}
+
+// generateSchedule generates the schedule for now, from
+// every calendar and the todo items picked out by the tags
+func generateSchedule(cmd *cobra.Command, ctx context.CommandContext) (*scheduler.Schedule, error) {
+	calendars, options, err := openAllCalendars(ctx.CalendarService(), ctx.SchedulerOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	todoList, err := ctx.TodoRepository().List()
+	if err != nil {
+		return nil, err
+	}
+
+	todoList, err = filterByTags(cmd, todoList)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduler.GenerateSchedule(ctx.Clock().Now(), calendars, todoList, options)
+}
+
+func drawSchedule(ctx context.CommandContext, schedule *scheduler.Schedule) error {
+	scheduleView := views.ScheduleView{}
+	scheduleView.SetData(schedule)
+
+	return ctx.ViewEngine().Draw(&scheduleView)
+}
+
+// drawMessage shows a message about what a command did, for commands
+// which have nothing else to show, through the view engine so that it
+// can be written in the output format given by --output
+func drawMessage(ctx context.CommandContext, message string) error {
+	view := views.MessageView{}
+	view.SetData(message)
+
+	return ctx.ViewEngine().Draw(&view)
+}
+
+// watchSchedule generates the schedule again every --interval, and draws it
+// over the last one, until it's interrupted. Calendars are only fetched again
+// when their cached copies expire, because they're opened through the cache.
+//
+// When the schedule can't be generated again, such as when a calendar can't
+// be fetched, the last one is left on screen with the error below it.
+func watchSchedule(cmd *cobra.Command, ctx context.CommandContext) error {
+	if flagChanged(cmd, "at") {
+		return fmt.Errorf("--watch cannot be used with --at, because the time would never change")
+	}
+
+	interval := defaultWatchInterval
+	if cmd.Flags().Lookup("interval") != nil {
+		var err error
+		interval, err = cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("invalid --interval %s, it must be more than 0", interval)
+	}
+
+	// Drawing over the last schedule would garble JSON
+	// and templates, so they're written one after another
+	output, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	redrawInPlace := output == "" || output == "text"
+
+	watchCtx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		schedule, err := generateSchedule(cmd, ctx)
+		if err != nil && first {
+			return err
+		}
+
+		if err != nil {
+			cmd.PrintErrf("Couldn't update the schedule: %s\n", err)
+		} else {
+			if redrawInPlace {
+				err = terminal.Clear(cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+			}
+
+			err = drawSchedule(ctx, schedule)
 			if err != nil {
 				return err
 			}
+		}
 
-			calendars = append(calendars, cal)
+		select {
+		case <-watchCtx.Done():
+			return nil
+		case <-ticker.C:
 		}
+	}
+}
+
+// withAtFlag returns a context whose clock is fixed at
+// the time given by --at, when it's given
+func withAtFlag(cmd *cobra.Command, ctx context.CommandContext) (context.CommandContext, error) {
+	if cmd.Flags().Lookup("at") == nil {
+		return ctx, nil
+	}
+
+	atInput, err := cmd.Flags().GetString("at")
+	if err != nil {
+		return ctx, err
+	}
+
+	if atInput == "" {
+		return ctx, nil
+	}
+
+	at, err := dateparse.ParseLocal(atInput)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid --at time: %s", err)
+	}
+
+	return ctx.WithClock(clock.NewFixedClock(at)), nil
+}
+
+// withOutputFlag returns a context whose view engine writes the output
+// format given by --output to the command's output, which is where
+// anything else the command writes, such as --watch clearing the
+// screen, goes too
+func withOutputFlag(cmd *cobra.Command, ctx context.CommandContext) (context.CommandContext, error) {
+	if cmd.Flags().Lookup("output") == nil {
+		return ctx, nil
+	}
+
+	output, err := outputFormat(cmd)
+	if err != nil {
+		return ctx, err
+	}
 
-		todoList, err := repo.List()
+	switch output {
+	case "", "text":
+		return ctx.WithViewEngine(views.NewTextViewEngine(cmd.OutOrStdout())), nil
+	case "json":
+		return ctx.WithViewEngine(views.NewJSONViewEngine(cmd.OutOrStdout())), nil
+	case "template":
+		tmpl, err := parseTemplateFlags(cmd, ctx)
 		if err != nil {
-			return err
+			return ctx, err
 		}
+		return ctx.WithViewEngine(views.NewTemplateViewEngine(cmd.OutOrStdout(), tmpl)), nil
+	default:
+		return ctx, fmt.Errorf("invalid --output '%s', expected text, json or template", output)
+	}
+}
 
-		schedule, err := scheduler.GenerateSchedule(time.Now(), calendars, todoList)
+// outputFormat is the output format given by --output, or
+// template when a template is given without an output format
+func outputFormat(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Lookup("output") == nil {
+		return "", nil
+	}
 
-		scheduleView := views.ScheduleView{}
-		scheduleView.SetData(schedule)
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
 
-		return viewEngine.Draw(&scheduleView)
-	},
+	// Giving a template is enough to ask for it to be used
+	if !cmd.Flags().Changed("output") && (flagChanged(cmd, "template-file") || flagChanged(cmd, "template")) {
+		output = "template"
+	}
+
+	return output, nil
+}
+
+// parseTemplateFlags parses the template in the file given by --template-file,
+// or the named template given by --template from the templates directory
+func parseTemplateFlags(cmd *cobra.Command, ctx context.CommandContext) (*template.Template, error) {
+	templateFile := ""
+	if cmd.Flags().Lookup("template-file") != nil {
+		var err error
+		templateFile, err = cmd.Flags().GetString("template-file")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	templateName := ""
+	if cmd.Flags().Lookup("template") != nil {
+		var err error
+		templateName, err = cmd.Flags().GetString("template")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case templateFile != "" && templateName != "":
+		return nil, fmt.Errorf("--template-file and --template cannot be used together")
+	case templateFile != "":
+		return views.ParseTemplateFile(templateFile)
+	case templateName != "":
+		return views.ParseNamedTemplate(templatesDir(ctx), templateName)
+	default:
+		return nil, fmt.Errorf("--output template needs a --template-file or --template to use")
+	}
+}
+
+// templatesDir is where the named templates are kept
+func templatesDir(ctx context.CommandContext) string {
+	return filepath.Join(ctx.ConfigDir(), "templates")
+}
+
+// openAllCalendars opens every calendar, and returns them along with
+// a copy of the scheduler options which has the buffers for each of them
+func openAllCalendars(calService calendar.CalendarServiceInterface, options scheduler.Options) ([]*ical.Calendar, scheduler.Options, error) {
+	allCalendarRecords, err := calService.GetAllCalendars()
+	if err != nil {
+		return nil, options, err
+	}
+
+	calendars := []*ical.Calendar{}
+	options.CalendarBuffers = map[*ical.Calendar]scheduler.Buffers{}
+	for _, record := range allCalendarRecords {
+		cal, err := calService.OpenCalendar(record.URL)
+		if err != nil {
+			return nil, options, err
+		}
+
+		calendars = append(calendars, cal)
+		options.CalendarBuffers[cal] = calendarBuffers(record, options.Buffers)
+	}
+
+	return calendars, options, nil
+}
+
+// calendarBuffers returns the buffers around the meetings in the calendar,
+// using the global buffers for any which the calendar doesn't set
+func calendarBuffers(record calendar.CalendarRecord, global scheduler.Buffers) scheduler.Buffers {
+	buffers := global
+
+	if record.BufferBefore != nil {
+		buffers.Before = *record.BufferBefore
+	}
+
+	if record.BufferAfter != nil {
+		buffers.After = *record.BufferAfter
+	}
+
+	return buffers
 }
 
 func init() {
+	RootCmd.PersistentFlags().String("at", "", rootAtHelp)
+	RootCmd.PersistentFlags().StringP("output", "o", "text", rootOutputHelp)
+	RootCmd.PersistentFlags().String("template-file", "", rootTemplateFileHelp)
+	RootCmd.PersistentFlags().String("template", "", rootTemplateHelp)
+	RootCmd.Flags().StringSlice("tag", []string{}, rootTagHelp)
+	RootCmd.Flags().StringSlice("exclude-tag", []string{}, rootExcludeTagHelp)
+	RootCmd.Flags().Bool("watch", false, rootWatchHelp)
+	RootCmd.Flags().Duration("interval", defaultWatchInterval, rootIntervalHelp)
 	RootCmd.AddCommand(VersionCmd)
 	RootCmd.AddCommand(TodoRootCmd)
 	RootCmd.AddCommand(CalendarRootCmd)
+	RootCmd.AddCommand(PlanCmd)
+	RootCmd.AddCommand(SearchCmd)
+	RootCmd.AddCommand(StatusCmd)
+	RootCmd.AddCommand(TuiCmd)
 }
 
 func ExecuteCWithArgs(ctx CommandContext, args []string) error {
@@ -63,3 +350,23 @@ func ExecuteCWithArgs(ctx CommandContext, args []string) error {
 func ExecuteC(ctx CommandContext) error {
 	return RootCmd.ExecuteContext(ctx)
 }
+
+var rootAtHelp = `Optional. Run as if it were this date and time, instead of now. Useful for planning ahead.
+
+The date and time can be any valid datetime string, and are assumed to be local time. For example:
+* "2026-10-19 09:30"
+`
+
+var rootTagHelp = `Optional. Only suggest tasks with this tag, e.g. @phone. Can be given more than once, or as a comma separated list, to only suggest tasks with all of the tags.`
+
+var rootExcludeTagHelp = `Optional. Don't suggest tasks with this tag. Can be given more than once, or as a comma separated list.`
+
+var rootOutputHelp = `Optional. How to show the output: text, json for scripts, or template to use your own text/template. The JSON schemas are described in JSON.md.`
+
+var rootTemplateFileHelp = `Optional. Path of a text/template file to show the output with, instead of the built in views. Implies --output template.`
+
+var rootTemplateHelp = `Optional. Name of a template in the templates directory of your ~/.what-next directory to show the output with, e.g. 'compact' for templates/compact.tmpl. Implies --output template.`
+
+var rootWatchHelp = `Optional. Keep the schedule on screen, and update it every --interval until interrupted with Ctrl+C. Calendars are only fetched again when their cached copies expire.`
+
+var rootIntervalHelp = `Optional. How often --watch updates the schedule, such as 30s or 5m. Can only be used with --watch.`
@@ -0,0 +1,201 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	. "github.com/AP-Hunt/what-next/m/calendar/fakes"
+	"github.com/AP-Hunt/what-next/m/cmd"
+	commandContext "github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/AP-Hunt/what-next/m/todo/fakes"
+	"github.com/AP-Hunt/what-next/m/views"
+	. "github.com/AP-Hunt/what-next/m/views/fakes"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// tickingClock is a clock which moves on a minute every time it's asked the time
+type tickingClock struct {
+	now time.Time
+}
+
+func (c *tickingClock) Now() time.Time {
+	now := c.now
+	c.now = c.now.Add(time.Minute)
+	return now
+}
+
+var _ = Describe("Watch", func() {
+	var (
+		viewEngine      *FakeViewEngineInterface
+		calendarService *FakeCalendarServiceInterface
+		todoRepo        *FakeTodoRepositoryInterface
+		cmdContext      commandContext.CommandContext
+		cancel          context.CancelFunc
+		now             time.Time
+		meeting         *ical.VEvent
+	)
+
+	prepareRootCmd := func(args ...string) {
+		PrepareCommandForTest(cmd.RootCmd, args)
+		cmd.RootCmd.PersistentFlags().String("at", "", "")
+		cmd.RootCmd.Flags().Bool("watch", false, "")
+		cmd.RootCmd.Flags().Duration("interval", time.Minute, "")
+	}
+
+	BeforeEach(func() {
+		viewEngine = &FakeViewEngineInterface{}
+		calendarService = &FakeCalendarServiceInterface{}
+		todoRepo = &FakeTodoRepositoryInterface{}
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+		var parent context.Context
+		parent, cancel = context.WithCancel(context.Background())
+
+		cmdContext = commandContext.NewCommandContext(parent).
+			WithCalendarService(calendarService).
+			WithTodoRepository(todoRepo).
+			WithViewEngine(viewEngine).
+			WithClock(&tickingClock{now: now}).
+			WithSchedulerOptions(scheduler.Options{})
+
+		cal := ical.NewCalendar()
+		meeting = cal.AddEvent("standup")
+		meeting.SetSummary("standup")
+		meeting.SetStartAt(now.Add(30 * time.Minute))
+		meeting.SetEndAt(now.Add(45 * time.Minute))
+
+		calendarService.GetAllCalendarsReturns([]calendar.CalendarRecord{
+			{Id: 1, DisplayName: "work", URL: "file://work.ical"},
+		}, nil)
+		calendarService.OpenCalendarReturns(cal, nil)
+		todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{}), nil)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	// stopAfterDraws stops watching once the schedule has been drawn a number of times
+	stopAfterDraws := func(draws int) {
+		viewEngine.DrawStub = func(view views.ViewInterface) error {
+			if viewEngine.DrawCallCount() >= draws {
+				cancel()
+			}
+			return nil
+		}
+	}
+
+	It("generates the schedule again every interval, counting down to the next meeting", func() {
+		stopAfterDraws(3)
+
+		prepareRootCmd("--watch", "--interval", "10ms")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(viewEngine.DrawCallCount()).To(Equal(3))
+		untilMeeting := []time.Duration{}
+		for i := 0; i < 3; i++ {
+			schedule := viewEngine.DrawArgsForCall(i).Data().(*scheduler.Schedule)
+			Expect(schedule.NextCalendarEvents).To(ConsistOf(meeting))
+			untilMeeting = append(untilMeeting, *schedule.TimeUntilNextCalendarEvent)
+		}
+		Expect(untilMeeting).To(Equal([]time.Duration{30 * time.Minute, 29 * time.Minute, 28 * time.Minute}))
+	})
+
+	It("opens the calendars through the calendar service each time, so they're only fetched when the cache expires", func() {
+		stopAfterDraws(2)
+
+		prepareRootCmd("--watch", "--interval", "10ms")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calendarService.OpenCalendarCallCount()).To(Equal(2))
+		Expect(calendarService.OpenCalendarArgsForCall(1)).To(Equal("file://work.ical"))
+	})
+
+	It("keeps watching when the schedule can't be generated again", func() {
+		stopAfterDraws(2)
+		calendarService.OpenCalendarReturnsOnCall(1, nil, errors.New("network unavailable"))
+
+		prepareRootCmd("--watch", "--interval", "10ms")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calendarService.OpenCalendarCallCount()).To(Equal(3))
+		Expect(viewEngine.DrawCallCount()).To(Equal(2))
+	})
+
+	It("returns the error when the schedule can't be generated the first time", func() {
+		calendarService.OpenCalendarReturns(nil, errors.New("network unavailable"))
+
+		prepareRootCmd("--watch", "--interval", "10ms")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).To(MatchError("network unavailable"))
+	})
+
+	It("returns an error when the interval isn't more than 0", func() {
+		prepareRootCmd("--watch", "--interval", "0s")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).To(HaveOccurred())
+		Expect(viewEngine.DrawCallCount()).To(Equal(0))
+	})
+
+	It("returns an error when used with --at", func() {
+		prepareRootCmd("--watch", "--at", "2022-10-18 09:30")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).To(HaveOccurred())
+		Expect(viewEngine.DrawCallCount()).To(Equal(0))
+	})
+
+	It("returns an error when --interval is given without --watch", func() {
+		prepareRootCmd("--interval", "30s")
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).To(MatchError("--interval can only be used with --watch"))
+		Expect(viewEngine.DrawCallCount()).To(Equal(0))
+	})
+
+	It("clears the screen and draws the schedule on the command's output", func() {
+		prepareRootCmd("--watch", "--interval", "10ms", "--output", "text")
+		cmd.RootCmd.PersistentFlags().StringP("output", "o", "text", "")
+		out := &bytes.Buffer{}
+		cmd.RootCmd.SetOut(out)
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(out.String()).To(HavePrefix("\x1b[H\x1b[J"))
+		Expect(out.String()).To(ContainSubstring("standup"))
+	})
+
+	It("writes JSON on the command's output, one document after another", func() {
+		prepareRootCmd("--watch", "--interval", "10ms", "--output", "json")
+		cmd.RootCmd.PersistentFlags().StringP("output", "o", "text", "")
+		out := &bytes.Buffer{}
+		cmd.RootCmd.SetOut(out)
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(out.String()).To(HavePrefix("{"))
+		Expect(out.String()).ToNot(ContainSubstring("\x1b[H"))
+		Expect(out.String()).To(ContainSubstring(`"summary": "standup"`))
+	})
+
+	It("draws the schedule once without --watch", func() {
+		prepareRootCmd()
+		err := cmd.RootCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		Expect(viewEngine.DrawArgsForCall(0)).To(BeAssignableToTypeOf(&views.ScheduleView{}))
+	})
+})
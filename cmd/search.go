@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/search"
+	"github.com/AP-Hunt/what-next/m/views"
+	"github.com/spf13/cobra"
+)
+
+var SearchCmd = &cobra.Command{
+	Use:     "search query...",
+	Args:    cobra.MinimumNArgs(1),
+	Aliases: []string{"find"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		viewEngine := ctx.ViewEngine()
+		repo := ctx.TodoRepository()
+
+		todoList, err := repo.List()
+		if err != nil {
+			return err
+		}
+
+		events, err := cachedEvents(ctx.CalendarService())
+		if err != nil {
+			return err
+		}
+
+		query := strings.Join(args, " ")
+		results, err := ctx.SearchIndex().Search(query, todoList, events)
+		if err != nil {
+			return err
+		}
+
+		view := views.SearchResultsView{Now: ctx.Clock().Now()}
+		view.SetData(&views.SearchResultsViewData{
+			Query:   query,
+			Results: results,
+		})
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+// cachedEvents returns the events in the cached copy of every calendar,
+// leaving out calendars which haven't been cached
+func cachedEvents(calService calendar.CalendarServiceInterface) ([]search.CalendarEvent, error) {
+	records, err := calService.GetAllCalendars()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []search.CalendarEvent{}
+	for _, record := range records {
+		cal, err := calService.OpenCachedCalendar(record.URL)
+		if err != nil {
+			var cacheMiss *calendar.ErrCacheMiss
+			if errors.As(err, &cacheMiss) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, event := range cal.Events() {
+			events = append(events, search.CalendarEvent{Calendar: record.DisplayName, Event: event})
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	. "github.com/AP-Hunt/what-next/m/calendar/fakes"
+	"github.com/AP-Hunt/what-next/m/clock"
+	"github.com/AP-Hunt/what-next/m/cmd"
+	commandContext "github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/search"
+	. "github.com/AP-Hunt/what-next/m/search/fakes"
+	"github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/AP-Hunt/what-next/m/todo/fakes"
+	"github.com/AP-Hunt/what-next/m/views"
+	. "github.com/AP-Hunt/what-next/m/views/fakes"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Search", func() {
+	var (
+		viewEngine      *FakeViewEngineInterface
+		calendarService *FakeCalendarServiceInterface
+		todoRepo        *FakeTodoRepositoryInterface
+		searchIndex     *FakeSearchIndexInterface
+		cmdContext      commandContext.CommandContext
+		todoList        *todo.TodoItemCollection
+		now             time.Time
+	)
+
+	BeforeEach(func() {
+		viewEngine = &FakeViewEngineInterface{}
+		calendarService = &FakeCalendarServiceInterface{}
+		todoRepo = &FakeTodoRepositoryInterface{}
+		searchIndex = &FakeSearchIndexInterface{}
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+		todoList = todo.NewTodoItemCollection([]*todo.TodoItem{{Id: 1, Action: "Book dentist"}})
+		todoRepo.ListReturns(todoList, nil)
+		calendarService.GetAllCalendarsReturns([]calendar.CalendarRecord{}, nil)
+
+		cmdContext = commandContext.NewCommandContext(context.Background()).
+			WithCalendarService(calendarService).
+			WithTodoRepository(todoRepo).
+			WithViewEngine(viewEngine).
+			WithClock(clock.NewFixedClock(now)).
+			WithSearchIndex(searchIndex)
+	})
+
+	It("searches the todo list for all of the arguments, and renders a SearchResults view", func() {
+		results := []search.Result{{TodoItem: todoList.Enumerate()[0], Snippet: "Book dentist"}}
+		searchIndex.SearchReturns(results, nil)
+
+		PrepareCommandForTest(cmd.SearchCmd, []string{"book", "dentist"})
+
+		err := cmd.SearchCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		query, searchedTodos, _ := searchIndex.SearchArgsForCall(0)
+		Expect(query).To(Equal("book dentist"))
+		Expect(searchedTodos).To(Equal(todoList))
+
+		Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		drawnView := viewEngine.DrawArgsForCall(0)
+		Expect(drawnView).To(BeAssignableToTypeOf(&views.SearchResultsView{}))
+		Expect(drawnView.Data().(*views.SearchResultsViewData).Results).To(Equal(results))
+		Expect(drawnView.(*views.SearchResultsView).Now).To(Equal(now))
+	})
+
+	It("searches the cached copy of each calendar, and skips those which haven't been cached", func() {
+		cal := ical.NewCalendar()
+		standup := cal.AddEvent("standup")
+
+		calendarService.GetAllCalendarsReturns([]calendar.CalendarRecord{
+			{Id: 1, DisplayName: "work", URL: "https://example.com/work.ical"},
+			{Id: 2, DisplayName: "home", URL: "https://example.com/home.ical"},
+		}, nil)
+		calendarService.OpenCachedCalendarReturnsOnCall(0, cal, nil)
+		calendarService.OpenCachedCalendarReturnsOnCall(1, nil, &calendar.ErrCacheMiss{Key: "home", Reason: "not found"})
+
+		PrepareCommandForTest(cmd.SearchCmd, []string{"standup"})
+
+		err := cmd.SearchCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calendarService.OpenCalendarCallCount()).To(Equal(0))
+
+		_, _, events := searchIndex.SearchArgsForCall(0)
+		Expect(events).To(Equal([]search.CalendarEvent{{Calendar: "work", Event: standup}}))
+	})
+
+	It("returns the error when the search fails", func() {
+		searchIndex.SearchReturns(nil, search.ErrFTS5Unavailable)
+
+		PrepareCommandForTest(cmd.SearchCmd, []string{"dentist"})
+
+		err := cmd.SearchCmd.ExecuteContext(cmdContext)
+		Expect(errors.Is(err, search.ErrFTS5Unavailable)).To(BeTrue())
+	})
+
+	It("needs a query", func() {
+		PrepareCommandForTest(cmd.SearchCmd, []string{})
+
+		err := cmd.SearchCmd.ExecuteContext(cmdContext)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/views"
+	ical "github.com/arran4/golang-ical"
+	"github.com/spf13/cobra"
+)
+
+var StatusCmd = &cobra.Command{
+	Use:                   "status [--format format] [--max-width width] [--tag tag...] [--exclude-tag tag...]",
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		viewEngine := ctx.ViewEngine()
+		repo := ctx.TodoRepository()
+
+		calendars, options, err := openCachedCalendars(ctx.CalendarService(), ctx.SchedulerOptions())
+		if err != nil {
+			return err
+		}
+
+		todoList, err := repo.List()
+		if err != nil {
+			return err
+		}
+
+		todoList, err = filterByTags(cmd, todoList)
+		if err != nil {
+			return err
+		}
+
+		schedule, err := scheduler.GenerateSchedule(ctx.Clock().Now(), calendars, todoList, options)
+		if err != nil {
+			return err
+		}
+
+		statusView := views.StatusView{}
+		if cmd.Flags().Lookup("format") != nil {
+			statusView.Format, err = cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+		}
+
+		if cmd.Flags().Lookup("max-width") != nil {
+			statusView.MaxWidth, err = cmd.Flags().GetInt("max-width")
+			if err != nil {
+				return err
+			}
+
+			if statusView.MaxWidth < 0 {
+				return fmt.Errorf("invalid --max-width %d, it can't be negative", statusView.MaxWidth)
+			}
+		}
+
+		statusView.SetData(schedule)
+
+		return viewEngine.Draw(&statusView)
+	},
+}
+
+// openCachedCalendars is openAllCalendars, but with the cached copy of each
+// calendar however old it is, so that it never waits on the network.
+// Calendars which haven't been cached yet are left out.
+func openCachedCalendars(calService calendar.CalendarServiceInterface, options scheduler.Options) ([]*ical.Calendar, scheduler.Options, error) {
+	allCalendarRecords, err := calService.GetAllCalendars()
+	if err != nil {
+		return nil, options, err
+	}
+
+	calendars := []*ical.Calendar{}
+	options.CalendarBuffers = map[*ical.Calendar]scheduler.Buffers{}
+	for _, record := range allCalendarRecords {
+		cal, err := calService.OpenCachedCalendar(record.URL)
+		if err != nil {
+			var cacheMiss *calendar.ErrCacheMiss
+			if errors.As(err, &cacheMiss) {
+				continue
+			}
+			return nil, options, err
+		}
+
+		calendars = append(calendars, cal)
+		options.CalendarBuffers[cal] = calendarBuffers(record, options.Buffers)
+	}
+
+	return calendars, options, nil
+}
+
+func init() {
+	StatusCmd.Flags().String("format", views.DefaultStatusFormat, statusFormatHelp)
+	StatusCmd.Flags().Int("max-width", 0, statusMaxWidthHelp)
+	StatusCmd.Flags().StringSlice("tag", []string{}, rootTagHelp)
+	StatusCmd.Flags().StringSlice("exclude-tag", []string{}, rootExcludeTagHelp)
+}
+
+var statusFormatHelp = `Optional. The line to show, in which placeholders are replaced with parts of your schedule:
+* {current}: the meeting happening now
+* {next}: the next meeting
+* {at}: the time the next meeting starts, such as 14:30
+* {until}: the time until the next meeting, such as 1h5m
+* {free}: the free time you have for tasks
+* {left}: the time left in your working day
+* {tasks}: how many tasks fit in your free time, such as "3 tasks fit"
+* {count}: the number of tasks which fit in your free time, such as 3
+* {task}: the best task to do next
+
+Alternatives can be given separated by ||. The first whose placeholders all have a value is shown, and the last is shown when none of them do.
+`
+
+var statusMaxWidthHelp = `Optional. The number of columns to cut the line to, for status bars with limited space. The line isn't cut when it's 0.`
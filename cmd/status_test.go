@@ -0,0 +1,92 @@
+package cmd_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	. "github.com/AP-Hunt/what-next/m/calendar/fakes"
+	"github.com/AP-Hunt/what-next/m/clock"
+	"github.com/AP-Hunt/what-next/m/cmd"
+	commandContext "github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/AP-Hunt/what-next/m/todo/fakes"
+	"github.com/AP-Hunt/what-next/m/views"
+	. "github.com/AP-Hunt/what-next/m/views/fakes"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		viewEngine      *FakeViewEngineInterface
+		calendarService *FakeCalendarServiceInterface
+		todoRepo        *FakeTodoRepositoryInterface
+		cmdContext      commandContext.CommandContext
+		now             time.Time
+	)
+
+	BeforeEach(func() {
+		viewEngine = &FakeViewEngineInterface{}
+		calendarService = &FakeCalendarServiceInterface{}
+		todoRepo = &FakeTodoRepositoryInterface{}
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+		cmdContext = commandContext.NewCommandContext(context.Background()).
+			WithCalendarService(calendarService).
+			WithTodoRepository(todoRepo).
+			WithViewEngine(viewEngine).
+			WithClock(clock.NewFixedClock(now)).
+			WithSchedulerOptions(scheduler.Options{})
+
+		todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{}), nil)
+	})
+
+	It("generates the schedule from the cached calendars only, and renders a Status view", func() {
+		cal := ical.NewCalendar()
+		evt := cal.AddEvent("standup")
+		evt.SetStartAt(now.Add(30 * time.Minute))
+		evt.SetEndAt(now.Add(45 * time.Minute))
+
+		calendarService.GetAllCalendarsReturns([]calendar.CalendarRecord{
+			{Id: 1, DisplayName: "work", URL: "file://work.ical"},
+			{Id: 2, DisplayName: "home", URL: "file://home.ical"},
+		}, nil)
+		calendarService.OpenCachedCalendarReturnsOnCall(0, cal, nil)
+		calendarService.OpenCachedCalendarReturnsOnCall(1, nil, &calendar.ErrCacheMiss{Key: "file://home.ical", Reason: "not found"})
+
+		PrepareCommandForTest(cmd.StatusCmd, []string{})
+		cmd.StatusCmd.Flags().String("format", "", "")
+		cmd.StatusCmd.Flags().Int("max-width", 0, "")
+
+		err := cmd.StatusCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(calendarService.OpenCalendarCallCount()).To(Equal(0))
+		Expect(calendarService.OpenCachedCalendarCallCount()).To(Equal(2))
+
+		Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		drawnView := viewEngine.DrawArgsForCall(0)
+		Expect(drawnView).To(BeAssignableToTypeOf(&views.StatusView{}))
+
+		schedule := drawnView.Data().(*scheduler.Schedule)
+		Expect(schedule.NextCalendarEvents).To(ConsistOf(evt))
+	})
+
+	It("passes the format and maximum width to the view", func() {
+		calendarService.GetAllCalendarsReturns([]calendar.CalendarRecord{}, nil)
+
+		PrepareCommandForTest(cmd.StatusCmd, []string{"--format", "{tasks}", "--max-width", "20"})
+		cmd.StatusCmd.Flags().String("format", "", "")
+		cmd.StatusCmd.Flags().Int("max-width", 0, "")
+
+		err := cmd.StatusCmd.ExecuteContext(cmdContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		drawnView := viewEngine.DrawArgsForCall(0).(*views.StatusView)
+		Expect(drawnView.Format).To(Equal("{tasks}"))
+		Expect(drawnView.MaxWidth).To(Equal(20))
+	})
+})
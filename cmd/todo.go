@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/hako/durafmt"
 	"github.com/spf13/cobra"
 
 	"github.com/AP-Hunt/what-next/m/context"
@@ -21,51 +20,81 @@ var TodoRootCmd = &cobra.Command{
 }
 
 var TodoAddCmd = &cobra.Command{
-	Use:                   "add action [--due due] [--duration duration]",
+	Use:                   "add action [--due due] [--duration duration] [--priority priority] [--tag tag...] [--every recurrence] [--parent id] [--note note...] [--link url...]",
 	DisableFlagsInUseLine: true,
 	Aliases:               []string{"a"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
-		var dueDate *time.Time = nil
+		dueDate, err := parseDueDateFlag(cmd, "due", ctx.Clock().Now())
+		if err != nil {
+			return err
+		}
+
+		duration, err := parseDurationFlag(cmd, "duration")
+		if err != nil {
+			return err
+		}
+
+		priority := todo.DefaultPriority
 
-		if cmd.Flags().Lookup("due") != nil {
-			dueDateInput, err := cmd.Flags().GetString("due")
+		givenPriority, err := parsePriorityFlag(cmd, "priority")
+		if err != nil {
+			return err
+		}
+		if givenPriority != nil {
+			priority = *givenPriority
+		}
+
+		tags := []string{}
+
+		if cmd.Flags().Lookup("tag") != nil {
+			tagsInput, err := cmd.Flags().GetStringSlice("tag")
 			if err != nil {
 				return err
 			}
-
-			if dueDateInput != "" {
-				parsedDate, err := todo.ParseDueDate(dueDateInput)
-				if err != nil {
-					return err
-				}
-				dueDate = &parsedDate
-			}
+			tags = todo.NormalizeTags(tagsInput)
 		}
 
-		var duration *time.Duration = nil
+		recurrence, err := parseRecurrenceFlag(cmd, "every")
+		if err != nil {
+			return err
+		}
 
-		if cmd.Flags().Lookup("duration") != nil {
-			durationInput, err := cmd.Flags().GetString("duration")
+		// Recurring items need a due date to find the next one from
+		if recurrence != nil && dueDate == nil {
+			firstDueDate, err := todo.FirstDueDate(*recurrence, ctx.Clock().Now())
 			if err != nil {
 				return err
 			}
-			if durationInput != "" {
-				parsedDuration, err := durafmt.ParseString(durationInput)
-				if err != nil {
-					return err
-				}
-				pd := parsedDuration.Duration()
-				duration = &pd
-			}
+			dueDate = &firstDueDate
+		}
+
+		parentId, err := parseParentFlag(cmd, "parent", ctx.TodoRepository())
+		if err != nil {
+			return err
+		}
+
+		links, err := parseLinksFlag(cmd, "link")
+		if err != nil {
+			return err
 		}
 
 		itemAction := strings.Join(args, " ")
 		item := todo.TodoItem{
-			Action:    itemAction,
-			Completed: false,
-			DueDate:   dueDate,
-			Duration:  duration,
+			Action:     itemAction,
+			Completed:  false,
+			DueDate:    dueDate,
+			Duration:   duration,
+			Priority:   priority,
+			Recurrence: recurrence,
+			ParentId:   parentId,
+			Tags:       tags,
+			Links:      links,
+		}
+
+		err = addNotesFromFlag(cmd, "note", &item)
+		if err != nil {
+			return err
 		}
 
 		repo := ctx.TodoRepository()
@@ -76,7 +105,7 @@ var TodoAddCmd = &cobra.Command{
 		}
 
 		viewEngine := ctx.ViewEngine()
-		view := views.TodoListView{}
+		view := views.TodoListView{Now: ctx.Clock().Now()}
 		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{&addedItem}))
 
 		return viewEngine.Draw(&view)
@@ -84,28 +113,53 @@ var TodoAddCmd = &cobra.Command{
 }
 
 var TodoListCmd = &cobra.Command{
-	Use:     "list",
-	Aliases: []string{"l"},
+	Use:                   "list [--tag tag...] [--exclude-tag tag...] [--archived] [--all] [--completed-since date] [--overdue] [--due-before date] [--due-after date] [--no-due] [--max-duration duration] [--sort due|duration|created|priority [--desc]]",
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"l"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
 		repo := ctx.TodoRepository()
 
-		items, err := repo.List()
+		var err error
+
+		archived := false
+		if cmd.Flags().Lookup("archived") != nil {
+			archived, err = cmd.Flags().GetBool("archived")
+			if err != nil {
+				return err
+			}
+		}
+
+		var items *todo.TodoItemCollection
+		if archived {
+			items, err = repo.ListArchived()
+			if err != nil {
+				return err
+			}
+		} else {
+			items, err = repo.List()
+			if err != nil {
+				return err
+			}
+		}
+
+		items, err = filterTodoList(cmd, items, ctx.Clock().Now(), archived)
 		if err != nil {
 			return err
 		}
 
-		time24Ago := time.Now().Add(-24 * time.Hour)
-		items = items.Filter(func(item *todo.TodoItem) bool {
-			if !item.Completed {
-				return true
-			}
+		items, err = filterByTags(cmd, items)
+		if err != nil {
+			return err
+		}
 
-			return item.CompletedAt.After(time24Ago)
-		})
+		items, err = sortTodoList(cmd, items)
+		if err != nil {
+			return err
+		}
 
 		viewEngine := ctx.ViewEngine()
-		view := views.TodoListView{}
+		view := views.TodoListView{Now: ctx.Clock().Now()}
 		view.SetData(items)
 
 		return viewEngine.Draw(&view)
@@ -120,55 +174,1140 @@ var TodoCompleteCmd = &cobra.Command{
 		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
 		repo := ctx.TodoRepository()
 
-		idStr := args[0]
-		idInt, err := strconv.ParseInt(idStr, 10, 32)
+		id, err := parseTodoId(args[0])
+		if err != nil {
+			return err
+		}
+
+		item, err := getTodoItem(repo, id)
+		if err != nil {
+			return err
+		}
+
+		if item.Completed {
+			return drawMessage(ctx, fmt.Sprintf("Todo item %d is already complete%s.", id, completedAtDescription(item)))
+		}
+
+		changedItems, err := completeTodoItem(repo, item, ctx.Clock().Now())
+		if err != nil {
+			return err
+		}
+
+		items := todo.NewTodoItemCollection(changedItems)
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(items)
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+var TodoReopenCmd = &cobra.Command{
+	Use:     "reopen id",
+	Aliases: []string{"o"},
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, err := parseTodoId(args[0])
 		if err != nil {
-			return fmt.Errorf("id must be an integer")
+			return err
 		}
 
-		item, err := repo.Get(int(idInt))
+		item, err := getTodoItem(repo, id)
+		if err != nil {
+			return err
+		}
 
+		if !item.Completed {
+			return drawMessage(ctx, fmt.Sprintf("Todo item %d isn't complete.", id))
+		}
+
+		changedItems, err := reopenTodoItem(repo, item)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				return fmt.Errorf("no todo item with id %d", int(idInt))
+			return err
+		}
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(todo.NewTodoItemCollection(changedItems))
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+// completeTodoItem completes the item, adds its next instance when it
+// recurs, and completes any parents which are finished because of it.
+// It returns the items it changed and added.
+func completeTodoItem(repo todo.TodoRepositoryInterface, item todo.TodoItem, now time.Time) ([]*todo.TodoItem, error) {
+	allItems, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if allItems.HasIncompleteSubtasks(item.Id) {
+		return nil, fmt.Errorf("todo item %d has subtasks which aren't complete, it will be completed when they are", item.Id)
+	}
+
+	item.Complete(now)
+	updated, err := repo.Update(item)
+	if err != nil {
+		return nil, err
+	}
+
+	changedItems := []*todo.TodoItem{&updated}
+
+	next, recurs, err := updated.NextInstance(now)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurs {
+		addedItem, err := repo.Add(next)
+		if err != nil {
+			return nil, err
+		}
+
+		changedItems = append(changedItems, &addedItem)
+	}
+
+	completedParents, err := completeFinishedParents(repo, updated, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(changedItems, completedParents...), nil
+}
+
+// reopenTodoItem reopens the item, and the parents which are no longer
+// complete because of it. The next instance which was added when a
+// recurring item was completed is removed, so completing it again doesn't
+// add another. It returns the items it changed.
+func reopenTodoItem(repo todo.TodoRepositoryInterface, item todo.TodoItem) ([]*todo.TodoItem, error) {
+	if item.IsRecurring() {
+		allItems, err := repo.List()
+		if err != nil {
+			return nil, err
+		}
+
+		next, err := allItems.NextInstanceOf(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if next != nil {
+			if err := repo.Delete(next.Id); err != nil {
+				return nil, err
 			}
+		}
+	}
+
+	item.Reopen()
+	updated, err := repo.Update(item)
+	if err != nil {
+		return nil, err
+	}
+
+	reopenedParents, err := reopenCompletedParents(repo, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*todo.TodoItem{&updated}, reopenedParents...), nil
+}
+
+// completeFinishedParents completes the parent of the item when all of
+// its subtasks are complete, and so on up the tree. It returns the
+// parents it completed.
+func completeFinishedParents(repo todo.TodoRepositoryInterface, item todo.TodoItem, now time.Time) ([]*todo.TodoItem, error) {
+	completed := []*todo.TodoItem{}
+
+	for item.ParentId != nil {
+		allItems, err := repo.List()
+		if err != nil {
+			return nil, err
+		}
+
+		if allItems.HasIncompleteSubtasks(*item.ParentId) {
+			break
+		}
+
+		parent, err := getTodoItem(repo, *item.ParentId)
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.Completed {
+			break
+		}
 
+		parent.Complete(now)
+		item, err = repo.Update(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		completedParent := item
+		completed = append(completed, &completedParent)
+	}
+
+	return completed, nil
+}
+
+// reopenCompletedParents reopens the parent of the item when it is
+// complete, and so on up the tree, because a parent is only complete
+// when all of its subtasks are. It returns the parents it reopened.
+func reopenCompletedParents(repo todo.TodoRepositoryInterface, item todo.TodoItem) ([]*todo.TodoItem, error) {
+	reopened := []*todo.TodoItem{}
+
+	for item.ParentId != nil {
+		parent, err := getTodoItem(repo, *item.ParentId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !parent.Completed {
+			break
+		}
+
+		parent.Reopen()
+		item, err = repo.Update(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		reopenedParent := item
+		reopened = append(reopened, &reopenedParent)
+	}
+
+	return reopened, nil
+}
+
+func completedAtDescription(item todo.TodoItem) string {
+	if item.CompletedAt == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(", it was completed at %s", item.CompletedAt.Local().Format("15:04 on Monday January _2"))
+}
+
+var TodoShowCmd = &cobra.Command{
+	Use:     "show id",
+	Aliases: []string{"s"},
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, err := parseTodoId(args[0])
+		if err != nil {
+			return err
+		}
+
+		item, err := getTodoItem(repo, id)
+		if err != nil {
+			return err
+		}
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoItemView{Now: ctx.Clock().Now()}
+		view.SetData(&item)
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+var TodoRemoveCmd = &cobra.Command{
+	Use:     "remove id...",
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		ids, err := parseTodoIds(args)
+		if err != nil {
 			return err
 		}
 
-		item.Complete()
+		// Check every item exists first, so that none are removed when an id is wrong
+		_, err = getTodoItems(repo, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			err = repo.Delete(id)
+			if err != nil {
+				return err
+			}
+		}
+
+		view := views.RemovedTodoItemsView{}
+		view.SetData(ids)
+
+		return ctx.ViewEngine().Draw(&view)
+	},
+}
+
+var TodoArchiveCmd = &cobra.Command{
+	Use:  "archive id...",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setArchived(cmd, args, true)
+	},
+}
+
+var TodoUnarchiveCmd = &cobra.Command{
+	Use:  "unarchive id...",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setArchived(cmd, args, false)
+	},
+}
+
+// setArchived archives or unarchives each of the items with the ids in args
+func setArchived(cmd *cobra.Command, args []string, archived bool) error {
+	var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+	repo := ctx.TodoRepository()
+
+	ids, err := parseTodoIds(args)
+	if err != nil {
+		return err
+	}
+
+	items, err := getTodoItems(repo, ids)
+	if err != nil {
+		return err
+	}
+
+	updatedItems := []*todo.TodoItem{}
+	for _, item := range items {
+		if archived {
+			item.Archive(ctx.Clock().Now())
+		} else {
+			item.Unarchive()
+		}
+
 		updated, err := repo.Update(item)
 		if err != nil {
 			return err
 		}
+		updatedItems = append(updatedItems, &updated)
+	}
+
+	viewEngine := ctx.ViewEngine()
+	view := views.TodoListView{Now: ctx.Clock().Now()}
+	view.SetData(todo.NewTodoItemCollection(updatedItems))
+
+	return viewEngine.Draw(&view)
+}
 
-		items := todo.NewTodoItemCollection([]*todo.TodoItem{&updated})
+var TodoTagCmd = &cobra.Command{
+	Use:  "tag id tag...",
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, err := parseTodoId(args[0])
+		if err != nil {
+			return err
+		}
+
+		_, err = getTodoItem(repo, id)
+		if err != nil {
+			return err
+		}
+
+		tagged, err := repo.Tag(id, args[1:])
+		if err != nil {
+			return err
+		}
 
 		viewEngine := ctx.ViewEngine()
-		view := views.TodoListView{}
-		view.SetData(items)
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{&tagged}))
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+var TodoUntagCmd = &cobra.Command{
+	Use:  "untag id tag...",
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, err := parseTodoId(args[0])
+		if err != nil {
+			return err
+		}
+
+		_, err = getTodoItem(repo, id)
+		if err != nil {
+			return err
+		}
+
+		untagged, err := repo.Untag(id, args[1:])
+		if err != nil {
+			return err
+		}
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{&untagged}))
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+var TodoBlockCmd = &cobra.Command{
+	Use:                   "block id --on id...",
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, blockedBy, err := parseDependencyArgs(cmd, args, repo)
+		if err != nil {
+			return err
+		}
+
+		allItems, err := repo.List()
+		if err != nil {
+			return err
+		}
+
+		for _, blockedById := range blockedBy {
+			if blockedById == id {
+				return fmt.Errorf("todo item %d can't be blocked by itself", id)
+			}
+
+			if allItems.IsWaitingOn(blockedById, id) {
+				return fmt.Errorf("todo item %d can't be blocked by %d, because %d is already waiting on %d", id, blockedById, blockedById, id)
+			}
+		}
+
+		blocked, err := repo.Block(id, blockedBy)
+		if err != nil {
+			return err
+		}
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{&blocked}))
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+var TodoUnblockCmd = &cobra.Command{
+	Use:                   "unblock id --on id...",
+	DisableFlagsInUseLine: true,
+	Args:                  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, blockedBy, err := parseDependencyArgs(cmd, args, repo)
+		if err != nil {
+			return err
+		}
+
+		unblocked, err := repo.Unblock(id, blockedBy)
+		if err != nil {
+			return err
+		}
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{&unblocked}))
+
+		return viewEngine.Draw(&view)
+	},
+}
+
+// parseDependencyArgs parses the id of the item being blocked or unblocked
+// from args, and the ids of the items it is blocked by from --on. Each of
+// the items must exist.
+func parseDependencyArgs(cmd *cobra.Command, args []string, repo todo.TodoRepositoryInterface) (int, []int, error) {
+	id, err := parseTodoId(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	onInput := []string{}
+	if cmd.Flags().Lookup("on") != nil {
+		onInput, err = cmd.Flags().GetStringSlice("on")
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if len(onInput) == 0 {
+		return 0, nil, fmt.Errorf("--on must be given the id of at least one other item")
+	}
+
+	blockedBy, err := parseTodoIds(onInput)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	_, err = getTodoItems(repo, append([]int{id}, blockedBy...))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, blockedBy, nil
+}
+
+var TodoEditCmd = &cobra.Command{
+	Use:                   "edit id [--action action] [--due due] [--duration duration] [--priority priority] [--every recurrence] [--note note...] [--link url...] [--remove-link url...] [--clear-due] [--clear-duration] [--clear-every] [--clear-notes]",
+	DisableFlagsInUseLine: true,
+	Aliases:               []string{"e"},
+	Args:                  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+		repo := ctx.TodoRepository()
+
+		id, err := parseTodoId(args[0])
+		if err != nil {
+			return err
+		}
+
+		item, err := getTodoItem(repo, id)
+		if err != nil {
+			return err
+		}
+
+		var edited todo.TodoItem
+		if anyFlagChanged(cmd, todoEditFlags) {
+			edited, err = editTodoItemWithFlags(cmd, item, ctx.Clock().Now())
+		} else {
+			edited, err = editTodoItemWithEditor(ctx, item)
+		}
+		if err != nil {
+			return err
+		}
+
+		updated, err := repo.Update(edited)
+		if err != nil {
+			return err
+		}
+
+		viewEngine := ctx.ViewEngine()
+		view := views.TodoListView{Now: ctx.Clock().Now()}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{&updated}))
 
 		return viewEngine.Draw(&view)
 	},
 }
 
+var todoEditFlags = []string{
+	"action", "due", "duration", "priority", "every", "note", "link", "remove-link",
+	"clear-due", "clear-duration", "clear-every", "clear-notes",
+}
+
+func editTodoItemWithFlags(cmd *cobra.Command, item todo.TodoItem, now time.Time) (todo.TodoItem, error) {
+	if flagChanged(cmd, "action") {
+		action, err := cmd.Flags().GetString("action")
+		if err != nil {
+			return todo.TodoItem{}, err
+		}
+
+		action = strings.TrimSpace(action)
+		if action == "" {
+			return todo.TodoItem{}, fmt.Errorf("action cannot be empty")
+		}
+		item.Action = action
+	}
+
+	if flagChanged(cmd, "due") && flagChanged(cmd, "clear-due") {
+		return todo.TodoItem{}, fmt.Errorf("--due and --clear-due cannot be used together")
+	}
+
+	if flagChanged(cmd, "duration") && flagChanged(cmd, "clear-duration") {
+		return todo.TodoItem{}, fmt.Errorf("--duration and --clear-duration cannot be used together")
+	}
+
+	if flagChanged(cmd, "every") && flagChanged(cmd, "clear-every") {
+		return todo.TodoItem{}, fmt.Errorf("--every and --clear-every cannot be used together")
+	}
+
+	dueDate, err := parseDueDateFlag(cmd, "due", now)
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+	if dueDate != nil {
+		item.DueDate = dueDate
+	}
+
+	duration, err := parseDurationFlag(cmd, "duration")
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+	if duration != nil {
+		item.Duration = duration
+	}
+
+	priority, err := parsePriorityFlag(cmd, "priority")
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+	if priority != nil {
+		item.Priority = *priority
+	}
+
+	recurrence, err := parseRecurrenceFlag(cmd, "every")
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+	if recurrence != nil {
+		item.Recurrence = recurrence
+	}
+
+	if flagChanged(cmd, "clear-due") {
+		item.DueDate = nil
+	}
+
+	if flagChanged(cmd, "clear-duration") {
+		item.Duration = nil
+	}
+
+	if flagChanged(cmd, "clear-every") {
+		item.Recurrence = nil
+	}
+
+	// Recurring items need a due date to find the next one from
+	if item.Recurrence != nil && item.DueDate == nil {
+		firstDueDate, err := todo.FirstDueDate(*item.Recurrence, now)
+		if err != nil {
+			return todo.TodoItem{}, err
+		}
+		item.DueDate = &firstDueDate
+	}
+
+	if flagChanged(cmd, "clear-notes") {
+		item.Notes = ""
+	}
+
+	err = addNotesFromFlag(cmd, "note", &item)
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+
+	links, err := parseLinksFlag(cmd, "link")
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+	item.AddLinks(links)
+
+	removedLinks, err := parseLinksFlag(cmd, "remove-link")
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+	item.RemoveLinks(removedLinks)
+
+	return item, nil
+}
+
+func editTodoItemWithEditor(ctx context.CommandContext, item todo.TodoItem) (todo.TodoItem, error) {
+	form, err := ctx.Editor().Edit(todo.FormatEditForm(item))
+	if err != nil {
+		return todo.TodoItem{}, err
+	}
+
+	return todo.ParseEditForm(form, item, ctx.Clock().Now())
+}
+
+// flagChanged returns true when the named flag was given
+func flagChanged(cmd *cobra.Command, flag string) bool {
+	return cmd.Flags().Lookup(flag) != nil && cmd.Flags().Changed(flag)
+}
+
+// getBoolFlag returns the value of the named boolean
+// flag, and false when the command doesn't have it
+func getBoolFlag(cmd *cobra.Command, flag string) (bool, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return false, nil
+	}
+
+	return cmd.Flags().GetBool(flag)
+}
+
+// anyFlagChanged returns true when any of the named flags were given
+func anyFlagChanged(cmd *cobra.Command, flags []string) bool {
+	for _, flag := range flags {
+		if flagChanged(cmd, flag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseDueDateFlag parses the due date given to the named
+// flag, and returns nil when the flag isn't given
+func parseDueDateFlag(cmd *cobra.Command, flag string, now time.Time) (*time.Time, error) {
+	return parseDateFlag(cmd, flag, now, todo.ParseDueDate)
+}
+
+// parseDateFlag parses the date given to the named flag with
+// the parser, and returns nil when the flag isn't given
+func parseDateFlag(cmd *cobra.Command, flag string, now time.Time, parse func(string, time.Time) (time.Time, error)) (*time.Time, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return nil, nil
+	}
+
+	dueDateInput, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if dueDateInput == "" {
+		return nil, nil
+	}
+
+	dueDate, err := parse(dueDateInput, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dueDate, nil
+}
+
+// parseDurationFlag parses the duration given to the named
+// flag, and returns nil when the flag isn't given
+func parseDurationFlag(cmd *cobra.Command, flag string) (*time.Duration, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return nil, nil
+	}
+
+	durationInput, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if durationInput == "" {
+		return nil, nil
+	}
+
+	duration, err := todo.ParseDuration(durationInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &duration, nil
+}
+
+// parsePriorityFlag parses the priority given to the named
+// flag, and returns nil when the flag isn't given
+func parsePriorityFlag(cmd *cobra.Command, flag string) (*todo.Priority, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return nil, nil
+	}
+
+	priorityInput, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if priorityInput == "" {
+		return nil, nil
+	}
+
+	priority, err := todo.ParsePriority(priorityInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &priority, nil
+}
+
+// parseRecurrenceFlag parses the recurrence given to the named
+// flag, and returns nil when the flag isn't given
+func parseRecurrenceFlag(cmd *cobra.Command, flag string) (*string, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return nil, nil
+	}
+
+	recurrenceInput, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurrenceInput == "" {
+		return nil, nil
+	}
+
+	recurrence, err := todo.ParseRecurrence(recurrenceInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recurrence, nil
+}
+
+// parseLinksFlag parses the links given to the named
+// flag, and returns none when the flag isn't given
+func parseLinksFlag(cmd *cobra.Command, flag string) ([]string, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return []string{}, nil
+	}
+
+	linksInput, err := cmd.Flags().GetStringArray(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	return todo.ParseLinks(linksInput)
+}
+
+// addNotesFromFlag adds each note given to the named flag to the item's notes
+func addNotesFromFlag(cmd *cobra.Command, flag string, item *todo.TodoItem) error {
+	if cmd.Flags().Lookup(flag) == nil {
+		return nil
+	}
+
+	notes, err := cmd.Flags().GetStringArray(flag)
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		item.AddNote(note)
+	}
+
+	return nil
+}
+
+// parseParentFlag parses the id of the parent given to the named flag, and
+// returns nil when the flag isn't given. The parent item must exist.
+func parseParentFlag(cmd *cobra.Command, flag string, repo todo.TodoRepositoryInterface) (*int, error) {
+	if cmd.Flags().Lookup(flag) == nil {
+		return nil, nil
+	}
+
+	parentInput, err := cmd.Flags().GetString(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentInput == "" {
+		return nil, nil
+	}
+
+	parentId, err := parseTodoId(parentInput)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = getTodoItem(repo, parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parentId, nil
+}
+
+func parseTodoId(idStr string) (int, error) {
+	idInt, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("id must be an integer")
+	}
+
+	return int(idInt), nil
+}
+
+func parseTodoIds(idStrs []string) ([]int, error) {
+	ids := []int{}
+	for _, idStr := range idStrs {
+		id, err := parseTodoId(idStr)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// getTodoItem gets the item with the id, with an
+// error for the user when there isn't one
+func getTodoItem(repo todo.TodoRepositoryInterface, id int) (todo.TodoItem, error) {
+	item, err := repo.Get(id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return todo.TodoItem{}, fmt.Errorf("no todo item with id %d", id)
+		}
+
+		return todo.TodoItem{}, err
+	}
+
+	return item, nil
+}
+
+// getTodoItems gets the items with each of the ids, and
+// returns an error if there isn't an item for any of them
+func getTodoItems(repo todo.TodoRepositoryInterface, ids []int) ([]todo.TodoItem, error) {
+	items := []todo.TodoItem{}
+	for _, id := range ids {
+		item, err := getTodoItem(repo, id)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// filterTodoList applies the filters given to the list command. Unless
+// --all, --completed-since or --archived are given, completed items are
+// only shown for 24 hours after they're completed.
+func filterTodoList(cmd *cobra.Command, items *todo.TodoItemCollection, now time.Time, archived bool) (*todo.TodoItemCollection, error) {
+	all, err := getBoolFlag(cmd, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	completedSince, err := parseDateFlag(cmd, "completed-since", now, todo.ParseSinceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedSince != nil {
+		items = items.Filter(todo.AnyOf(todo.IsIncomplete, todo.CompletedSince(*completedSince)))
+	} else if !all && !archived {
+		items = items.Filter(todo.AnyOf(todo.IsIncomplete, todo.CompletedSince(now.Add(-24*time.Hour))))
+	}
+
+	overdue, err := getBoolFlag(cmd, "overdue")
+	if err != nil {
+		return nil, err
+	}
+	if overdue {
+		items = items.Filter(todo.IsOverdueAt(now))
+	}
+
+	dueBefore, err := parseDueDateFlag(cmd, "due-before", now)
+	if err != nil {
+		return nil, err
+	}
+	if dueBefore != nil {
+		items = items.Filter(todo.DueBefore(*dueBefore))
+	}
+
+	dueAfter, err := parseDueDateFlag(cmd, "due-after", now)
+	if err != nil {
+		return nil, err
+	}
+	if dueAfter != nil {
+		items = items.Filter(todo.DueAfter(*dueAfter))
+	}
+
+	noDue, err := getBoolFlag(cmd, "no-due")
+	if err != nil {
+		return nil, err
+	}
+	if noDue {
+		items = items.Filter(todo.HasNoDueDate)
+	}
+
+	maxDuration, err := parseDurationFlag(cmd, "max-duration")
+	if err != nil {
+		return nil, err
+	}
+	if maxDuration != nil {
+		items = items.Filter(todo.HasDurationAtMost(*maxDuration))
+	}
+
+	return items, nil
+}
+
+// sortTodoList sorts the items in the order given by --sort and --desc,
+// and leaves them in the order they're in when neither are given
+func sortTodoList(cmd *cobra.Command, items *todo.TodoItemCollection) (*todo.TodoItemCollection, error) {
+	sortInput := ""
+	if cmd.Flags().Lookup("sort") != nil {
+		var err error
+		sortInput, err = cmd.Flags().GetString("sort")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	desc, err := getBoolFlag(cmd, "desc")
+	if err != nil {
+		return nil, err
+	}
+
+	if sortInput == "" && !desc {
+		return items, nil
+	}
+
+	// --desc on its own sorts the newest items first
+	if sortInput == "" {
+		sortInput = "created"
+	}
+
+	less, err := todo.ParseSortOrder(sortInput, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	return items.SortStable(less), nil
+}
+
+// filterByTags keeps the items which have all of the tags given
+// by --tag, and none of the tags given by --exclude-tag
+func filterByTags(cmd *cobra.Command, items *todo.TodoItemCollection) (*todo.TodoItemCollection, error) {
+	if cmd.Flags().Lookup("tag") != nil {
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err != nil {
+			return nil, err
+		}
+		items = items.Filter(todo.HasAllTags(tags))
+	}
+
+	if cmd.Flags().Lookup("exclude-tag") != nil {
+		excludedTags, err := cmd.Flags().GetStringSlice("exclude-tag")
+		if err != nil {
+			return nil, err
+		}
+		items = items.Filter(todo.HasNoneOfTags(excludedTags))
+	}
+
+	return items, nil
+}
+
 func init() {
 	TodoAddCmd.Flags().String("due", "", todoAddDueDateHelp)
 	TodoAddCmd.Flags().String("duration", "", todoAddDurationHelp)
+	TodoAddCmd.Flags().String("priority", "", todoAddPriorityHelp)
+	TodoAddCmd.Flags().StringSlice("tag", []string{}, todoAddTagHelp)
+	TodoAddCmd.Flags().String("every", "", todoAddEveryHelp)
+	TodoAddCmd.Flags().String("parent", "", todoAddParentHelp)
+	TodoAddCmd.Flags().StringArray("note", []string{}, todoAddNoteHelp)
+	TodoAddCmd.Flags().StringArray("link", []string{}, todoAddLinkHelp)
+	TodoListCmd.Flags().StringSlice("tag", []string{}, todoFilterTagHelp)
+	TodoListCmd.Flags().StringSlice("exclude-tag", []string{}, todoFilterExcludeTagHelp)
+	TodoListCmd.Flags().Bool("all", false, todoListAllHelp)
+	TodoListCmd.Flags().String("completed-since", "", todoListCompletedSinceHelp)
+	TodoListCmd.Flags().Bool("overdue", false, todoListOverdueHelp)
+	TodoListCmd.Flags().String("due-before", "", todoListDueBeforeHelp)
+	TodoListCmd.Flags().String("due-after", "", todoListDueAfterHelp)
+	TodoListCmd.Flags().Bool("no-due", false, todoListNoDueHelp)
+	TodoListCmd.Flags().String("max-duration", "", todoListMaxDurationHelp)
+	TodoListCmd.Flags().String("sort", "", todoListSortHelp)
+	TodoListCmd.Flags().Bool("desc", false, todoListDescHelp)
 	TodoRootCmd.AddCommand(TodoAddCmd)
 	TodoRootCmd.AddCommand(TodoListCmd)
 	TodoRootCmd.AddCommand(TodoCompleteCmd)
+	TodoRootCmd.AddCommand(TodoReopenCmd)
+	TodoRootCmd.AddCommand(TodoTagCmd)
+	TodoRootCmd.AddCommand(TodoUntagCmd)
+
+	TodoBlockCmd.Flags().StringSlice("on", []string{}, todoBlockOnHelp)
+	TodoUnblockCmd.Flags().StringSlice("on", []string{}, todoUnblockOnHelp)
+	TodoRootCmd.AddCommand(TodoBlockCmd)
+	TodoRootCmd.AddCommand(TodoUnblockCmd)
+
+	TodoEditCmd.Flags().String("action", "", todoEditActionHelp)
+	TodoEditCmd.Flags().String("due", "", todoAddDueDateHelp)
+	TodoEditCmd.Flags().String("duration", "", todoAddDurationHelp)
+	TodoEditCmd.Flags().String("priority", "", todoAddPriorityHelp)
+	TodoEditCmd.Flags().Bool("clear-due", false, todoEditClearDueHelp)
+	TodoEditCmd.Flags().Bool("clear-duration", false, todoEditClearDurationHelp)
+	TodoEditCmd.Flags().String("every", "", todoAddEveryHelp)
+	TodoEditCmd.Flags().Bool("clear-every", false, todoEditClearEveryHelp)
+	TodoEditCmd.Flags().StringArray("note", []string{}, todoAddNoteHelp)
+	TodoEditCmd.Flags().StringArray("link", []string{}, todoAddLinkHelp)
+	TodoEditCmd.Flags().StringArray("remove-link", []string{}, todoEditRemoveLinkHelp)
+	TodoEditCmd.Flags().Bool("clear-notes", false, todoEditClearNotesHelp)
+	TodoRootCmd.AddCommand(TodoEditCmd)
+
+	TodoListCmd.Flags().Bool("archived", false, todoListArchivedHelp)
+	TodoRootCmd.AddCommand(TodoShowCmd)
+	TodoRootCmd.AddCommand(TodoRemoveCmd)
+	TodoRootCmd.AddCommand(TodoArchiveCmd)
+	TodoRootCmd.AddCommand(TodoUnarchiveCmd)
 }
 
-var todoAddDueDateHelp = `Optional. Date and time at which the new item is due.
+var todoAddDueDateHelp = "Optional. Date and time at which the new item is due.\n\n" + todo.DueDateHelp()
 
-Due dates can be specified as any valid datetime string, and are assumed to be local time.
-For due dates of today or tomorrow, the following shorthand strings can be used:
-* @today
-* @tod
-* @tomorrow
-* @tom
-* @tmrw
+var todoAddDurationHelp = `Optional. Duration you expect this item to take. Durations can be provided in a human readable form, e.g. '30m' or '1h10m'.`
+
+var todoAddPriorityHelp = `Optional. How important this item is, from 1 (highest) to 5 (lowest). Defaults to 3.
+
+Priorities can also be given as p1 to p5, or by name: highest, high, medium, low or lowest.
 `
 
-var todoAddDurationHelp = `Optional. Duration you expect this item to take. Durations can be provided in a human readable form, e.g. '30m' or '1h10m'.`
+var todoAddTagHelp = `Optional. Tag for the new item, such as +release or @laptop. Can be given more than once, or as a comma separated list.`
+
+var todoAddEveryHelp = `Optional. How often the item recurs. When a recurring item is completed, its next instance is added, due at the next time the recurrence comes around.
+
+Recurrences can be given as an RRULE, such as 'FREQ=WEEKLY;BYDAY=FR', or as one of:
+* day, weekday, week, month or year
+* the name of a day, such as friday, or a comma separated list of them
+* an interval, such as '2 weeks'
+
+Recurring items without a due date are due at the end of the first day the recurrence picks out.
+`
+
+var todoAddParentHelp = `Optional. Id of the item the new item is a subtask of.
+
+Large items can be broken down in to subtasks which fit between meetings. Items with subtasks are completed when all of their subtasks are, and only their subtasks are suggested.`
+
+var todoAddNoteHelp = `Optional. A note to add to the item, such as the context it needs. Can be given more than once, and each note is added on a line of its own. Notes are shown by 'todo show'.`
+
+var todoAddLinkHelp = `Optional. A URL the item relates to, such as a pull request or ticket. Can be given more than once. Links are shown by 'todo show'.`
+
+var todoFilterTagHelp = `Optional. Only show items with this tag. Can be given more than once, or as a comma separated list, to only show items with all of the tags.`
+
+var todoFilterExcludeTagHelp = `Optional. Don't show items with this tag. Can be given more than once, or as a comma separated list.`
+
+var todoEditActionHelp = `Optional. New action for the item.
+
+When no flags are given, the item is opened in the editor named by $VISUAL or $EDITOR instead.`
+
+var todoEditClearDueHelp = `Optional. Remove the item's due date. Recurring items are given the first due date of their recurrence instead.`
+
+var todoEditClearDurationHelp = `Optional. Remove the item's duration.`
+
+var todoEditClearEveryHelp = `Optional. Stop the item from recurring.`
+
+var todoBlockOnHelp = `Id of the item which must be completed before this one can be started. Can be given more than once, or as a comma separated list.
+
+Blocked items aren't suggested until the items they are blocked by are complete.`
+
+var todoUnblockOnHelp = `Id of the item this one should no longer wait on. Can be given more than once, or as a comma separated list.`
+
+var todoEditRemoveLinkHelp = `Optional. A URL to remove from the item's links. Can be given more than once.`
+
+var todoEditClearNotesHelp = `Optional. Remove the item's notes. Any notes given with --note are added afterwards.`
+
+var todoListArchivedHelp = `Optional. Show the archived items, instead of the others. Archived items can be brought back with 'todo unarchive'.`
+
+var todoListAllHelp = `Optional. Show every completed item, instead of only those completed in the last 24 hours.`
+
+var todoListCompletedSinceHelp = `Optional. Show the items completed since this date, instead of only those completed in the last 24 hours. Accepts the same dates as --due, or yesterday. Days begin at midnight, and are the last time that day came around, so "monday" shows the items completed since the start of the most recent Monday.`
+
+var todoListOverdueHelp = `Optional. Only show the items which aren't complete and are past their due dates.`
+
+var todoListDueBeforeHelp = `Optional. Only show the items due before this date. Accepts the same dates as --due.`
+
+var todoListDueAfterHelp = `Optional. Only show the items due after this date. Accepts the same dates as --due.`
+
+var todoListNoDueHelp = `Optional. Only show the items without a due date.`
+
+var todoListMaxDurationHelp = `Optional. Only show the items which take no longer than this, such as 30m. Items without a duration aren't shown.`
+
+var todoListSortHelp = `Optional. Sort the items by due, duration, created or priority. Items without a due date or duration come last, even with --desc. Subtasks are sorted amongst the other subtasks of their parent.`
+
+var todoListDescHelp = `Optional. Sort the items in descending order, instead of ascending.`
@@ -2,32 +2,40 @@ package cmd_test
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
+	"github.com/AP-Hunt/what-next/m/clock"
 	"github.com/AP-Hunt/what-next/m/cmd"
 	commandContext "github.com/AP-Hunt/what-next/m/context"
+	editorFakes "github.com/AP-Hunt/what-next/m/editor/fakes"
 	"github.com/AP-Hunt/what-next/m/todo"
 	. "github.com/AP-Hunt/what-next/m/todo/fakes"
 	"github.com/AP-Hunt/what-next/m/views"
 	. "github.com/AP-Hunt/what-next/m/views/fakes"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
 )
 
 var _ = Describe("Todo", func() {
 	var (
 		viewEngine *FakeViewEngineInterface
 		todoRepo   *FakeTodoRepositoryInterface
+		editor     *editorFakes.FakeEditorInterface
 		cmdContext commandContext.CommandContext
 	)
 
 	BeforeEach(func() {
 		viewEngine = &FakeViewEngineInterface{}
 		todoRepo = &FakeTodoRepositoryInterface{}
+		editor = &editorFakes.FakeEditorInterface{}
 
 		cmdContext = commandContext.NewCommandContext(context.Background()).
 			WithTodoRepository(todoRepo).
-			WithViewEngine(viewEngine)
+			WithViewEngine(viewEngine).
+			WithEditor(editor).
+			WithClock(clock.NewFixedClock(time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)))
 
 	})
 
@@ -55,6 +63,110 @@ var _ = Describe("Todo", func() {
 			Expect(drawnView).To(BeAssignableToTypeOf(&views.TodoListView{}))
 
 		})
+
+		It("adds the new item with the --priority given", func() {
+			todoRepo.AddReturns(todo.TodoItem{Id: 1, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"foo", "--priority", "high"})
+			cmd.TodoAddCmd.Flags().String("priority", "", "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.AddArgsForCall(0).Priority).To(Equal(todo.PriorityHigh))
+		})
+
+		It("adds the new item with the default priority when none is given", func() {
+			todoRepo.AddReturns(todo.TodoItem{Id: 1, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"foo"})
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.AddArgsForCall(0).Priority).To(Equal(todo.DefaultPriority))
+		})
+
+		It("adds the new item with the --tag given", func() {
+			todoRepo.AddReturns(todo.TodoItem{Id: 1, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"foo", "--tag", "@Laptop", "--tag", "+release"})
+			cmd.TodoAddCmd.Flags().StringSlice("tag", []string{}, "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.AddArgsForCall(0).Tags).To(Equal([]string{"@laptop", "+release"}))
+		})
+
+		It("adds the new item as a subtask of the --parent given", func() {
+			todoRepo.GetReturns(todo.TodoItem{Id: 5, Action: "parent"}, nil)
+			todoRepo.AddReturns(todo.TodoItem{Id: 6, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"foo", "--parent", "5"})
+			cmd.TodoAddCmd.Flags().String("parent", "", "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*todoRepo.AddArgsForCall(0).ParentId).To(Equal(5))
+		})
+
+		It("returns an error when the --parent doesn't exist", func() {
+			todoRepo.GetReturns(todo.TodoItem{}, sql.ErrNoRows)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"foo", "--parent", "5"})
+			cmd.TodoAddCmd.Flags().String("parent", "", "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 5"))
+			Expect(todoRepo.AddCallCount()).To(Equal(0))
+		})
+
+		It("adds the new item with the --note and --link given", func() {
+			todoRepo.AddReturns(todo.TodoItem{Id: 1, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{
+				"foo",
+				"--note", "see the thread in #releases",
+				"--note", "needs a changelog entry",
+				"--link", "https://example.com/pull/1",
+			})
+			cmd.TodoAddCmd.Flags().StringArray("note", []string{}, "")
+			cmd.TodoAddCmd.Flags().StringArray("link", []string{}, "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			added := todoRepo.AddArgsForCall(0)
+			Expect(added.Notes).To(Equal("see the thread in #releases\nneeds a changelog entry"))
+			Expect(added.Links).To(Equal([]string{"https://example.com/pull/1"}))
+		})
+
+		It("returns an error for a --link which isn't a URL", func() {
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"foo", "--link", "JIRA-123"})
+			cmd.TodoAddCmd.Flags().StringArray("link", []string{}, "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("invalid link 'JIRA-123'")))
+			Expect(todoRepo.AddCallCount()).To(Equal(0))
+		})
+
+		It("adds a recurring item, due on the first day of the --every given", func() {
+			todoRepo.AddReturns(todo.TodoItem{Id: 1, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoAddCmd, []string{"submit timesheet", "--every", "friday"})
+			cmd.TodoAddCmd.Flags().String("every", "", "")
+			cmd.TodoAddCmd.Flags().String("due", "", "")
+
+			err := cmd.TodoAddCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			added := todoRepo.AddArgsForCall(0)
+			Expect(*added.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=FR"))
+			Expect(added.DueDate.Weekday()).To(Equal(time.Friday))
+			Expect(added.DueDate.Day()).To(Equal(21))
+		})
 	})
 
 	Describe("List", func() {
@@ -81,7 +193,725 @@ var _ = Describe("Todo", func() {
 			drawnView := viewEngine.DrawArgsForCall(0)
 
 			Expect(drawnView).To(BeAssignableToTypeOf(&views.TodoListView{}))
+			Expect(drawnView.(*views.TodoListView).Now).To(Equal(time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)))
+		})
+
+		It("only shows items with all of the --tag, and none of the --exclude-tag, given", func() {
+			laptop := &todo.TodoItem{Id: 1, Action: "laptop", Tags: []string{"@laptop"}}
+			laptopRelease := &todo.TodoItem{Id: 2, Action: "laptop release", Tags: []string{"@laptop", "+release"}}
+			phone := &todo.TodoItem{Id: 3, Action: "phone", Tags: []string{"@phone"}}
+			todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{laptop, laptopRelease, phone}), nil)
+
+			PrepareCommandForTest(cmd.TodoListCmd, []string{"--tag", "@laptop", "--exclude-tag", "+release"})
+			cmd.TodoListCmd.Flags().StringSlice("tag", []string{}, "")
+			cmd.TodoListCmd.Flags().StringSlice("exclude-tag", []string{}, "")
+
+			err := cmd.TodoListCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			drawnItems := viewEngine.DrawArgsForCall(0).Data().(*todo.TodoItemCollection)
+			Expect(drawnItems.Enumerate()).To(ConsistOf(laptop))
+		})
+
+		It("shows the archived items with --archived", func() {
+			archived := &todo.TodoItem{Id: 1, Action: "archived", Completed: true}
+			todoRepo.ListArchivedReturns(todo.NewTodoItemCollection([]*todo.TodoItem{archived}), nil)
+
+			PrepareCommandForTest(cmd.TodoListCmd, []string{"--archived"})
+			cmd.TodoListCmd.Flags().Bool("archived", false, "")
+
+			err := cmd.TodoListCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.ListCallCount()).To(Equal(0))
+			drawnItems := viewEngine.DrawArgsForCall(0).Data().(*todo.TodoItemCollection)
+			Expect(drawnItems.Enumerate()).To(ConsistOf(archived))
+		})
+	})
+
+	Describe("List filters and sorting", func() {
+		var (
+			overdue     *todo.TodoItem
+			quick       *todo.TodoItem
+			noDue       *todo.TodoItem
+			oldComplete *todo.TodoItem
+			newComplete *todo.TodoItem
+		)
+
+		BeforeEach(func() {
+			now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+			yesterday := now.AddDate(0, 0, -1)
+			nextWeek := now.AddDate(0, 0, 7)
+			lastWeek := now.AddDate(0, 0, -7)
+			anHourAgo := now.Add(-time.Hour)
+			short := 10 * time.Minute
+			long := 3 * time.Hour
+
+			overdue = &todo.TodoItem{Id: 1, Action: "overdue", DueDate: &yesterday, Duration: &long, Priority: todo.PriorityLow}
+			quick = &todo.TodoItem{Id: 2, Action: "quick", DueDate: &nextWeek, Duration: &short, Priority: todo.PriorityHigh}
+			noDue = &todo.TodoItem{Id: 3, Action: "no due", Priority: todo.PriorityMedium}
+			oldComplete = &todo.TodoItem{Id: 4, Action: "old", Completed: true, CompletedAt: &lastWeek}
+			newComplete = &todo.TodoItem{Id: 5, Action: "new", Completed: true, CompletedAt: &anHourAgo}
+
+			todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{overdue, quick, noDue, oldComplete, newComplete}), nil)
+		})
+
+		listWith := func(args ...string) []*todo.TodoItem {
+			PrepareCommandForTest(cmd.TodoListCmd, args)
+			cmd.TodoListCmd.Flags().Bool("all", false, "")
+			cmd.TodoListCmd.Flags().String("completed-since", "", "")
+			cmd.TodoListCmd.Flags().Bool("overdue", false, "")
+			cmd.TodoListCmd.Flags().String("due-before", "", "")
+			cmd.TodoListCmd.Flags().String("due-after", "", "")
+			cmd.TodoListCmd.Flags().Bool("no-due", false, "")
+			cmd.TodoListCmd.Flags().String("max-duration", "", "")
+			cmd.TodoListCmd.Flags().String("sort", "", "")
+			cmd.TodoListCmd.Flags().Bool("desc", false, "")
+
+			err := cmd.TodoListCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			return viewEngine.DrawArgsForCall(viewEngine.DrawCallCount() - 1).Data().(*todo.TodoItemCollection).Enumerate()
+		}
+
+		It("only shows items completed in the last 24 hours by default", func() {
+			Expect(listWith()).To(Equal([]*todo.TodoItem{overdue, quick, noDue, newComplete}))
+		})
+
+		It("shows every completed item with --all", func() {
+			Expect(listWith("--all")).To(ContainElement(oldComplete))
+		})
+
+		It("shows items completed since the date given with --completed-since", func() {
+			Expect(listWith("--completed-since", "2022-10-01")).To(ContainElements(oldComplete, newComplete))
+		})
+
+		It("shows items completed since the start of the day given with --completed-since", func() {
+			Expect(listWith("--completed-since", "today")).To(Equal([]*todo.TodoItem{overdue, quick, noDue, newComplete}))
+			Expect(listWith("--completed-since", "monday")).To(Equal([]*todo.TodoItem{overdue, quick, noDue, newComplete}))
+		})
+
+		It("only shows overdue items with --overdue", func() {
+			Expect(listWith("--overdue")).To(Equal([]*todo.TodoItem{overdue}))
+		})
+
+		It("only shows items due in the range given with --due-after and --due-before", func() {
+			Expect(listWith("--due-after", "2022-10-17", "--due-before", "2022-10-30")).To(Equal([]*todo.TodoItem{quick}))
+		})
+
+		It("only shows items without a due date with --no-due", func() {
+			Expect(listWith("--no-due")).To(Equal([]*todo.TodoItem{noDue, newComplete}))
+		})
+
+		It("only shows items which fit in the time given with --max-duration", func() {
+			Expect(listWith("--max-duration", "30m")).To(Equal([]*todo.TodoItem{quick}))
+		})
+
+		It("sorts the items with --sort, and reverses them with --desc", func() {
+			Expect(listWith("--sort", "priority")).To(Equal([]*todo.TodoItem{newComplete, quick, noDue, overdue}))
+			Expect(listWith("--sort", "duration", "--due-after", "2022-10-01")).To(Equal([]*todo.TodoItem{quick, overdue}))
+			Expect(listWith("--sort", "due", "--desc", "--due-after", "2022-10-01")).To(Equal([]*todo.TodoItem{quick, overdue}))
+		})
+
+		It("keeps items without a due date last when sorting by due date with --desc", func() {
+			Expect(listWith("--sort", "due", "--desc")).To(Equal([]*todo.TodoItem{quick, overdue, noDue, newComplete}))
+		})
+
+		It("returns an error for an unknown sort order", func() {
+			PrepareCommandForTest(cmd.TodoListCmd, []string{"--sort", "alphabetical"})
+			cmd.TodoListCmd.Flags().String("sort", "", "")
+
+			err := cmd.TodoListCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("invalid sort order")))
+		})
+	})
+
+	Describe("Tag", func() {
+		It("tags the item and renders it", func() {
+			item := todo.TodoItem{Id: 1, Action: "foo"}
+			todoRepo.GetReturns(item, nil)
+			item.Tags = []string{"@phone"}
+			todoRepo.TagReturns(item, nil)
+
+			PrepareCommandForTest(cmd.TodoTagCmd, []string{"1", "@phone"})
+
+			err := cmd.TodoTagCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, tags := todoRepo.TagArgsForCall(0)
+			Expect(id).To(Equal(1))
+			Expect(tags).To(Equal([]string{"@phone"}))
+
+			drawnItems := viewEngine.DrawArgsForCall(0).Data().(*todo.TodoItemCollection)
+			Expect(drawnItems.Enumerate()[0].Tags).To(Equal([]string{"@phone"}))
+		})
+
+		It("returns an error when the item doesn't exist", func() {
+			todoRepo.GetReturns(todo.TodoItem{}, sql.ErrNoRows)
+
+			PrepareCommandForTest(cmd.TodoTagCmd, []string{"1", "@phone"})
+
+			err := cmd.TodoTagCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 1"))
+			Expect(todoRepo.TagCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Untag", func() {
+		It("untags the item", func() {
+			todoRepo.GetReturns(todo.TodoItem{Id: 1, Action: "foo", Tags: []string{"@phone"}}, nil)
+			todoRepo.UntagReturns(todo.TodoItem{Id: 1, Action: "foo"}, nil)
+
+			PrepareCommandForTest(cmd.TodoUntagCmd, []string{"1", "@phone"})
+
+			err := cmd.TodoUntagCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, tags := todoRepo.UntagArgsForCall(0)
+			Expect(id).To(Equal(1))
+			Expect(tags).To(Equal([]string{"@phone"}))
+		})
+	})
+
+	Describe("Edit", func() {
+		var item todo.TodoItem
+
+		prepareEditCmd := func(args []string) {
+			PrepareCommandForTest(cmd.TodoEditCmd, args)
+			cmd.TodoEditCmd.Flags().String("action", "", "")
+			cmd.TodoEditCmd.Flags().String("due", "", "")
+			cmd.TodoEditCmd.Flags().String("duration", "", "")
+			cmd.TodoEditCmd.Flags().String("priority", "", "")
+			cmd.TodoEditCmd.Flags().Bool("clear-due", false, "")
+			cmd.TodoEditCmd.Flags().Bool("clear-duration", false, "")
+			cmd.TodoEditCmd.Flags().String("every", "", "")
+			cmd.TodoEditCmd.Flags().Bool("clear-every", false, "")
+			cmd.TodoEditCmd.Flags().StringArray("note", []string{}, "")
+			cmd.TodoEditCmd.Flags().StringArray("link", []string{}, "")
+			cmd.TodoEditCmd.Flags().StringArray("remove-link", []string{}, "")
+		}
+
+		BeforeEach(func() {
+			dueDate := time.Date(2022, time.October, 20, 17, 0, 0, 0, time.Local)
+			duration := 30 * time.Minute
+			item = todo.TodoItem{Id: 1, Action: "foo", DueDate: &dueDate, Duration: &duration, Priority: todo.PriorityMedium}
+
+			todoRepo.GetReturns(item, nil)
+			todoRepo.UpdateStub = func(updated todo.TodoItem) (todo.TodoItem, error) {
+				return updated, nil
+			}
+		})
+
+		It("changes only the fields given by flags", func() {
+			prepareEditCmd([]string{"1", "--action", "bar", "--duration", "1h"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			updated := todoRepo.UpdateArgsForCall(0)
+			Expect(updated.Id).To(Equal(1))
+			Expect(updated.Action).To(Equal("bar"))
+			Expect(*updated.Duration).To(Equal(time.Hour))
+			Expect(updated.DueDate).To(Equal(item.DueDate))
+			Expect(editor.EditCallCount()).To(Equal(0))
+			Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		})
+
+		It("clears the due date and duration", func() {
+			prepareEditCmd([]string{"1", "--clear-due", "--clear-duration"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			updated := todoRepo.UpdateArgsForCall(0)
+			Expect(updated.DueDate).To(BeNil())
+			Expect(updated.Duration).To(BeNil())
+		})
+
+		It("makes an item without a due date recurring, due on the first day of the --every given", func() {
+			item.DueDate = nil
+			todoRepo.GetReturns(item, nil)
+
+			prepareEditCmd([]string{"1", "--every", "friday"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			updated := todoRepo.UpdateArgsForCall(0)
+			Expect(*updated.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=FR"))
+			Expect(updated.DueDate.Weekday()).To(Equal(time.Friday))
+			Expect(updated.DueDate.Day()).To(Equal(21))
+		})
+
+		It("gives a recurring item the first due date of the --every given when its due date is cleared", func() {
+			prepareEditCmd([]string{"1", "--every", "monday", "--clear-due"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			updated := todoRepo.UpdateArgsForCall(0)
+			Expect(updated.DueDate).ToNot(BeNil())
+			Expect(updated.DueDate.Weekday()).To(Equal(time.Monday))
+		})
+
+		It("adds notes and links, and removes links", func() {
+			item.Notes = "waiting on the security review"
+			item.Links = []string{"https://example.com/pull/1", "https://example.com/issues/2"}
+			todoRepo.GetReturns(item, nil)
+
+			prepareEditCmd([]string{
+				"1",
+				"--note", "approved, ready to merge",
+				"--link", "https://example.com/pull/3",
+				"--remove-link", "https://example.com/issues/2",
+			})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			updated := todoRepo.UpdateArgsForCall(0)
+			Expect(updated.Notes).To(Equal("waiting on the security review\napproved, ready to merge"))
+			Expect(updated.Links).To(Equal([]string{"https://example.com/pull/1", "https://example.com/pull/3"}))
+		})
+
+		It("validates the due date", func() {
+			prepareEditCmd([]string{"1", "--due", "not a date"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("invalid due date")))
+			Expect(todoRepo.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("does not allow a field to be set and cleared at the same time", func() {
+			prepareEditCmd([]string{"1", "--due", "@tomorrow", "--clear-due"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).To(HaveOccurred())
+			Expect(todoRepo.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("opens the item in the editor when no flags are given", func() {
+			editor.EditReturns("action: edited\ndue:\nduration: 45m\npriority: high\n", nil)
+			prepareEditCmd([]string{"1"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(editor.EditArgsForCall(0)).To(ContainSubstring("action: foo"))
+
+			updated := todoRepo.UpdateArgsForCall(0)
+			Expect(updated.Action).To(Equal("edited"))
+			Expect(updated.DueDate).To(BeNil())
+			Expect(*updated.Duration).To(Equal(45 * time.Minute))
+			Expect(updated.Priority).To(Equal(todo.PriorityHigh))
+		})
+
+		It("returns an error when the item doesn't exist", func() {
+			todoRepo.GetReturns(todo.TodoItem{}, sql.ErrNoRows)
+			prepareEditCmd([]string{"1", "--action", "bar"})
+
+			err := cmd.TodoEditCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 1"))
+		})
+	})
+
+	Describe("Complete", func() {
+		BeforeEach(func() {
+			todoRepo.UpdateStub = func(updated todo.TodoItem) (todo.TodoItem, error) {
+				return updated, nil
+			}
+			todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{}), nil)
+		})
+
+		It("completes the item", func() {
+			todoRepo.GetReturns(todo.TodoItem{Id: 1}, nil)
+
+			PrepareCommandForTest(cmd.TodoCompleteCmd, []string{"1"})
+
+			err := cmd.TodoCompleteCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.UpdateArgsForCall(0).Completed).To(BeTrue())
+			Expect(todoRepo.AddCallCount()).To(Equal(0))
+		})
+
+		It("adds the next instance of a recurring item", func() {
+			dueDate := time.Date(2022, time.October, 17, 17, 0, 0, 0, time.Local)
+			every := "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+			todoRepo.GetReturns(todo.TodoItem{Id: 1, Action: "review PRs", DueDate: &dueDate, Recurrence: &every}, nil)
+			todoRepo.AddStub = func(added todo.TodoItem) (todo.TodoItem, error) {
+				added.Id = 2
+				return added, nil
+			}
+
+			PrepareCommandForTest(cmd.TodoCompleteCmd, []string{"1"})
+
+			err := cmd.TodoCompleteCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.AddCallCount()).To(Equal(1))
+			next := todoRepo.AddArgsForCall(0)
+			Expect(next.Action).To(Equal("review PRs"))
+			Expect(next.Completed).To(BeFalse())
+			Expect(*next.DueDate).To(Equal(time.Date(2022, time.October, 18, 17, 0, 0, 0, time.Local)))
+
+			drawn := viewEngine.DrawArgsForCall(0).Data().(*todo.TodoItemCollection)
+			Expect(drawn.Len()).To(Equal(2))
+		})
+
+		It("does not change an item which is already complete", func() {
+			completedAt := time.Date(2022, time.October, 16, 9, 0, 0, 0, time.Local)
+			todoRepo.GetReturns(todo.TodoItem{Id: 1, Completed: true, CompletedAt: &completedAt}, nil)
+
+			PrepareCommandForTest(cmd.TodoCompleteCmd, []string{"1"})
+
+			err := cmd.TodoCompleteCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.UpdateCallCount()).To(Equal(0))
+
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.MessageView{}))
+			Expect(drawnView.Data()).To(Equal("Todo item 1 is already complete, it was completed at 09:00 on Sunday October 16."))
+		})
+		It("does not complete an item with incomplete subtasks", func() {
+			parentId := 1
+			todoRepo.GetReturns(todo.TodoItem{Id: 1}, nil)
+			todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{
+				{Id: 1},
+				{Id: 2, ParentId: &parentId},
+			}), nil)
+
+			PrepareCommandForTest(cmd.TodoCompleteCmd, []string{"1"})
+
+			err := cmd.TodoCompleteCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("has subtasks which aren't complete")))
+			Expect(todoRepo.UpdateCallCount()).To(Equal(0))
+		})
+
+		It("completes the parent when its last incomplete subtask is completed", func() {
+			parentId := 1
+			parent := todo.TodoItem{Id: 1}
+			subtask := todo.TodoItem{Id: 2, ParentId: &parentId}
+			completedSibling := todo.TodoItem{Id: 3, ParentId: &parentId, Completed: true}
+
+			todoRepo.GetStub = func(id int) (todo.TodoItem, error) {
+				if id == parent.Id {
+					return parent, nil
+				}
+				return subtask, nil
+			}
+			todoRepo.UpdateStub = func(updated todo.TodoItem) (todo.TodoItem, error) {
+				if updated.Id == subtask.Id {
+					subtask = updated
+				}
+				return updated, nil
+			}
+			todoRepo.ListStub = func() (*todo.TodoItemCollection, error) {
+				return todo.NewTodoItemCollection([]*todo.TodoItem{&parent, &subtask, &completedSibling}), nil
+			}
+
+			PrepareCommandForTest(cmd.TodoCompleteCmd, []string{"2"})
+
+			err := cmd.TodoCompleteCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.UpdateCallCount()).To(Equal(2))
+			Expect(todoRepo.UpdateArgsForCall(1).Id).To(Equal(1))
+			Expect(todoRepo.UpdateArgsForCall(1).Completed).To(BeTrue())
+		})
+	})
+
+	Describe("Reopen", func() {
+		BeforeEach(func() {
+			todoRepo.UpdateStub = func(updated todo.TodoItem) (todo.TodoItem, error) {
+				return updated, nil
+			}
+		})
+
+		It("reopens a completed item", func() {
+			completedAt := time.Now()
+			todoRepo.GetReturns(todo.TodoItem{Id: 1, Completed: true, CompletedAt: &completedAt}, nil)
+
+			PrepareCommandForTest(cmd.TodoReopenCmd, []string{"1"})
+
+			err := cmd.TodoReopenCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			reopened := todoRepo.UpdateArgsForCall(0)
+			Expect(reopened.Completed).To(BeFalse())
+			Expect(reopened.CompletedAt).To(BeNil())
+			Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		})
+
+		It("reopens the completed parent of a reopened subtask", func() {
+			parentId := 1
+			completedAt := time.Now()
+			todoRepo.GetStub = func(id int) (todo.TodoItem, error) {
+				if id == parentId {
+					return todo.TodoItem{Id: 1, Completed: true, CompletedAt: &completedAt}, nil
+				}
+				return todo.TodoItem{Id: 2, ParentId: &parentId, Completed: true, CompletedAt: &completedAt}, nil
+			}
+
+			PrepareCommandForTest(cmd.TodoReopenCmd, []string{"2"})
+
+			err := cmd.TodoReopenCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.UpdateCallCount()).To(Equal(2))
+			Expect(todoRepo.UpdateArgsForCall(1).Id).To(Equal(1))
+			Expect(todoRepo.UpdateArgsForCall(1).Completed).To(BeFalse())
+		})
+
+		It("removes the next instance of a recurring item, so completing it again only adds one", func() {
+			dueDate := time.Date(2022, time.October, 17, 17, 0, 0, 0, time.Local)
+			every := "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+			stored := map[int]todo.TodoItem{
+				1: {Id: 1, Action: "review PRs", DueDate: &dueDate, Recurrence: &every},
+			}
+			nextId := 2
+
+			todoRepo.GetStub = func(id int) (todo.TodoItem, error) {
+				return stored[id], nil
+			}
+			todoRepo.ListStub = func() (*todo.TodoItemCollection, error) {
+				items := []*todo.TodoItem{}
+				for id := 1; id < nextId; id++ {
+					if item, ok := stored[id]; ok {
+						items = append(items, &item)
+					}
+				}
+				return todo.NewTodoItemCollection(items), nil
+			}
+			todoRepo.AddStub = func(added todo.TodoItem) (todo.TodoItem, error) {
+				added.Id = nextId
+				nextId++
+				stored[added.Id] = added
+				return added, nil
+			}
+			todoRepo.UpdateStub = func(updated todo.TodoItem) (todo.TodoItem, error) {
+				stored[updated.Id] = updated
+				return updated, nil
+			}
+			todoRepo.DeleteStub = func(id int) error {
+				delete(stored, id)
+				return nil
+			}
+
+			for _, command := range []*cobra.Command{cmd.TodoCompleteCmd, cmd.TodoReopenCmd, cmd.TodoCompleteCmd} {
+				PrepareCommandForTest(command, []string{"1"})
+				Expect(command.ExecuteContext(cmdContext)).To(Succeed())
+			}
+
+			Expect(todoRepo.AddCallCount()).To(Equal(2))
+			Expect(todoRepo.DeleteCallCount()).To(Equal(1))
+			Expect(todoRepo.DeleteArgsForCall(0)).To(Equal(2))
+
+			items, err := todoRepo.List()
+			Expect(err).ToNot(HaveOccurred())
+			nextInstances := items.Filter(todo.IsIncomplete).Enumerate()
+			Expect(nextInstances).To(HaveLen(1))
+			Expect(nextInstances[0].Action).To(Equal("review PRs"))
+			Expect(*nextInstances[0].DueDate).To(Equal(time.Date(2022, time.October, 18, 17, 0, 0, 0, time.Local)))
+		})
+
+		It("does not change an item which isn't complete", func() {
+			todoRepo.GetReturns(todo.TodoItem{Id: 1}, nil)
+
+			PrepareCommandForTest(cmd.TodoReopenCmd, []string{"1"})
+
+			err := cmd.TodoReopenCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.UpdateCallCount()).To(Equal(0))
+
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.MessageView{}))
+			Expect(drawnView.Data()).To(Equal("Todo item 1 isn't complete."))
+		})
+
+		It("returns an error when the item doesn't exist", func() {
+			todoRepo.GetReturns(todo.TodoItem{}, sql.ErrNoRows)
+
+			PrepareCommandForTest(cmd.TodoReopenCmd, []string{"1"})
+
+			err := cmd.TodoReopenCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 1"))
+		})
+	})
+
+	Describe("Block", func() {
+		prepareBlockCmd := func(args []string) {
+			PrepareCommandForTest(cmd.TodoBlockCmd, args)
+			cmd.TodoBlockCmd.Flags().StringSlice("on", []string{}, "")
+		}
+
+		BeforeEach(func() {
+			todoRepo.GetStub = func(id int) (todo.TodoItem, error) {
+				return todo.TodoItem{Id: id}, nil
+			}
+			todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{}), nil)
+		})
+
+		It("blocks the item on the items given by --on", func() {
+			todoRepo.BlockReturns(todo.TodoItem{Id: 1, BlockedBy: []int{2, 3}}, nil)
+			prepareBlockCmd([]string{"1", "--on", "2,3"})
+
+			err := cmd.TodoBlockCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, blockedBy := todoRepo.BlockArgsForCall(0)
+			Expect(id).To(Equal(1))
+			Expect(blockedBy).To(Equal([]int{2, 3}))
+			Expect(viewEngine.DrawCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when --on isn't given", func() {
+			prepareBlockCmd([]string{"1"})
+
+			err := cmd.TodoBlockCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("--on")))
+		})
+
+		It("does not block an item on itself", func() {
+			prepareBlockCmd([]string{"1", "--on", "1"})
+
+			err := cmd.TodoBlockCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("todo item 1 can't be blocked by itself"))
+			Expect(todoRepo.BlockCallCount()).To(Equal(0))
+		})
+
+		It("does not block an item on one which is waiting on it", func() {
+			todoRepo.ListReturns(todo.NewTodoItemCollection([]*todo.TodoItem{
+				{Id: 1},
+				{Id: 2, BlockedBy: []int{3}},
+				{Id: 3, BlockedBy: []int{1}},
+			}), nil)
+			prepareBlockCmd([]string{"1", "--on", "2"})
+
+			err := cmd.TodoBlockCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError(ContainSubstring("because 2 is already waiting on 1")))
+			Expect(todoRepo.BlockCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when an item doesn't exist", func() {
+			todoRepo.GetStub = func(id int) (todo.TodoItem, error) {
+				if id == 2 {
+					return todo.TodoItem{}, sql.ErrNoRows
+				}
+				return todo.TodoItem{Id: id}, nil
+			}
+			prepareBlockCmd([]string{"1", "--on", "2"})
+
+			err := cmd.TodoBlockCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 2"))
+		})
+	})
+
+	Describe("Unblock", func() {
+		It("unblocks the item from the items given by --on", func() {
+			todoRepo.GetStub = func(id int) (todo.TodoItem, error) {
+				return todo.TodoItem{Id: id}, nil
+			}
+			todoRepo.UnblockReturns(todo.TodoItem{Id: 1}, nil)
+
+			PrepareCommandForTest(cmd.TodoUnblockCmd, []string{"1", "--on", "2"})
+			cmd.TodoUnblockCmd.Flags().StringSlice("on", []string{}, "")
+
+			err := cmd.TodoUnblockCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			id, blockedBy := todoRepo.UnblockArgsForCall(0)
+			Expect(id).To(Equal(1))
+			Expect(blockedBy).To(Equal([]int{2}))
+		})
+	})
+
+	Describe("Show", func() {
+		It("renders the item in a TodoItem view", func() {
+			todoRepo.GetReturns(todo.TodoItem{Id: 1, Action: "foo", Notes: "some notes"}, nil)
+
+			PrepareCommandForTest(cmd.TodoShowCmd, []string{"1"})
+
+			err := cmd.TodoShowCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.TodoItemView{}))
+			Expect(drawnView.Data().(*todo.TodoItem).Notes).To(Equal("some notes"))
+		})
+
+		It("returns an error when the item doesn't exist", func() {
+			todoRepo.GetReturns(todo.TodoItem{}, sql.ErrNoRows)
+
+			PrepareCommandForTest(cmd.TodoShowCmd, []string{"1"})
+
+			err := cmd.TodoShowCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 1"))
+		})
+	})
+
+	Describe("Remove", func() {
+		It("deletes each of the items", func() {
+			todoRepo.GetReturns(todo.TodoItem{Id: 1}, nil)
+
+			PrepareCommandForTest(cmd.TodoRemoveCmd, []string{"1", "2"})
+
+			err := cmd.TodoRemoveCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.DeleteCallCount()).To(Equal(2))
+			Expect(todoRepo.DeleteArgsForCall(0)).To(Equal(1))
+			Expect(todoRepo.DeleteArgsForCall(1)).To(Equal(2))
+
+			drawnView := viewEngine.DrawArgsForCall(0)
+			Expect(drawnView).To(BeAssignableToTypeOf(&views.RemovedTodoItemsView{}))
+			Expect(drawnView.Data()).To(Equal([]int{1, 2}))
+		})
+
+		It("does not delete any items when one of them doesn't exist", func() {
+			todoRepo.GetReturnsOnCall(0, todo.TodoItem{Id: 1}, nil)
+			todoRepo.GetReturnsOnCall(1, todo.TodoItem{}, sql.ErrNoRows)
+
+			PrepareCommandForTest(cmd.TodoRemoveCmd, []string{"1", "2"})
+
+			err := cmd.TodoRemoveCmd.ExecuteContext(cmdContext)
+			Expect(err).To(MatchError("no todo item with id 2"))
+			Expect(todoRepo.DeleteCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Archive", func() {
+		It("archives each of the items", func() {
+			todoRepo.GetReturnsOnCall(0, todo.TodoItem{Id: 1}, nil)
+			todoRepo.GetReturnsOnCall(1, todo.TodoItem{Id: 2}, nil)
+			todoRepo.UpdateStub = func(updated todo.TodoItem) (todo.TodoItem, error) {
+				return updated, nil
+			}
+
+			PrepareCommandForTest(cmd.TodoArchiveCmd, []string{"1", "2"})
+
+			err := cmd.TodoArchiveCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(todoRepo.UpdateCallCount()).To(Equal(2))
+			Expect(todoRepo.UpdateArgsForCall(0).ArchivedAt).ToNot(BeNil())
+			Expect(todoRepo.UpdateArgsForCall(1).ArchivedAt).ToNot(BeNil())
+		})
+	})
+
+	Describe("Unarchive", func() {
+		It("unarchives the item", func() {
+			archivedAt := time.Now()
+			todoRepo.GetReturns(todo.TodoItem{Id: 1, ArchivedAt: &archivedAt}, nil)
+
+			PrepareCommandForTest(cmd.TodoUnarchiveCmd, []string{"1"})
+
+			err := cmd.TodoUnarchiveCmd.ExecuteContext(cmdContext)
+			Expect(err).ToNot(HaveOccurred())
 
+			Expect(todoRepo.UpdateArgsForCall(0).ArchivedAt).To(BeNil())
 		})
 	})
 })
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/context"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/terminal"
+	"github.com/AP-Hunt/what-next/m/todo"
+	"github.com/AP-Hunt/what-next/m/tui"
+	ical "github.com/arran4/golang-ical"
+	"github.com/spf13/cobra"
+)
+
+// tuiRefreshInterval is how often the interface loads the schedule again
+const tuiRefreshInterval = time.Minute
+
+var TuiCmd = &cobra.Command{
+	Use:  "tui",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ctx context.CommandContext = cmd.Context().(context.CommandContext)
+
+		term, err := terminal.Open(os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		defer term.Close()
+
+		app := tui.NewApp(&tuiBackend{ctx: ctx}, ctx.Clock(), tuiRefreshInterval)
+		return app.Run(term)
+	},
+}
+
+// tuiBackend loads what the interface shows, and changes the
+// todo list, with the services in the command context
+type tuiBackend struct {
+	ctx context.CommandContext
+}
+
+func (b *tuiBackend) Load() (*tui.Snapshot, error) {
+	now := b.ctx.Clock().Now()
+
+	calendars, options, err := openAllCalendars(b.ctx.CalendarService(), b.ctx.SchedulerOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	allItems, err := b.ctx.TodoRepository().List()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := scheduler.GenerateSchedule(now, calendars, allItems, options)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*ical.VEvent{}
+	for _, cal := range calendars {
+		for _, event := range eventsOnDay(cal, calendar.StartOfDay(now)) {
+			if !calendar.IsCancelledEvent(event) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	err = calendar.SortEventsByStartDateAscending(events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tui.Snapshot{
+		Now:       now,
+		Schedule:  schedule,
+		Events:    events,
+		TodoItems: allItems.Filter(todo.AnyOf(todo.IsIncomplete, todo.CompletedSince(now.Add(-24*time.Hour)))),
+	}, nil
+}
+
+func (b *tuiBackend) Add(action string) error {
+	_, err := b.ctx.TodoRepository().Add(todo.TodoItem{
+		Action:   action,
+		Priority: todo.DefaultPriority,
+		Tags:     []string{},
+	})
+	return err
+}
+
+func (b *tuiBackend) Complete(item todo.TodoItem) error {
+	_, err := completeTodoItem(b.ctx.TodoRepository(), item, b.ctx.Clock().Now())
+	return err
+}
+
+func (b *tuiBackend) Reopen(item todo.TodoItem) error {
+	_, err := reopenTodoItem(b.ctx.TodoRepository(), item)
+	return err
+}
+
+func (b *tuiBackend) Edit(item todo.TodoItem) error {
+	edited, err := editTodoItemWithEditor(b.ctx, item)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.ctx.TodoRepository().Update(edited)
+	return err
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 
 	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/clock"
+	"github.com/AP-Hunt/what-next/m/editor"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/search"
 	"github.com/AP-Hunt/what-next/m/todo"
 	"github.com/AP-Hunt/what-next/m/views"
 )
@@ -11,9 +15,14 @@ import (
 type ContextKey = string
 
 const (
-	CtxTodoRepo        ContextKey = "TodoRepo"
-	CtxViewEngine      ContextKey = "ViewEngine"
-	CtxCalendarService ContextKey = "CalendarService"
+	CtxTodoRepo         ContextKey = "TodoRepo"
+	CtxViewEngine       ContextKey = "ViewEngine"
+	CtxCalendarService  ContextKey = "CalendarService"
+	CtxClock            ContextKey = "Clock"
+	CtxSchedulerOptions ContextKey = "SchedulerOptions"
+	CtxEditor           ContextKey = "Editor"
+	CtxSearchIndex      ContextKey = "SearchIndex"
+	CtxConfigDir        ContextKey = "ConfigDir"
 )
 
 type CommandContext struct {
@@ -49,3 +58,44 @@ func (ctx CommandContext) WithCalendarService(service calendar.CalendarServiceIn
 func (ctx CommandContext) CalendarService() calendar.CalendarServiceInterface {
 	return ctx.Value(CtxCalendarService).(calendar.CalendarServiceInterface)
 }
+
+func (ctx CommandContext) WithClock(clock clock.Clock) CommandContext {
+	return CommandContext{context.WithValue(ctx, CtxClock, clock)}
+}
+
+func (ctx CommandContext) Clock() clock.Clock {
+	return ctx.Value(CtxClock).(clock.Clock)
+}
+
+func (ctx CommandContext) WithSchedulerOptions(options scheduler.Options) CommandContext {
+	return CommandContext{context.WithValue(ctx, CtxSchedulerOptions, options)}
+}
+
+func (ctx CommandContext) SchedulerOptions() scheduler.Options {
+	return ctx.Value(CtxSchedulerOptions).(scheduler.Options)
+}
+
+func (ctx CommandContext) WithEditor(editor editor.EditorInterface) CommandContext {
+	return CommandContext{context.WithValue(ctx, CtxEditor, editor)}
+}
+
+func (ctx CommandContext) Editor() editor.EditorInterface {
+	return ctx.Value(CtxEditor).(editor.EditorInterface)
+}
+
+func (ctx CommandContext) WithSearchIndex(index search.SearchIndexInterface) CommandContext {
+	return CommandContext{context.WithValue(ctx, CtxSearchIndex, index)}
+}
+
+func (ctx CommandContext) SearchIndex() search.SearchIndexInterface {
+	return ctx.Value(CtxSearchIndex).(search.SearchIndexInterface)
+}
+
+func (ctx CommandContext) WithConfigDir(dir string) CommandContext {
+	return CommandContext{context.WithValue(ctx, CtxConfigDir, dir)}
+}
+
+// ConfigDir is the directory config.yaml and the named templates are in
+func (ctx CommandContext) ConfigDir() string {
+	return ctx.Value(CtxConfigDir).(string)
+}
@@ -2,34 +2,81 @@ package context
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/clock"
 	"github.com/AP-Hunt/what-next/m/db"
+	"github.com/AP-Hunt/what-next/m/editor"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/search"
 	"github.com/AP-Hunt/what-next/m/todo"
 	"github.com/AP-Hunt/what-next/m/views"
+	"github.com/hako/durafmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/spf13/viper"
 )
 
 const (
 	CFG_KEY_DATA_DIR = "WHAT_NEXT_DATA_DIR"
+	CFG_KEY_TIMEZONE = "WHAT_NEXT_TIMEZONE"
+
+	CFG_KEY_WORKING_HOURS = "working_hours"
+	CFG_KEY_BUFFERS       = "buffers"
 )
 
+const workingHoursWeekdays = "weekdays"
+
+type buffersConfig struct {
+	Before string `mapstructure:"before"`
+	After  string `mapstructure:"after"`
+}
+
+type workingDayConfig struct {
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+	Lunch string `mapstructure:"lunch"`
+}
+
 func CreateDefaultCommandContext(parentContext context.Context) (CommandContext, error) {
 	ctx := NewCommandContext(parentContext)
 
 	initViper()
 
+	err := initTimezone(viper.GetString(CFG_KEY_TIMEZONE))
+	if err != nil {
+		return CommandContext{}, err
+	}
+
 	database, err := initDb(viper.GetString(CFG_KEY_DATA_DIR))
 	if err != nil {
 		return CommandContext{}, err
 	}
 
+	workingHours, err := loadWorkingHours()
+	if err != nil {
+		return CommandContext{}, err
+	}
+
+	buffers, err := loadBuffers()
+	if err != nil {
+		return CommandContext{}, err
+	}
+
 	ctx = ctx.
 		WithTodoRepository(todo.NewTodoSQLRepository(database, ctx)).
 		WithViewEngine(&views.StdOutViewEngine{}).
+		WithClock(clock.NewSystemClock()).
+		WithEditor(editor.NewExternalEditor()).
+		WithSearchIndex(search.NewSQLiteSearchIndex(database, ctx)).
+		WithConfigDir(viper.GetString(CFG_KEY_DATA_DIR)).
+		WithSchedulerOptions(scheduler.Options{
+			WorkingHours: workingHours,
+			Buffers:      buffers,
+		}).
 		WithCalendarService(
 			calendar.NewCalendarService(
 				database,
@@ -61,6 +108,141 @@ func initViper() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = viper.BindEnv(CFG_KEY_TIMEZONE)
+	if err != nil {
+		panic(err)
+	}
+
+	viper.SetConfigName("config")
+	viper.AddConfigPath(viper.GetString(CFG_KEY_DATA_DIR))
+	err = viper.ReadInConfig()
+	if err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			panic(err)
+		}
+	}
+}
+
+// loadWorkingHours reads the working hours from the config file. Each day
+// of the week can have its own working hours, and the hours for "weekdays"
+// apply to every day from Monday to Friday that doesn't.
+//
+// There are no working hours when none are configured.
+func loadWorkingHours() (scheduler.WorkingHours, error) {
+	if !viper.IsSet(CFG_KEY_WORKING_HOURS) {
+		return nil, nil
+	}
+
+	config := map[string]workingDayConfig{}
+	err := viper.UnmarshalKey(CFG_KEY_WORKING_HOURS, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", CFG_KEY_WORKING_HOURS, err)
+	}
+
+	workingHours := scheduler.WorkingHours{}
+
+	if weekdaysConfig, ok := config[workingHoursWeekdays]; ok {
+		workingDay, err := parseWorkingDay(weekdaysConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s for %s: %s", CFG_KEY_WORKING_HOURS, workingHoursWeekdays, err)
+		}
+
+		for weekday := time.Monday; weekday <= time.Friday; weekday++ {
+			workingHours[weekday] = workingDay
+		}
+	}
+
+	for key, dayConfig := range config {
+		if key == workingHoursWeekdays {
+			continue
+		}
+
+		weekday, err := scheduler.ParseWeekday(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", CFG_KEY_WORKING_HOURS, err)
+		}
+
+		workingDay, err := parseWorkingDay(dayConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s for %s: %s", CFG_KEY_WORKING_HOURS, key, err)
+		}
+
+		workingHours[weekday] = workingDay
+	}
+
+	return workingHours, nil
+}
+
+// loadBuffers reads the buffers to keep free before and after meetings
+// from the config file. Each is zero when it isn't configured.
+func loadBuffers() (scheduler.Buffers, error) {
+	config := buffersConfig{}
+	err := viper.UnmarshalKey(CFG_KEY_BUFFERS, &config)
+	if err != nil {
+		return scheduler.Buffers{}, fmt.Errorf("invalid %s: %s", CFG_KEY_BUFFERS, err)
+	}
+
+	buffers := scheduler.Buffers{}
+
+	if config.Before != "" {
+		before, err := durafmt.ParseString(config.Before)
+		if err != nil {
+			return scheduler.Buffers{}, fmt.Errorf("invalid %s before: %s", CFG_KEY_BUFFERS, err)
+		}
+		buffers.Before = before.Duration()
+	}
+
+	if config.After != "" {
+		after, err := durafmt.ParseString(config.After)
+		if err != nil {
+			return scheduler.Buffers{}, fmt.Errorf("invalid %s after: %s", CFG_KEY_BUFFERS, err)
+		}
+		buffers.After = after.Duration()
+	}
+
+	return buffers, nil
+}
+
+func parseWorkingDay(config workingDayConfig) (scheduler.WorkingDay, error) {
+	start, err := scheduler.ParseTimeOfDay(config.Start)
+	if err != nil {
+		return scheduler.WorkingDay{}, err
+	}
+
+	end, err := scheduler.ParseTimeOfDay(config.End)
+	if err != nil {
+		return scheduler.WorkingDay{}, err
+	}
+
+	workingDay := scheduler.WorkingDay{Start: start, End: end}
+
+	if config.Lunch != "" {
+		lunch, err := scheduler.ParseBreak(config.Lunch)
+		if err != nil {
+			return scheduler.WorkingDay{}, err
+		}
+		workingDay.Lunch = &lunch
+	}
+
+	return workingDay, workingDay.Validate()
+}
+
+// initTimezone sets the local timezone to the configured IANA timezone,
+// so that "today" and floating calendar times are in the user's timezone.
+// The system's timezone is used when none is configured.
+func initTimezone(tzName string) error {
+	if tzName == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(tzName)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", CFG_KEY_TIMEZONE, err)
+	}
+
+	time.Local = loc
+	return nil
 }
 
 func initDb(dataDir string) (*sqlx.DB, error) {
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE calendars
+    ADD COLUMN buffer_before INT NULL;
+
+ALTER TABLE calendars
+    ADD COLUMN buffer_after INT NULL;
+
+
+-- +goose Down
+ALTER TABLE calendars
+    DROP COLUMN buffer_after;
+
+ALTER TABLE calendars
+    DROP COLUMN buffer_before;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE todo_items
+    ADD COLUMN priority INT NOT NULL DEFAULT 3;
+
+-- +goose Down
+ALTER TABLE todo_items
+    DROP COLUMN priority;
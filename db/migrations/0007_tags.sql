@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE todo_item_tags (
+    todo_item_id INTEGER NOT NULL REFERENCES todo_items(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_item_id, tag_id)
+);
+
+-- +goose Down
+DROP TABLE todo_item_tags;
+DROP TABLE tags;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE todo_items
+    ADD COLUMN archived_at DATETIME NULL;
+
+-- +goose Down
+ALTER TABLE todo_items
+    DROP COLUMN archived_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE todo_items
+    ADD COLUMN recurrence TEXT NULL;
+
+-- +goose Down
+ALTER TABLE todo_items
+    DROP COLUMN recurrence;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE todo_items
+    ADD COLUMN parent_id INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE todo_items
+    DROP COLUMN parent_id;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE todo_item_dependencies (
+    todo_item_id INTEGER NOT NULL REFERENCES todo_items(id) ON DELETE CASCADE,
+    blocked_by_id INTEGER NOT NULL REFERENCES todo_items(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_item_id, blocked_by_id)
+);
+
+-- +goose Down
+DROP TABLE todo_item_dependencies;
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE todo_items
+    ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE todo_item_links (
+    id INTEGER PRIMARY KEY,
+    todo_item_id INTEGER NOT NULL REFERENCES todo_items(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    UNIQUE (todo_item_id, url)
+);
+
+-- +goose Down
+DROP TABLE todo_item_links;
+
+ALTER TABLE todo_items
+    DROP COLUMN notes;
@@ -0,0 +1,72 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultEditor = "vi"
+
+//counterfeiter:generate -o fakes/ . EditorInterface
+type EditorInterface interface {
+	// Edit lets the user edit the content, and returns what they saved
+	Edit(content string) (string, error)
+}
+
+// ExternalEditor edits content in the editor named by
+// $VISUAL or $EDITOR, or in vi when neither is set
+type ExternalEditor struct{}
+
+func NewExternalEditor() *ExternalEditor {
+	return &ExternalEditor{}
+}
+
+func (e *ExternalEditor) Edit(content string) (string, error) {
+	file, err := os.CreateTemp("", "what-next-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create file to edit: %s", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(content)
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("write file to edit: %s", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return "", fmt.Errorf("write file to edit: %s", err)
+	}
+
+	// The editor can include arguments, such as "code --wait"
+	editorCommand := strings.Fields(editorName())
+	cmd := exec.Command(editorCommand[0], append(editorCommand[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("run editor '%s': %s", editorCommand[0], err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("read edited file: %s", err)
+	}
+
+	return string(edited), nil
+}
+
+func editorName() string {
+	for _, envVar := range []string{"VISUAL", "EDITOR"} {
+		if name := strings.TrimSpace(os.Getenv(envVar)); name != "" {
+			return name
+		}
+	}
+
+	return defaultEditor
+}
@@ -0,0 +1,27 @@
+package integration_test_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("archiving and removing todo items", func() {
+	It("can archive, list, unarchive and remove items", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			Expect(exec([]string{"todo", "add", "first"})).To(Succeed())
+			Expect(exec([]string{"todo", "add", "second"})).To(Succeed())
+			Expect(exec([]string{"todo", "archive", "1", "2"})).To(Succeed())
+			Expect(exec([]string{"todo", "list", "--archived"})).To(Succeed())
+			Expect(exec([]string{"todo", "unarchive", "2"})).To(Succeed())
+			Expect(exec([]string{"todo", "remove", "1", "2"})).To(Succeed())
+		})
+	})
+
+	It("does not remove items which don't exist", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"todo", "remove", "1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no todo item with id 1"))
+		})
+	})
+})
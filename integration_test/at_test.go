@@ -0,0 +1,30 @@
+package integration_test_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("--at", func() {
+	It("accepts a date and time to run as if it were now", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"--at", "2026-10-19 09:30"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	It("can be used with subcommands", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"todo", "add", "test action", "--due", "@tomorrow", "--at", "2026-10-19 09:30"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	It("rejects times it cannot parse", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"--at", "not a time"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid --at time"))
+		})
+	})
+})
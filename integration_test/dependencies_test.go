@@ -0,0 +1,31 @@
+package integration_test_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("dependencies", func() {
+	It("can block todo items on others, and unblock them again", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			Expect(exec([]string{"todo", "add", "deploy"})).To(Succeed())
+			Expect(exec([]string{"todo", "add", "get approval"})).To(Succeed())
+			Expect(exec([]string{"todo", "block", "1", "--on", "2"})).To(Succeed())
+			Expect(exec([]string{"todo", "list"})).To(Succeed())
+			Expect(exec([]string{})).To(Succeed())
+			Expect(exec([]string{"todo", "unblock", "1", "--on", "2"})).To(Succeed())
+		})
+	})
+
+	It("can't make todo items wait on each other", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			Expect(exec([]string{"todo", "add", "deploy"})).To(Succeed())
+			Expect(exec([]string{"todo", "add", "get approval"})).To(Succeed())
+			Expect(exec([]string{"todo", "block", "1", "--on", "2"})).To(Succeed())
+
+			err := exec([]string{"todo", "block", "2", "--on", "1"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("already waiting on"))
+		})
+	})
+})
@@ -0,0 +1,65 @@
+package integration_test_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("--output", func() {
+	It("can show every view as JSON", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			Expect(exec([]string{"todo", "add", "test action", "--due", "@tomorrow", "--duration", "30m"})).To(Succeed())
+			Expect(exec([]string{"--output", "json"})).To(Succeed())
+			Expect(exec([]string{"todo", "list", "--output", "json"})).To(Succeed())
+			Expect(exec([]string{"todo", "show", "1", "-o", "json"})).To(Succeed())
+			Expect(exec([]string{"plan", "-o", "json"})).To(Succeed())
+			Expect(exec([]string{"calendar", "list", "-o", "json"})).To(Succeed())
+		})
+	})
+
+	It("rejects formats it doesn't know", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"todo", "list", "--output", "xml"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid --output"))
+		})
+	})
+})
+
+var _ = Describe("--output template", func() {
+	It("shows views with a template file", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			templateFile := filepath.Join(cfg.DataDir, "list.tmpl")
+			Expect(os.WriteFile(templateFile, []byte(`{{ range .Enumerate }}{{ .Id }} {{ .Action }} {{ due .DueDate }}{{ "\n" }}{{ end }}`), 0644)).To(Succeed())
+
+			Expect(exec([]string{"todo", "add", "test action", "--due", "@tomorrow"})).To(Succeed())
+			Expect(exec([]string{"todo", "list", "--output", "template", "--template-file", templateFile})).To(Succeed())
+			Expect(exec([]string{"todo", "list", "--template-file", templateFile})).To(Succeed())
+		})
+	})
+
+	It("shows views with a named template from the templates directory", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			Expect(os.MkdirAll(filepath.Join(cfg.DataDir, "templates"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cfg.DataDir, "templates", "compact.tmpl"), []byte(`{{ .Action }}`), 0644)).To(Succeed())
+
+			Expect(exec([]string{"todo", "add", "test action"})).To(Succeed())
+			Expect(exec([]string{"todo", "show", "1", "--template", "compact"})).To(Succeed())
+
+			err := exec([]string{"todo", "show", "1", "--template", "missing"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("there is no template named 'missing'"))
+		})
+	})
+
+	It("needs a template to be given", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"todo", "list", "--output", "template"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("needs a --template-file or --template"))
+		})
+	})
+})
@@ -0,0 +1,26 @@
+package integration_test_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tags", func() {
+	It("can be added to todo items, and used to filter them", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			Expect(exec([]string{"todo", "add", "test action", "--tag", "@laptop,+release"})).To(Succeed())
+			Expect(exec([]string{"todo", "tag", "1", "@phone"})).To(Succeed())
+			Expect(exec([]string{"todo", "untag", "1", "@laptop"})).To(Succeed())
+			Expect(exec([]string{"todo", "list", "--tag", "@phone", "--exclude-tag", "+release"})).To(Succeed())
+			Expect(exec([]string{"--tag", "@phone"})).To(Succeed())
+		})
+	})
+
+	It("can't be added to todo items which don't exist", func() {
+		RunIntegrationTest(func(exec Executor, cfg *testConfig) {
+			err := exec([]string{"todo", "tag", "1", "@phone"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no todo item with id 1"))
+		})
+	})
+})
@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"time"
+
+	ical "github.com/arran4/golang-ical"
+)
+
+// Buffers are time kept free around meetings, for getting
+// ready before they start and wrapping up after they end
+type Buffers struct {
+	Before time.Duration
+	After  time.Duration
+}
+
+// buffersFor returns the buffers for the meetings in cal,
+// which are the global buffers unless it has its own
+func (o Options) buffersFor(cal *ical.Calendar) Buffers {
+	if buffers, ok := o.CalendarBuffers[cal]; ok {
+		return buffers
+	}
+
+	return o.Buffers
+}
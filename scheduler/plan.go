@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/todo"
+	ical "github.com/arran4/golang-ical"
+	"golang.org/x/exp/slices"
+)
+
+// PlanEntry is a block of time in a plan. It is taken up by either
+// a calendar event, a task from the todo list or lunch, or it is
+// free time when none of them are set.
+//
+// Late is set when the task is planned to finish after it's due.
+type PlanEntry struct {
+	Start time.Time
+	End   time.Time
+	Event *ical.VEvent
+	Task  *todo.TodoItem
+	Lunch bool
+	Late  bool
+}
+
+func (e PlanEntry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+type Plan struct {
+	// Now is the time the plan was generated at, which
+	// is before it starts when it's made before work
+	Now              time.Time
+	Start            time.Time
+	End              time.Time
+	Entries          []PlanEntry
+	AllDayEvents     []*ical.VEvent
+	UnscheduledTasks todo.TodoItemCollection
+}
+
+// GeneratePlan takes todays calendars and a todo list and plans
+// the time between now and the end of the day, by
+// * placing the calendar events in the time they take up
+// * assigning tasks from the todo list to the free time between them
+// * leaving whatever free time remains after that
+//
+// Tasks are assigned in the order given by RankTasks, each to the
+// earliest free time it fits in to, which is when it finishes soonest.
+// Tasks which would still finish after they're due, such as those which
+// are already overdue, are marked as late. No two tasks are assigned the
+// same time.
+// Tasks without a duration, and tasks which don't fit in any free
+// time, are left as unscheduled tasks. Tasks with subtasks which
+// aren't complete are left out, in favour of their subtasks, and so
+// are tasks which are blocked by other tasks.
+//
+// All day events don't take up any time in the plan. The buffers
+// around other events are kept free of tasks, including the buffer
+// after an event which has just ended.
+//
+// When there are working hours, the plan starts no earlier than the
+// start of the working day, ends at the end of it, and includes lunch.
+// Nothing is planned on days which aren't working days.
+func GeneratePlan(now time.Time, calendars []*ical.Calendar, todoList *todo.TodoItemCollection, options Options) (*Plan, error) {
+	start := now
+	end := calendar.StartOfDay(now).AddDate(0, 0, 1)
+	busy := []PlanEntry{}
+
+	if options.WorkingHours != nil {
+		workingDay, isWorkingDay := options.WorkingHours.On(now)
+		if isWorkingDay {
+			dayStart, dayEnd := workingDay.Bounds(now)
+			start = latest(now, dayStart)
+			end = dayEnd
+
+			lunchStart, lunchEnd, hasLunch := workingDay.LunchBounds(now)
+			if hasLunch && lunchEnd.After(start) {
+				busy = append(busy, PlanEntry{Start: latest(lunchStart, start), End: lunchEnd, Lunch: true})
+			}
+		}
+
+		if !isWorkingDay || end.Before(start) {
+			end = start
+		}
+	}
+
+	plan := &Plan{
+		Now:              now,
+		Start:            start,
+		End:              end,
+		Entries:          []PlanEntry{},
+		AllDayEvents:     []*ical.VEvent{},
+		UnscheduledTasks: todo.TodoItemCollection{},
+	}
+
+	// blocked is the time tasks can't be assigned to, which
+	// is the busy time plus the buffers around events
+	blocked := append([]PlanEntry{}, busy...)
+
+	events, eventBuffers := eventsOnDay(calendar.StartOfDay(now), calendars, options)
+	for _, event := range events {
+		isAllDay, err := calendar.IsAllDayEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		if isAllDay {
+			plan.AllDayEvents = append(plan.AllDayEvents, event)
+			continue
+		}
+
+		eventStart, eventEnd, err := calendar.EventStartAndEnd(event)
+		if err != nil {
+			return nil, err
+		}
+
+		// The buffer after an event which has already
+		// ended can still be keeping the start of the plan free
+		buffers := eventBuffers[event]
+		blockedStart, blockedEnd := eventStart.Add(-buffers.Before), eventEnd.Add(buffers.After)
+		if blockedEnd.After(start) && blockedStart.Before(end) {
+			blocked = append(blocked, PlanEntry{Start: blockedStart, End: blockedEnd})
+		}
+
+		if eventEnd.After(start) && eventStart.Before(end) {
+			busy = append(busy, PlanEntry{Start: eventStart, End: eventEnd, Event: event})
+		}
+	}
+
+	slices.SortFunc(busy, func(a PlanEntry, b PlanEntry) bool {
+		return a.Start.Before(b.Start)
+	})
+
+	slices.SortFunc(blocked, func(a PlanEntry, b PlanEntry) bool {
+		return a.Start.Before(b.Start)
+	})
+
+	plan.Entries = append(plan.Entries, busy...)
+
+	free := freeTime(start, end, blocked)
+
+	tasksForConsideration := RankTasks(todoList.Leaves().Filter(todo.IsNotBlocked).Filter(func(ti *todo.TodoItem) bool {
+		return ti.Completed == false
+	}), now, nil)
+
+	unscheduled := []*todo.TodoItem{}
+	for _, task := range tasksForConsideration.Enumerate() {
+		if task.Duration == nil {
+			unscheduled = append(unscheduled, task)
+			continue
+		}
+
+		slot := slices.IndexFunc(free, func(gap PlanEntry) bool {
+			return gap.Duration() >= *task.Duration
+		})
+
+		if slot < 0 {
+			unscheduled = append(unscheduled, task)
+			continue
+		}
+
+		taskStart := free[slot].Start
+		taskEnd := taskStart.Add(*task.Duration)
+		late := task.DueDate != nil && taskEnd.After(*task.DueDate)
+		plan.Entries = append(plan.Entries, PlanEntry{Start: taskStart, End: taskEnd, Task: task, Late: late})
+		free[slot].Start = taskEnd
+	}
+
+	for _, gap := range free {
+		if gap.Duration() > 0 {
+			plan.Entries = append(plan.Entries, gap)
+		}
+	}
+
+	slices.SortStableFunc(plan.Entries, func(a PlanEntry, b PlanEntry) bool {
+		return a.Start.Before(b.Start)
+	})
+
+	plan.UnscheduledTasks = *todo.NewTodoItemCollection(unscheduled)
+
+	return plan, nil
+}
+
+// freeTime finds the gaps between from and to which aren't
+// taken up by any of the busy entries, which must be sorted
+// by their start times
+func freeTime(from time.Time, to time.Time, busy []PlanEntry) []PlanEntry {
+	free := []PlanEntry{}
+	cursor := from
+
+	for _, entry := range busy {
+		if entry.Start.After(cursor) {
+			gapEnd := entry.Start
+			if gapEnd.After(to) {
+				gapEnd = to
+			}
+			free = append(free, PlanEntry{Start: cursor, End: gapEnd})
+		}
+
+		if entry.End.After(cursor) {
+			cursor = entry.End
+		}
+
+		if !cursor.Before(to) {
+			return free
+		}
+	}
+
+	if cursor.Before(to) {
+		free = append(free, PlanEntry{Start: cursor, End: to})
+	}
+
+	return free
+}
@@ -0,0 +1,249 @@
+package scheduler_test
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func entryTimes(plan *scheduler.Plan) [][2]time.Time {
+	times := [][2]time.Time{}
+	for _, entry := range plan.Entries {
+		times = append(times, [2]time.Time{entry.Start.Local(), entry.End.Local()})
+	}
+	return times
+}
+
+func taskEntry(plan *scheduler.Plan, task *todo.TodoItem) *scheduler.PlanEntry {
+	for _, entry := range plan.Entries {
+		if entry.Task == task {
+			e := entry
+			return &e
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Plan", func() {
+	now := time.Date(2022, time.October, 17, 12, 0, 0, 0, time.Local)
+	options := scheduler.Options{
+		WorkingHours: scheduler.WorkingHours{
+			time.Monday: {Start: scheduler.TimeOfDay{Hour: 9}, End: scheduler.TimeOfDay{Hour: 17}},
+		},
+	}
+
+	Describe("GeneratePlan", func() {
+		var (
+			cal           *ical.Calendar
+			lunch         *ical.VEvent
+			review        *ical.VEvent
+			planning      *ical.VEvent
+			emptyTodoList *todo.TodoItemCollection
+		)
+
+		BeforeEach(func() {
+			lunch = newEvent(now, "30m", "30m")
+			planning = newEvent(now, "1h45m", "45m")
+			review = newEvent(now, "2h", "1h")
+			cal = generateCalendar(lunch, review, planning)
+			emptyTodoList = todo.NewTodoItemCollection([]*todo.TodoItem{})
+		})
+
+		It("contains the calendar events between now and the end of the day, in order", func() {
+			yesterday := newEvent(now, "-24h", "1h")
+			afterEnd := newEvent(now, "5h30m", "30m")
+			cal.AddVEvent(yesterday)
+			cal.AddVEvent(afterEnd)
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, emptyTodoList, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			events := []*ical.VEvent{}
+			for _, entry := range plan.Entries {
+				if entry.Event != nil {
+					events = append(events, entry.Event)
+				}
+			}
+
+			Expect(events).To(Equal([]*ical.VEvent{lunch, planning, review}))
+		})
+
+		It("fills the time between events with free time, when there are no tasks", func() {
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, emptyTodoList, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			at := func(hour int, minute int) time.Time {
+				return time.Date(2022, time.October, 17, hour, minute, 0, 0, time.Local)
+			}
+
+			Expect(entryTimes(plan)).To(Equal([][2]time.Time{
+				{at(12, 0), at(12, 30)},
+				{at(12, 30), at(13, 0)},
+				{at(13, 0), at(13, 45)},
+				{at(13, 45), at(14, 30)},
+				{at(14, 0), at(15, 0)},
+				{at(15, 0), at(17, 0)},
+			}))
+			Expect(plan.Entries[0].Event).To(BeNil())
+			Expect(plan.Entries[0].Task).To(BeNil())
+		})
+
+		It("assigns tasks to the earliest free time they fit in, in order of due date, without overlapping", func() {
+			tomorrow := now.AddDate(0, 0, 1)
+
+			urgent := taskWithDuration(25 * time.Minute)
+			urgent.Id = 10
+			urgent.DueDate = &tomorrow
+
+			long := taskWithDuration(time.Hour)
+			long.Id = 1
+
+			short := taskWithDuration(40 * time.Minute)
+			short.Id = 2
+
+			tasks := todo.NewTodoItemCollection([]*todo.TodoItem{short, long, urgent})
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, tasks, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(taskEntry(plan, urgent).Start).To(BeTemporally("==", now))
+			Expect(taskEntry(plan, long).Start).To(BeTemporally("==", time.Date(2022, time.October, 17, 15, 0, 0, 0, time.Local)))
+			Expect(taskEntry(plan, short).Start).To(BeTemporally("==", time.Date(2022, time.October, 17, 13, 0, 0, 0, time.Local)))
+			Expect(taskEntry(plan, short).End).To(BeTemporally("==", time.Date(2022, time.October, 17, 13, 40, 0, 0, time.Local)))
+		})
+
+		It("marks tasks as late when the only free time they fit in ends after they're due", func() {
+			dueAt1330 := time.Date(2022, time.October, 17, 13, 30, 0, 0, time.Local)
+
+			dueSoon := taskWithDuration(time.Hour)
+			dueSoon.Priority = todo.PriorityHighest
+			dueSoon.DueDate = &dueAt1330
+
+			tasks := todo.NewTodoItemCollection([]*todo.TodoItem{dueSoon})
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, tasks, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			entry := taskEntry(plan, dueSoon)
+			Expect(entry.Start).To(BeTemporally("==", time.Date(2022, time.October, 17, 15, 0, 0, 0, time.Local)))
+			Expect(entry.Late).To(BeTrue())
+		})
+
+		It("marks tasks which are already overdue as late", func() {
+			yesterday := now.AddDate(0, 0, -1)
+
+			overdue := taskWithDuration(10 * time.Minute)
+			overdue.DueDate = &yesterday
+
+			tasks := todo.NewTodoItemCollection([]*todo.TodoItem{overdue})
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, tasks, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(taskEntry(plan, overdue).Start).To(BeTemporally("==", now))
+			Expect(taskEntry(plan, overdue).Late).To(BeTrue())
+		})
+
+		It("leaves tasks which don't fit, or don't have a duration, unscheduled", func() {
+			tooLong := taskWithDuration(3 * time.Hour)
+			noDuration := taskWithoutDuration()
+
+			tasks := todo.NewTodoItemCollection([]*todo.TodoItem{tooLong, noDuration})
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, tasks, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(taskEntry(plan, tooLong)).To(BeNil())
+			Expect(taskEntry(plan, noDuration)).To(BeNil())
+			Expect(plan.UnscheduledTasks.Enumerate()).To(ConsistOf(tooLong, noDuration))
+		})
+
+		It("does not contain completed tasks", func() {
+			completed := taskWithDuration(10 * time.Minute)
+			completed.Completed = true
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{completed}), options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(taskEntry(plan, completed)).To(BeNil())
+			Expect(plan.UnscheduledTasks.Enumerate()).ToNot(ContainElement(completed))
+		})
+
+		It("plans until midnight when there are no working hours", func() {
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, emptyTodoList, scheduler.Options{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.End).To(Equal(time.Date(2022, time.October, 18, 0, 0, 0, 0, time.Local)))
+			Expect(plan.Entries[len(plan.Entries)-1].Start).To(BeTemporally("==", time.Date(2022, time.October, 17, 15, 0, 0, 0, time.Local)))
+		})
+
+		It("starts at the start of the working day when planning before it", func() {
+			early := time.Date(2022, time.October, 17, 7, 0, 0, 0, time.Local)
+
+			plan, err := scheduler.GeneratePlan(early, []*ical.Calendar{}, emptyTodoList, options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Start).To(Equal(time.Date(2022, time.October, 17, 9, 0, 0, 0, time.Local)))
+		})
+
+		It("does not assign tasks to lunch", func() {
+			lunch := scheduler.Break{Start: scheduler.TimeOfDay{Hour: 12}, End: scheduler.TimeOfDay{Hour: 12, Minute: 30}}
+			withLunch := scheduler.Options{
+				WorkingHours: scheduler.WorkingHours{
+					time.Monday: {Start: scheduler.TimeOfDay{Hour: 9}, End: scheduler.TimeOfDay{Hour: 17}, Lunch: &lunch},
+				},
+			}
+			task := taskWithDuration(10 * time.Minute)
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{}, todo.NewTodoItemCollection([]*todo.TodoItem{task}), withLunch)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Entries[0].Lunch).To(BeTrue())
+			Expect(plan.Entries[0].End).To(Equal(time.Date(2022, time.October, 17, 12, 30, 0, 0, time.Local)))
+			Expect(taskEntry(plan, task).Start).To(Equal(time.Date(2022, time.October, 17, 12, 30, 0, 0, time.Local)))
+		})
+
+		It("does not plan anything on days which aren't working days", func() {
+			tuesday := now.AddDate(0, 0, 1)
+			task := taskWithDuration(10 * time.Minute)
+
+			plan, err := scheduler.GeneratePlan(tuesday, []*ical.Calendar{}, todo.NewTodoItemCollection([]*todo.TodoItem{task}), options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Entries).To(BeEmpty())
+			Expect(plan.UnscheduledTasks.Enumerate()).To(ConsistOf(task))
+		})
+
+		It("keeps the buffer after the last meeting of the day free, even once it has ended", func() {
+			withBuffers := options
+			withBuffers.Buffers = scheduler.Buffers{After: 10 * time.Minute}
+			cal := generateCalendar(newEvent(now, "-1h", "55m"))
+			task := taskWithDuration(time.Hour)
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{task}), withBuffers)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.Entries[0].Start).To(BeTemporally("==", now.Add(5*time.Minute)))
+			Expect(taskEntry(plan, task).Start).To(BeTemporally("==", now.Add(5*time.Minute)))
+		})
+
+		It("does not let all day events take up time", func() {
+			holiday := ical.NewEvent("holiday")
+			holiday.SetProperty(ical.ComponentPropertyDtStart, now.Format("20060102"), ical.WithValue(string(ical.ValueDataTypeDate)))
+			holiday.SetProperty(ical.ComponentPropertyDtEnd, now.AddDate(0, 0, 1).Format("20060102"), ical.WithValue(string(ical.ValueDataTypeDate)))
+			cal := generateCalendar(holiday)
+
+			task := taskWithDuration(time.Hour)
+
+			plan, err := scheduler.GeneratePlan(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{task}), options)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(plan.AllDayEvents).To(ConsistOf(holiday))
+			Expect(taskEntry(plan, task).Start).To(BeTemporally("==", now))
+		})
+	})
+})
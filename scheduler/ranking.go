@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/todo"
+)
+
+// How much each factor counts towards a task's rank
+const (
+	priorityWeight = 0.5
+	urgencyWeight  = 0.35
+	fitWeight      = 0.15
+)
+
+// RankTasks orders tasks so that the one which should be done next comes
+// first. Each task is scored on
+// * its priority
+// * how soon it is due, with overdue tasks being the most urgent
+// * how much of the free time it would use, so that the time isn't wasted
+//
+// Tasks with the same score are kept in order of their due dates.
+// freeTime can be nil when the free time isn't limited.
+func RankTasks(tasks *todo.TodoItemCollection, now time.Time, freeTime *time.Duration) *todo.TodoItemCollection {
+	scores := map[*todo.TodoItem]float64{}
+	for _, task := range tasks.Enumerate() {
+		scores[task] = TaskScore(task, now, freeTime)
+	}
+
+	return tasks.SortByDueDateAsc().SortStable(func(a *todo.TodoItem, b *todo.TodoItem) bool {
+		return scores[a] > scores[b]
+	})
+}
+
+// TaskScore scores a task between 0 and 1, where higher
+// scores mean the task should be done sooner
+func TaskScore(task *todo.TodoItem, now time.Time, freeTime *time.Duration) float64 {
+	return priorityWeight*priorityScore(task) +
+		urgencyWeight*urgencyScore(task, now) +
+		fitWeight*fitScore(task, freeTime)
+}
+
+func priorityScore(task *todo.TodoItem) float64 {
+	priority := task.Priority
+	if !priority.IsValid() {
+		priority = todo.DefaultPriority
+	}
+
+	return float64(todo.PriorityLowest-priority) / float64(todo.PriorityLowest-todo.PriorityHighest)
+}
+
+// urgencyScore is 1 for tasks which are overdue or due now, and
+// falls away the more days there are until the task is due
+func urgencyScore(task *todo.TodoItem, now time.Time) float64 {
+	if task.DueDate == nil {
+		return 0
+	}
+
+	daysUntilDue := task.DueDate.Sub(now).Hours() / 24
+	if daysUntilDue < 0 {
+		daysUntilDue = 0
+	}
+
+	return 1 / (1 + daysUntilDue)
+}
+
+// fitScore is the share of the free time a task would use
+func fitScore(task *todo.TodoItem, freeTime *time.Duration) float64 {
+	if task.Duration == nil || freeTime == nil || *freeTime <= 0 {
+		return 0
+	}
+
+	fit := float64(*task.Duration) / float64(*freeTime)
+	if fit > 1 {
+		return 0
+	}
+
+	return fit
+}
@@ -0,0 +1,72 @@
+package scheduler_test
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func actions(tasks *todo.TodoItemCollection) []string {
+	result := []string{}
+	for _, task := range tasks.Enumerate() {
+		result = append(result, task.Action)
+	}
+	return result
+}
+
+var _ = Describe("Ranking", func() {
+	now := time.Date(2022, time.October, 17, 12, 0, 0, 0, time.Local)
+
+	Describe("RankTasks", func() {
+		It("puts higher priority tasks first", func() {
+			low := &todo.TodoItem{Id: 1, Action: "low", Priority: todo.PriorityLow}
+			high := &todo.TodoItem{Id: 2, Action: "high", Priority: todo.PriorityHigh}
+			medium := &todo.TodoItem{Id: 3, Action: "medium", Priority: todo.PriorityMedium}
+
+			ranked := scheduler.RankTasks(todo.NewTodoItemCollection([]*todo.TodoItem{low, high, medium}), now, nil)
+
+			Expect(actions(ranked)).To(Equal([]string{"high", "medium", "low"}))
+		})
+
+		It("puts tasks which are due sooner first, when they have the same priority", func() {
+			nextWeek := now.AddDate(0, 0, 7)
+			tomorrow := now.AddDate(0, 0, 1)
+
+			later := &todo.TodoItem{Id: 1, Action: "later", Priority: todo.PriorityMedium, DueDate: &nextWeek}
+			sooner := &todo.TodoItem{Id: 2, Action: "sooner", Priority: todo.PriorityMedium, DueDate: &tomorrow}
+			whenever := &todo.TodoItem{Id: 3, Action: "whenever", Priority: todo.PriorityMedium}
+
+			ranked := scheduler.RankTasks(todo.NewTodoItemCollection([]*todo.TodoItem{whenever, later, sooner}), now, nil)
+
+			Expect(actions(ranked)).To(Equal([]string{"sooner", "later", "whenever"}))
+		})
+
+		It("lets an overdue task outrank a higher priority task which isn't due", func() {
+			yesterday := now.AddDate(0, 0, -1)
+
+			important := &todo.TodoItem{Id: 1, Action: "important", Priority: todo.PriorityHighest}
+			overdue := &todo.TodoItem{Id: 2, Action: "overdue", Priority: todo.PriorityMedium, DueDate: &yesterday}
+
+			ranked := scheduler.RankTasks(todo.NewTodoItemCollection([]*todo.TodoItem{important, overdue}), now, nil)
+
+			Expect(actions(ranked)).To(Equal([]string{"overdue", "important"}))
+		})
+
+		It("puts tasks which make better use of the free time first, when they are otherwise equal", func() {
+			freeTime := 30 * time.Minute
+			short := taskWithDuration(5 * time.Minute)
+			short.Action = "short"
+			short.Priority = todo.PriorityMedium
+			fits := taskWithDuration(25 * time.Minute)
+			fits.Action = "fits"
+			fits.Priority = todo.PriorityMedium
+
+			ranked := scheduler.RankTasks(todo.NewTodoItemCollection([]*todo.TodoItem{short, fits}), now, &freeTime)
+
+			Expect(actions(ranked)).To(Equal([]string{"fits", "short"}))
+		})
+	})
+})
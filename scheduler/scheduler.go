@@ -10,10 +10,56 @@ import (
 )
 
 type Schedule struct {
+	// Now is the time the schedule was generated for
+	Now time.Time
+
 	CurrentCalendarEvents      []*ical.VEvent
 	NextCalendarEvents         []*ical.VEvent
 	TimeUntilNextCalendarEvent *time.Duration
 	AchievableTasks            todo.TodoItemCollection
+
+	// UsableTimeUntilNextCalendarEvent is the time until the next
+	// event, less the buffer before it and what remains of the
+	// buffer after any event which has just ended
+	UsableTimeUntilNextCalendarEvent *time.Duration
+
+	// IsWorkingTime is false outside of working hours and during
+	// lunch. It is always true when there are no working hours.
+	IsWorkingTime bool
+
+	// The working hours fields are only set when there are working hours.
+	// The time left in the working day and until lunch are counted from
+	// the end of the buffer after any event which has just ended.
+	EndOfWorkingDay      *time.Time
+	TimeLeftInWorkingDay *time.Duration
+	TimeUntilLunch       *time.Duration
+}
+
+// Options change how schedules and plans are generated
+type Options struct {
+	// WorkingHours limit tasks to being scheduled during working
+	// time. When nil, tasks can be scheduled at any time of day.
+	WorkingHours WorkingHours
+
+	// Buffers are kept free around every meeting, except those in
+	// calendars which have their own buffers in CalendarBuffers
+	Buffers         Buffers
+	CalendarBuffers map[*ical.Calendar]Buffers
+}
+
+// FreeTime is the usable time from now until the next meeting, lunch, or
+// the end of the working day, whichever comes first. It is nil when there
+// is nothing left in the day to limit it.
+func (s *Schedule) FreeTime() *time.Duration {
+	var freeTime *time.Duration = nil
+
+	for _, limit := range []*time.Duration{s.UsableTimeUntilNextCalendarEvent, s.TimeUntilLunch, s.TimeLeftInWorkingDay} {
+		if limit != nil && (freeTime == nil || *limit < *freeTime) {
+			freeTime = limit
+		}
+	}
+
+	return freeTime
 }
 
 // GenerateSchedule takes todays calendar and a todo list
@@ -22,33 +68,37 @@ type Schedule struct {
 // * the next calendar event after that
 // * the time until that event
 // * which tasks from the todo list are achievable in that time
-func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.TodoItemCollection) (*Schedule, error) {
+//
+// Achievable tasks are ranked by RankTasks, best first. Tasks with
+// subtasks which aren't complete are never achievable themselves,
+// because their work is done through the subtasks. Neither are tasks
+// which are blocked by other tasks.
+//
+// Recurring events in the calendars are expanded in to their
+// occurrences for today before they are considered.
+//
+// The time for tasks ends at the buffer before the next event, and
+// doesn't begin until the buffer after any event which has just ended,
+// even when there are no more events that day.
+//
+// When there are working hours, the time for tasks also ends at lunch and
+// at the end of the working day, and no tasks are achievable outside them.
+func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.TodoItemCollection, options Options) (*Schedule, error) {
 	schedule := &Schedule{
+		Now:                        now,
 		CurrentCalendarEvents:      []*ical.VEvent{},
 		NextCalendarEvents:         []*ical.VEvent{},
 		TimeUntilNextCalendarEvent: nil,
 		AchievableTasks:            todo.TodoItemCollection{},
+		IsWorkingTime:              true,
 	}
 
-	allEvents := []*ical.VEvent{}
-	for _, cal := range calendars {
-		for _, e := range cal.Events() {
-			allEvents = append(allEvents, e)
-		}
-	}
+	eventsForConsideration, eventBuffers := eventsOnDay(calendar.StartOfDay(now), calendars, options)
 
-	eventsForConsideration := calendar.FilterEvents(allEvents, func(evt *ical.VEvent) bool {
-		startsToday, err := calendar.EventStartsToday(evt)
-		endsToday, err := calendar.EventEndsToday(evt)
-
-		if err != nil {
-			return false
-		}
-
-		isRecurringDef := calendar.IsRecurringEventDefinition(evt)
-
-		return (startsToday || endsToday) && !isRecurringDef
-	})
+	// Time for tasks starts after the buffers following
+	// any events, other than all day events, which have
+	// started by now
+	usableFrom := now
 
 	for _, event := range eventsForConsideration {
 		isHappening, err := calendar.EventIsCurrentlyHappening(event, now)
@@ -60,11 +110,25 @@ func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.
 		if isHappening {
 			schedule.CurrentCalendarEvents = append(schedule.CurrentCalendarEvents, event)
 		}
+
+		isAllDay, err := calendar.IsAllDayEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		start, end, err := calendar.EventStartAndEnd(event)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isAllDay && !start.After(now) {
+			usableFrom = latest(usableFrom, end.Add(eventBuffers[event].After))
+		}
 	}
 
 	eventsStartingAfterNow := []*ical.VEvent{}
 	for _, event := range eventsForConsideration {
-		start, err := event.GetStartAt()
+		start, _, err := calendar.EventStartAndEnd(event)
 		if err != nil {
 			return nil, err
 		}
@@ -74,7 +138,7 @@ func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.
 		}
 	}
 
-	tasksForConsideration := todoList.Filter(func(ti *todo.TodoItem) bool {
+	tasksForConsideration := todoList.Leaves().Filter(todo.IsNotBlocked).Filter(func(ti *todo.TodoItem) bool {
 		return ti.Completed == false
 	})
 
@@ -88,13 +152,13 @@ func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.
 			return nil, err
 		}
 		nextStartingEvent := eventsStartingAfterNow[0]
-		nextEventStartTime, err := nextStartingEvent.GetStartAt()
+		nextEventStartTime, _, err := calendar.EventStartAndEnd(nextStartingEvent)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, event := range eventsStartingAfterNow {
-			start, err := event.GetStartAt()
+			start, _, err := calendar.EventStartAndEnd(event)
 			if err != nil {
 				return nil, err
 			}
@@ -107,8 +171,30 @@ func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.
 		timeUntilNextEvent := nextEventStartTime.Sub(now)
 		schedule.TimeUntilNextCalendarEvent = &timeUntilNextEvent
 
+		usableUntil := nextEventStartTime
+		for _, event := range schedule.NextCalendarEvents {
+			if usableUntil.After(nextEventStartTime.Add(-eventBuffers[event].Before)) {
+				usableUntil = nextEventStartTime.Add(-eventBuffers[event].Before)
+			}
+		}
+
+		usableTimeUntilNextEvent := time.Duration(0)
+		if usableUntil.After(usableFrom) {
+			usableTimeUntilNextEvent = usableUntil.Sub(usableFrom)
+		}
+		schedule.UsableTimeUntilNextCalendarEvent = &usableTimeUntilNextEvent
+	}
+
+	if options.WorkingHours != nil {
+		schedule.applyWorkingHours(now, usableFrom, options.WorkingHours)
+	}
+
+	freeTime := schedule.FreeTime()
+	if !schedule.IsWorkingTime {
+		schedule.AchievableTasks = todo.TodoItemCollection{}
+	} else if freeTime != nil {
 		achievableTasksWithinDuration := *tasksForConsideration.Filter(func(ti *todo.TodoItem) bool {
-			return ti.Duration != nil && *ti.Duration <= timeUntilNextEvent
+			return ti.Duration != nil && *ti.Duration <= *freeTime
 		})
 
 		schedule.AchievableTasks = *achievableTasksWithinDuration.Append(&tasksWithoutDurationSet)
@@ -116,21 +202,90 @@ func GenerateSchedule(now time.Time, calendars []*ical.Calendar, todoList *todo.
 		schedule.AchievableTasks = *tasksForConsideration
 	}
 
-	schedule.AchievableTasks = *schedule.AchievableTasks.SortByDueDateAsc()
+	schedule.AchievableTasks = *RankTasks(&schedule.AchievableTasks, now, freeTime)
 
 	return schedule, nil
 }
 
+// applyWorkingHours sets the time left in the working day, and until
+// lunch, which are both counted from when the time for tasks begins
+func (s *Schedule) applyWorkingHours(now time.Time, usableFrom time.Time, workingHours WorkingHours) {
+	timeLeft := time.Duration(0)
+	s.TimeLeftInWorkingDay = &timeLeft
+
+	workingDay, isWorkingDay := workingHours.On(now)
+	if !isWorkingDay {
+		s.IsWorkingTime = false
+		return
+	}
+
+	s.IsWorkingTime = workingDay.IsWorkingTime(now)
+
+	start, end := workingDay.Bounds(now)
+	s.EndOfWorkingDay = &end
+
+	if usableFrom.Before(end) {
+		timeLeft = end.Sub(latest(usableFrom, start))
+	}
+
+	lunchStart, _, hasLunch := workingDay.LunchBounds(now)
+	if hasLunch && now.Before(lunchStart) {
+		timeUntilLunch := time.Duration(0)
+		if usableFrom.Before(lunchStart) {
+			timeUntilLunch = lunchStart.Sub(usableFrom)
+		}
+		s.TimeUntilLunch = &timeUntilLunch
+	}
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+// eventsOnDay finds the events in all calendars that start or end on
+// the day beginning at the given midnight, including the occurrences
+// of recurring events on that day, along with the buffers around each
+// of them
+func eventsOnDay(day time.Time, calendars []*ical.Calendar, options Options) ([]*ical.VEvent, map[*ical.VEvent]Buffers) {
+	allEvents := []*ical.VEvent{}
+	eventBuffers := map[*ical.VEvent]Buffers{}
+	for _, cal := range calendars {
+		buffers := options.buffersFor(cal)
+		for _, e := range calendar.ExpandRecurringEvents(cal.Events(), day, day.AddDate(0, 0, 1)) {
+			allEvents = append(allEvents, e)
+			eventBuffers[e] = buffers
+		}
+	}
+
+	return calendar.FilterEvents(allEvents, func(evt *ical.VEvent) bool {
+		startsOnDay, err := calendar.EventStartsOnDay(evt, day)
+		if err != nil {
+			return false
+		}
+
+		endsOnDay, err := calendar.EventEndsOnDay(evt, day)
+		if err != nil {
+			return false
+		}
+
+		return startsOnDay || endsOnDay
+	}), eventBuffers
+}
+
 func sortEventsByStartTimeAsc(events []*ical.VEvent) error {
 	var err error = nil
 	slices.SortFunc(events, func(a *ical.VEvent, b *ical.VEvent) bool {
-		aStart, e := a.GetStartAt()
+		aStart, _, e := calendar.EventStartAndEnd(a)
 		if e != nil {
 			err = e
 			return false
 		}
 
-		bStart, e := b.GetStartAt()
+		bStart, _, e := calendar.EventStartAndEnd(b)
 		if e != nil {
 			err = e
 			return false
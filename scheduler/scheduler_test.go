@@ -35,7 +35,7 @@ func newEvent(now time.Time, relativeStartTime string, duration string) *ical.VE
 }
 
 func randomEventNotHappeningNow(now time.Time) *ical.VEvent {
-	midnightOfNow := now.Truncate(24 * time.Hour)
+	midnightOfNow := calendar.StartOfDay(now)
 	endOfDay := midnightOfNow.Add(24 * time.Hour)
 
 	var event *ical.VEvent
@@ -99,7 +99,7 @@ func taskWithoutDuration() *todo.TodoItem {
 }
 
 var _ = Describe("Scheduler", func() {
-	now := time.Now()
+	now := time.Date(2022, time.October, 17, 12, 0, 0, 0, time.Local)
 	Describe("GenerateSchedule", func() {
 		Context("when 'now' falls within a calendar event", func() {
 			It("the schedule contains that event in the CurrentCalendarEvents field", func() {
@@ -113,7 +113,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.CurrentCalendarEvents).To(ContainElement(currentEvent))
@@ -136,7 +136,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.CurrentCalendarEvents).To(ContainElement(eventOne))
@@ -155,7 +155,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.NextCalendarEvents).To(ContainElement(nextEvent))
@@ -172,7 +172,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.NextCalendarEvents).To(ContainElement(nextEvent))
@@ -191,7 +191,7 @@ var _ = Describe("Scheduler", func() {
 
 					todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-					schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+					schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(schedule.NextCalendarEvents).To(ContainElement(nextEvent))
@@ -208,7 +208,7 @@ var _ = Describe("Scheduler", func() {
 
 					todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-					schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+					schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(schedule.NextCalendarEvents).To(BeEmpty())
@@ -225,7 +225,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.TimeUntilNextCalendarEvent).ToNot(BeNil())
@@ -251,7 +251,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.NextCalendarEvents).To(HaveLen(0))
@@ -266,7 +266,7 @@ var _ = Describe("Scheduler", func() {
 
 				todoList := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todoList, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.TimeUntilNextCalendarEvent).To(BeNil())
@@ -288,7 +288,7 @@ var _ = Describe("Scheduler", func() {
 					otherwiseAchievableTask,
 				})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.AchievableTasks.Enumerate()).ToNot(ContainElement(otherwiseAchievableTask))
@@ -303,7 +303,7 @@ var _ = Describe("Scheduler", func() {
 					taskShortEnough,
 				})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.AchievableTasks.Enumerate()).To(ContainElement(taskShortEnough))
@@ -319,7 +319,7 @@ var _ = Describe("Scheduler", func() {
 					taskShortEnough,
 				})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.AchievableTasks.Enumerate()).To(ContainElement(taskHasNoDuration))
@@ -340,7 +340,7 @@ var _ = Describe("Scheduler", func() {
 					taskWithDueDateInTheFuture,
 				})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.AchievableTasks.Enumerate()).To(ContainElement(taskWithDueDateInTheFuture))
@@ -381,7 +381,7 @@ var _ = Describe("Scheduler", func() {
 
 				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{taskB, taskC, taskA})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				scheduledTasks := schedule.AchievableTasks.Enumerate()
@@ -392,6 +392,67 @@ var _ = Describe("Scheduler", func() {
 				Expect(actions).To(Equal([]string{"A", "B", "C"}))
 			})
 
+			It("will rank tasks with a higher priority first", func() {
+				important := taskWithDuration(10 * time.Minute)
+				important.Priority = todo.PriorityHighest
+
+				unimportant := taskWithDuration(10 * time.Minute)
+				unimportant.Priority = todo.PriorityLowest
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{unimportant, important})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.AchievableTasks.Enumerate()).To(Equal([]*todo.TodoItem{important, unimportant}))
+			})
+
+			It("will contain subtasks which fit, instead of their parent which doesn't", func() {
+				parent := taskWithDuration(4 * time.Hour)
+				parent.Id = 101
+
+				subtask := taskWithDuration(40 * time.Minute)
+				subtask.Id = 102
+				subtask.ParentId = &parent.Id
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{parent, subtask})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.AchievableTasks.Enumerate()).To(Equal([]*todo.TodoItem{subtask}))
+			})
+
+			It("will not contain tasks without a duration whose subtasks aren't complete", func() {
+				parent := taskWithoutDuration()
+				parent.Id = 101
+
+				subtask := taskWithDuration(4 * time.Hour)
+				subtask.Id = 102
+				subtask.ParentId = &parent.Id
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{parent, subtask})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.AchievableTasks.Enumerate()).To(BeEmpty())
+			})
+
+			It("will not contain tasks which are blocked by other tasks", func() {
+				blocked := taskWithDuration(10 * time.Minute)
+				blocked.BlockedBy = []int{101}
+
+				unblocked := taskWithDuration(10 * time.Minute)
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{blocked, unblocked})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.AchievableTasks.Enumerate()).To(Equal([]*todo.TodoItem{unblocked}))
+			})
+
 			Context("when there are no more meetings in the day", func() {
 				calWithoutFutureEvents := generateCalendar(
 					newEvent(now, "-2h", "30m"),
@@ -415,7 +476,7 @@ var _ = Describe("Scheduler", func() {
 						taskWithNoDueDate,
 					})
 
-					schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{calWithoutFutureEvents}, tasks)
+					schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{calWithoutFutureEvents}, tasks, scheduler.Options{})
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(schedule.AchievableTasks.Enumerate()).To(ContainElement(taskWithDueDateInTheFuture))
@@ -437,37 +498,216 @@ var _ = Describe("Scheduler", func() {
 
 				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{calA, calB}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{calA, calB}, tasks, scheduler.Options{})
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(schedule.CurrentCalendarEvents).To(ContainElements(currentEventInCalA, currentEventInCalB))
 				Expect(schedule.NextCalendarEvents).To(ContainElements(nextEventInCalA, nextEventInCalB))
 			})
 
-			It("will not contain recurring event definitions; only instances of a recurring event", func() {
-				nowRecurringEventDef := newEvent(now, "-30m", "1h")
-				nowRecurringEventDef.AddRrule("FREQ=WEEKLY;BYDAY=TU")
+			It("will contain today's occurrences of recurring events, instead of their definitions", func() {
+				nowRecurringEventDef := newEvent(now, "-168h30m", "1h")
+				nowRecurringEventDef.AddRrule("FREQ=DAILY")
+
+				nextRecurringEventDef := newEvent(now, "-166h", "30m")
+				nextRecurringEventDef.AddRrule("FREQ=DAILY")
 
-				nowRecurringEventInstance := newEvent(now, "-30m", "1h")
-				nowRecurringEventInstance.AddProperty(ical.ComponentProperty("RECURRENCE-ID"), "202020101T120000Z")
+				cal := generateCalendar(nowRecurringEventDef, nextRecurringEventDef)
 
-				nextRecurringEventDef := newEvent(now, "2h", "30m")
-				nextRecurringEventDef.AddRrule("FREQ=WEEKLY;BYDAY=TU")
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
+				Expect(err).ToNot(HaveOccurred())
 
-				nextRecurringEventInstance := newEvent(now, "2h", "30m")
-				nextRecurringEventInstance.AddProperty(ical.ComponentProperty("RECURRENCE-ID"), "202020101T120000Z")
+				Expect(schedule.CurrentCalendarEvents).To(HaveLen(1))
+				Expect(schedule.CurrentCalendarEvents[0].Id()).To(Equal(nowRecurringEventDef.Id()))
+				Expect(calendar.IsRecurringEventDefinition(schedule.CurrentCalendarEvents[0])).To(BeFalse())
+
+				currentStart, _, err := calendar.EventStartAndEnd(schedule.CurrentCalendarEvents[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(currentStart).To(BeTemporally("~", now.Add(-30*time.Minute), time.Second))
+
+				Expect(schedule.NextCalendarEvents).To(HaveLen(1))
+				Expect(schedule.NextCalendarEvents[0].Id()).To(Equal(nextRecurringEventDef.Id()))
+
+				nextStart, _, err := calendar.EventStartAndEnd(schedule.NextCalendarEvents[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(nextStart).To(BeTemporally("~", now.Add(2*time.Hour), time.Second))
+			})
 
-				cal := generateCalendar(nowRecurringEventDef, nowRecurringEventInstance, nextRecurringEventDef, nextRecurringEventInstance)
+			It("will contain moved occurrences of recurring events at their new time, and not contain cancelled ones", func() {
+				movedRecurringEventDef := newEvent(now, "-166h", "30m")
+				movedRecurringEventDef.AddRrule("FREQ=DAILY")
+
+				movedInstance := newEvent(now, "3h", "30m")
+				movedInstance.SetProperty(ical.ComponentPropertyUniqueId, movedRecurringEventDef.Id())
+				movedInstance.SetProperty(calendar.ComponentPropertyRecurrenceId, now.Add(2*time.Hour).UTC().Format("20060102T150405Z"))
+
+				cancelledRecurringEventDef := newEvent(now, "-168h30m", "1h")
+				cancelledRecurringEventDef.AddRrule("FREQ=DAILY")
+
+				cancelledInstance := newEvent(now, "-30m", "1h")
+				cancelledInstance.SetProperty(ical.ComponentPropertyUniqueId, cancelledRecurringEventDef.Id())
+				cancelledInstance.SetProperty(calendar.ComponentPropertyRecurrenceId, now.Add(-30*time.Minute).UTC().Format("20060102T150405Z"))
+				cancelledInstance.SetStatus(ical.ObjectStatusCancelled)
+
+				cal := generateCalendar(movedRecurringEventDef, movedInstance, cancelledRecurringEventDef, cancelledInstance)
 
 				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{})
 
-				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks)
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, scheduler.Options{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.CurrentCalendarEvents).To(BeEmpty())
+				Expect(schedule.NextCalendarEvents).To(ConsistOf(movedInstance))
+			})
+		})
+
+		Context("when there are working hours", func() {
+			lunch := scheduler.Break{Start: scheduler.TimeOfDay{Hour: 13}, End: scheduler.TimeOfDay{Hour: 14}}
+			options := scheduler.Options{
+				WorkingHours: scheduler.WorkingHours{
+					time.Monday: {
+						Start: scheduler.TimeOfDay{Hour: 9},
+						End:   scheduler.TimeOfDay{Hour: 17, Minute: 30},
+						Lunch: &lunch,
+					},
+				},
+			}
+
+			It("will contain the time left in the working day, and when it ends", func() {
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{}, todo.NewTodoItemCollection([]*todo.TodoItem{}), options)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*schedule.TimeLeftInWorkingDay).To(Equal(5*time.Hour + 30*time.Minute))
+				Expect(*schedule.EndOfWorkingDay).To(Equal(time.Date(2022, time.October, 17, 17, 30, 0, 0, time.Local)))
+				Expect(schedule.IsWorkingTime).To(BeTrue())
+			})
+
+			It("will only contain tasks which can be finished before lunch", func() {
+				fitsBeforeLunch := taskWithDuration(time.Hour)
+				tooLongBeforeLunch := taskWithDuration(90 * time.Minute)
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{fitsBeforeLunch, tooLongBeforeLunch})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{}, tasks, options)
 				Expect(err).ToNot(HaveOccurred())
 
-				Expect(schedule.CurrentCalendarEvents).To(ConsistOf(nowRecurringEventInstance))
-				Expect(schedule.NextCalendarEvents).To(ConsistOf(nextRecurringEventInstance))
+				Expect(*schedule.TimeUntilLunch).To(Equal(time.Hour))
+				Expect(*schedule.FreeTime()).To(Equal(time.Hour))
+				Expect(schedule.AchievableTasks.Enumerate()).To(ConsistOf(fitsBeforeLunch))
 			})
+
+			It("will only contain tasks which can be finished before the end of the working day", func() {
+				afternoon := time.Date(2022, time.October, 17, 16, 0, 0, 0, time.Local)
+				fitsInDay := taskWithDuration(time.Hour)
+				tooLongForDay := taskWithDuration(2 * time.Hour)
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{fitsInDay, tooLongForDay})
+
+				schedule, err := scheduler.GenerateSchedule(afternoon, []*ical.Calendar{}, tasks, options)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*schedule.FreeTime()).To(Equal(90 * time.Minute))
+				Expect(schedule.AchievableTasks.Enumerate()).To(ConsistOf(fitsInDay))
+			})
+
+			DescribeTable("will not contain any tasks outside of working time",
+				func(at time.Time) {
+					tasks := todo.NewTodoItemCollection([]*todo.TodoItem{taskWithDuration(5 * time.Minute), taskWithoutDuration()})
+
+					schedule, err := scheduler.GenerateSchedule(at, []*ical.Calendar{}, tasks, options)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(schedule.IsWorkingTime).To(BeFalse())
+					Expect(schedule.AchievableTasks.Len()).To(Equal(0))
+				},
+				Entry("before the working day", time.Date(2022, time.October, 17, 8, 0, 0, 0, time.Local)),
+				Entry("during lunch", time.Date(2022, time.October, 17, 13, 30, 0, 0, time.Local)),
+				Entry("after the working day", time.Date(2022, time.October, 17, 23, 0, 0, 0, time.Local)),
+				Entry("on a day that isn't a working day", time.Date(2022, time.October, 18, 12, 0, 0, 0, time.Local)),
+			)
 		})
 
+		Context("when there are buffers around meetings", func() {
+			options := scheduler.Options{
+				Buffers: scheduler.Buffers{Before: 5 * time.Minute, After: 10 * time.Minute},
+			}
+
+			It("will contain both the raw and usable time until the next meeting", func() {
+				cal := generateCalendar(newEvent(now, "30m", "30m"))
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{}), options)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*schedule.TimeUntilNextCalendarEvent).To(Equal(30 * time.Minute))
+				Expect(*schedule.UsableTimeUntilNextCalendarEvent).To(Equal(25 * time.Minute))
+			})
+
+			It("will not contain tasks which only fit in the raw time until the next meeting", func() {
+				cal := generateCalendar(newEvent(now, "30m", "30m"))
+				fitsWithBuffer := taskWithDuration(25 * time.Minute)
+				fitsWithoutBuffer := taskWithDuration(30 * time.Minute)
+
+				tasks := todo.NewTodoItemCollection([]*todo.TodoItem{fitsWithBuffer, fitsWithoutBuffer})
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, tasks, options)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.AchievableTasks.Enumerate()).To(ConsistOf(fitsWithBuffer))
+			})
+
+			It("will not count the buffer after a meeting which has just ended as usable", func() {
+				cal := generateCalendar(
+					newEvent(now, "-1h", "55m"),
+					newEvent(now, "30m", "30m"),
+				)
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{}), options)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*schedule.UsableTimeUntilNextCalendarEvent).To(Equal(20 * time.Minute))
+			})
+
+			It("will not count the buffer after the last meeting of the day as usable", func() {
+				lunch := scheduler.Break{Start: scheduler.TimeOfDay{Hour: 13}, End: scheduler.TimeOfDay{Hour: 14}}
+				withWorkingHours := options
+				withWorkingHours.WorkingHours = scheduler.WorkingHours{
+					time.Monday: {Start: scheduler.TimeOfDay{Hour: 9}, End: scheduler.TimeOfDay{Hour: 17, Minute: 30}, Lunch: &lunch},
+				}
+				cal := generateCalendar(newEvent(now, "-1h", "55m"))
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{}), withWorkingHours)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(schedule.UsableTimeUntilNextCalendarEvent).To(BeNil())
+				Expect(*schedule.TimeUntilLunch).To(Equal(55 * time.Minute))
+				Expect(*schedule.TimeLeftInWorkingDay).To(Equal(5*time.Hour + 25*time.Minute))
+				Expect(*schedule.FreeTime()).To(Equal(55 * time.Minute))
+			})
+
+			It("will use a calendar's own buffers instead of the global buffers", func() {
+				cal := generateCalendar(newEvent(now, "30m", "30m"))
+				withCalendarBuffers := options
+				withCalendarBuffers.CalendarBuffers = map[*ical.Calendar]scheduler.Buffers{
+					cal: {Before: 15 * time.Minute},
+				}
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{}), withCalendarBuffers)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*schedule.UsableTimeUntilNextCalendarEvent).To(Equal(15 * time.Minute))
+			})
+
+			It("will not have less than no usable time", func() {
+				cal := generateCalendar(newEvent(now, "2m", "30m"))
+
+				schedule, err := scheduler.GenerateSchedule(now, []*ical.Calendar{cal}, todo.NewTodoItemCollection([]*todo.TodoItem{taskWithDuration(time.Minute)}), options)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*schedule.UsableTimeUntilNextCalendarEvent).To(Equal(time.Duration(0)))
+				Expect(schedule.AchievableTasks.Len()).To(Equal(0))
+			})
+		})
 	})
 })
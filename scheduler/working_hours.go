@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var regexTimeOfDay *regexp.Regexp = regexp.MustCompile("^([01]?[0-9]|2[0-3]):([0-5][0-9])$")
+
+// TimeOfDay is a wall clock time, such as 09:30
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+}
+
+// ParseTimeOfDay parses a 24 hour time in the form "HH:MM"
+func ParseTimeOfDay(value string) (TimeOfDay, error) {
+	matched := regexTimeOfDay.FindStringSubmatch(strings.TrimSpace(value))
+	if matched == nil {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day '%s', expected HH:MM", value)
+	}
+
+	hour, _ := strconv.Atoi(matched[1])
+	minute, _ := strconv.Atoi(matched[2])
+
+	return TimeOfDay{Hour: hour, Minute: minute}, nil
+}
+
+// On returns the time of day on the same date as day, in its location
+func (t TimeOfDay) On(day time.Time) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, t.Hour, t.Minute, 0, 0, day.Location())
+}
+
+func (t TimeOfDay) Before(other TimeOfDay) bool {
+	return t.Hour < other.Hour || (t.Hour == other.Hour && t.Minute < other.Minute)
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// Break is a period during the working day which isn't worked
+type Break struct {
+	Start TimeOfDay
+	End   TimeOfDay
+}
+
+// ParseBreak parses a break in the form "HH:MM-HH:MM"
+func ParseBreak(value string) (Break, error) {
+	startStr, endStr, found := strings.Cut(value, "-")
+	if !found {
+		return Break{}, fmt.Errorf("invalid break '%s', expected HH:MM-HH:MM", value)
+	}
+
+	start, err := ParseTimeOfDay(startStr)
+	if err != nil {
+		return Break{}, err
+	}
+
+	end, err := ParseTimeOfDay(endStr)
+	if err != nil {
+		return Break{}, err
+	}
+
+	if !start.Before(end) {
+		return Break{}, fmt.Errorf("invalid break '%s', it must end after it starts", value)
+	}
+
+	return Break{Start: start, End: end}, nil
+}
+
+type WorkingDay struct {
+	Start TimeOfDay
+	End   TimeOfDay
+	Lunch *Break
+}
+
+// Bounds returns the start and end of the working day on the date of day
+func (d WorkingDay) Bounds(day time.Time) (time.Time, time.Time) {
+	return d.Start.On(day), d.End.On(day)
+}
+
+// LunchBounds returns the start and end of lunch on the date
+// of day, and false when the working day has no lunch break
+func (d WorkingDay) LunchBounds(day time.Time) (time.Time, time.Time, bool) {
+	if d.Lunch == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return d.Lunch.Start.On(day), d.Lunch.End.On(day), true
+}
+
+// IsWorkingTime returns true when t is between the start
+// and end of the working day, and not during lunch
+func (d WorkingDay) IsWorkingTime(t time.Time) bool {
+	start, end := d.Bounds(t)
+	if t.Before(start) || !t.Before(end) {
+		return false
+	}
+
+	lunchStart, lunchEnd, hasLunch := d.LunchBounds(t)
+	if hasLunch && !t.Before(lunchStart) && t.Before(lunchEnd) {
+		return false
+	}
+
+	return true
+}
+
+func (d WorkingDay) Validate() error {
+	if !d.Start.Before(d.End) {
+		return fmt.Errorf("working day must end after it starts")
+	}
+
+	if d.Lunch != nil && (d.Lunch.Start.Before(d.Start) || d.End.Before(d.Lunch.End)) {
+		return fmt.Errorf("lunch must be within the working day")
+	}
+
+	return nil
+}
+
+// WorkingHours are the working days of the week. Days
+// which are missing from the map aren't working days.
+type WorkingHours map[time.Weekday]WorkingDay
+
+// On returns the working day for the day of the week that
+// t falls on, and false when it isn't a working day
+func (w WorkingHours) On(t time.Time) (WorkingDay, bool) {
+	day, ok := w[t.Weekday()]
+	return day, ok
+}
+
+// ParseWeekday parses the full or abbreviated name of a day of the week
+func ParseWeekday(value string) (time.Weekday, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if value == name || value == name[:3] {
+			return weekday, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("invalid day of the week '%s'", value)
+}
@@ -0,0 +1,100 @@
+package scheduler_test
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkingHours", func() {
+	DescribeTable("ParseTimeOfDay",
+		func(input string, expected scheduler.TimeOfDay) {
+			actual, err := scheduler.ParseTimeOfDay(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("morning", "09:30", scheduler.TimeOfDay{Hour: 9, Minute: 30}),
+		Entry("without a leading zero", "9:05", scheduler.TimeOfDay{Hour: 9, Minute: 5}),
+		Entry("afternoon", "17:45", scheduler.TimeOfDay{Hour: 17, Minute: 45}),
+	)
+
+	DescribeTable("ParseTimeOfDay rejects values that aren't 24 hour times",
+		func(input string) {
+			_, err := scheduler.ParseTimeOfDay(input)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("12 hour time", "9:30pm"),
+		Entry("out of range hour", "24:00"),
+		Entry("out of range minute", "12:60"),
+	)
+
+	Describe("ParseBreak", func() {
+		It("parses a start and end time", func() {
+			actual, err := scheduler.ParseBreak("12:30-13:15")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(scheduler.Break{
+				Start: scheduler.TimeOfDay{Hour: 12, Minute: 30},
+				End:   scheduler.TimeOfDay{Hour: 13, Minute: 15},
+			}))
+		})
+
+		It("rejects breaks which end before they start", func() {
+			_, err := scheduler.ParseBreak("13:30-12:30")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("WorkingDay", func() {
+		lunch := scheduler.Break{Start: scheduler.TimeOfDay{Hour: 12}, End: scheduler.TimeOfDay{Hour: 13}}
+		workingDay := scheduler.WorkingDay{
+			Start: scheduler.TimeOfDay{Hour: 9},
+			End:   scheduler.TimeOfDay{Hour: 17},
+			Lunch: &lunch,
+		}
+		at := func(hour int, minute int) time.Time {
+			return time.Date(2022, time.October, 17, hour, minute, 0, 0, time.Local)
+		}
+
+		DescribeTable("IsWorkingTime",
+			func(t time.Time, expected bool) {
+				Expect(workingDay.IsWorkingTime(t)).To(Equal(expected))
+			},
+			Entry("before the start", at(8, 59), false),
+			Entry("at the start", at(9, 0), true),
+			Entry("during the morning", at(11, 0), true),
+			Entry("during lunch", at(12, 30), false),
+			Entry("at the end of lunch", at(13, 0), true),
+			Entry("at the end", at(17, 0), false),
+		)
+
+		It("is invalid when lunch isn't within the working day", func() {
+			lateLunch := scheduler.Break{Start: scheduler.TimeOfDay{Hour: 16}, End: scheduler.TimeOfDay{Hour: 18}}
+			invalid := scheduler.WorkingDay{Start: workingDay.Start, End: workingDay.End, Lunch: &lateLunch}
+
+			Expect(invalid.Validate()).To(HaveOccurred())
+		})
+
+		It("is invalid when it ends before it starts", func() {
+			invalid := scheduler.WorkingDay{Start: workingDay.End, End: workingDay.Start}
+
+			Expect(invalid.Validate()).To(HaveOccurred())
+		})
+	})
+
+	DescribeTable("ParseWeekday",
+		func(input string, expected time.Weekday) {
+			actual, err := scheduler.ParseWeekday(input)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("full name", "monday", time.Monday),
+		Entry("abbreviated name", "fri", time.Friday),
+		Entry("capitalised name", "Sunday", time.Sunday),
+	)
+})
@@ -0,0 +1,20 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchExpression turns what the user searched for in to an FTS5 match
+// expression, which finds the entries containing every word in the query
+// as the start of a word. Each word is quoted, so that the query can't
+// contain FTS5 syntax. It returns an empty string when there aren't any
+// words to search for.
+func MatchExpression(query string) string {
+	terms := []string{}
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, fmt.Sprintf(`"%s"*`, strings.ReplaceAll(word, `"`, `""`)))
+	}
+
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,21 @@
+package search_test
+
+import (
+	"github.com/AP-Hunt/what-next/m/search"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MatchExpression", func() {
+	It("matches every word in the query as a prefix", func() {
+		Expect(search.MatchExpression("dentist  appoint")).To(Equal(`"dentist"* "appoint"*`))
+	})
+
+	It("quotes words so they can't be FTS5 syntax", func() {
+		Expect(search.MatchExpression(`NOT ship-it "now`)).To(Equal(`"NOT"* "ship-it"* """now"*`))
+	})
+
+	It("is empty when there are no words", func() {
+		Expect(search.MatchExpression("  ")).To(Equal(""))
+	})
+})
@@ -0,0 +1,197 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AP-Hunt/what-next/m/todo"
+	ical "github.com/arran4/golang-ical"
+	"github.com/jmoiron/sqlx"
+)
+
+// The text in a result's snippet which matched the query
+// is between SnippetMatchStart and SnippetMatchEnd
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+// ErrFTS5Unavailable is returned when the SQLite library what-next was
+// built with doesn't have the FTS5 extension
+var ErrFTS5Unavailable = errors.New("search needs SQLite's FTS5 extension, which this build of what-next doesn't have. Build it with the sqlite_fts5 tag")
+
+// CalendarEvent is an event, along with the
+// display name of the calendar it's from
+type CalendarEvent struct {
+	Calendar string
+	Event    *ical.VEvent
+}
+
+// Result is a todo item or calendar event which matched a search
+type Result struct {
+	TodoItem *todo.TodoItem
+	Event    *CalendarEvent
+
+	// Snippet is the part of the text which best matched the query
+	Snippet string
+
+	// Rank is how relevant the result is. Higher is more relevant.
+	Rank float64
+}
+
+//counterfeiter:generate -o fakes/ . SearchIndexInterface
+type SearchIndexInterface interface {
+	Search(query string, todos *todo.TodoItemCollection, events []CalendarEvent) ([]Result, error)
+}
+
+// SQLiteSearchIndex searches with SQLite's FTS5 full text search.
+// Todo items and calendar events are indexed in a temporary table
+// for each search, so the index is never out of date with the
+// todo list or the calendar cache.
+type SQLiteSearchIndex struct {
+	conn *sqlx.DB
+	ctx  context.Context
+}
+
+func NewSQLiteSearchIndex(conn *sqlx.DB, ctx context.Context) *SQLiteSearchIndex {
+	return &SQLiteSearchIndex{
+		conn: conn,
+		ctx:  ctx,
+	}
+}
+
+const (
+	kindTodoItem = "todo"
+	kindEvent    = "event"
+)
+
+type searchRow struct {
+	Kind    string
+	Ref     int
+	Snippet string
+	Score   float64
+}
+
+// Search finds the todo items whose action, notes or tags, and the
+// events whose summary, description or location, contain every word
+// of the query. Words match the start of words in the text, so "meet"
+// finds "meeting". Results are ordered from most to least relevant,
+// with matches in an action or summary counting for more than
+// matches elsewhere.
+func (s *SQLiteSearchIndex) Search(query string, todos *todo.TodoItemCollection, events []CalendarEvent) ([]Result, error) {
+	match := MatchExpression(query)
+	if match == "" {
+		return []Result{}, nil
+	}
+
+	// Temporary tables only exist on the connection which
+	// created them, so everything is done on the same one
+	conn, err := s.conn.Connx(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(s.ctx, `DROP TABLE IF EXISTS temp.search_index`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.ExecContext(s.ctx, `CREATE VIRTUAL TABLE temp.search_index USING fts5(kind UNINDEXED, ref UNINDEXED, title, body)`)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return nil, ErrFTS5Unavailable
+		}
+		return nil, err
+	}
+	defer conn.ExecContext(s.ctx, `DROP TABLE IF EXISTS temp.search_index`)
+
+	items := todos.Enumerate()
+	err = s.index(conn, items, events)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []searchRow{}
+	err = conn.SelectContext(
+		s.ctx,
+		&rows,
+		`SELECT
+			kind,
+			ref,
+			snippet(search_index, -1, ?, ?, '…', 12) AS snippet,
+			bm25(search_index, 0.0, 0.0, 2.0, 1.0) AS score
+		FROM search_index
+		WHERE search_index MATCH ?
+		ORDER BY score, kind DESC, ref`,
+		SnippetMatchStart,
+		SnippetMatchEnd,
+		match,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching: %s", err)
+	}
+
+	results := []Result{}
+	for _, row := range rows {
+		// bm25 scores more relevant matches lower
+		result := Result{Snippet: row.Snippet, Rank: -row.Score}
+
+		switch row.Kind {
+		case kindTodoItem:
+			result.TodoItem = items[row.Ref]
+		case kindEvent:
+			result.Event = &events[row.Ref]
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *SQLiteSearchIndex) index(conn *sqlx.Conn, items []*todo.TodoItem, events []CalendarEvent) error {
+	tx, err := conn.BeginTxx(s.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PreparexContext(s.ctx, `INSERT INTO search_index (kind, ref, title, body) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for ref, item := range items {
+		body := strings.Join(append([]string{item.Notes}, item.Tags...), "\n")
+		_, err = insert.ExecContext(s.ctx, kindTodoItem, ref, item.Action, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	for ref, event := range events {
+		body := strings.Join([]string{
+			propertyValue(event.Event, ical.PropertyDescription),
+			propertyValue(event.Event, ical.PropertyLocation),
+		}, "\n")
+		_, err = insert.ExecContext(s.ctx, kindEvent, ref, propertyValue(event.Event, ical.PropertySummary), body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func propertyValue(event *ical.VEvent, property ical.Property) string {
+	prop := event.GetProperty(ical.ComponentProperty(property))
+	if prop == nil {
+		return ""
+	}
+
+	return prop.Value
+}
@@ -0,0 +1,124 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AP-Hunt/what-next/m/db"
+	"github.com/AP-Hunt/what-next/m/search"
+	"github.com/AP-Hunt/what-next/m/todo"
+	ical "github.com/arran4/golang-ical"
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newEvent(id string, summary string, description string, location string) *ical.VEvent {
+	event := ical.NewEvent(id)
+	event.SetProperty(ical.ComponentProperty(ical.PropertySummary), summary)
+	if description != "" {
+		event.SetProperty(ical.ComponentProperty(ical.PropertyDescription), description)
+	}
+	if location != "" {
+		event.SetProperty(ical.ComponentProperty(ical.PropertyLocation), location)
+	}
+	return event
+}
+
+var _ = Describe("SQLiteSearchIndex", func() {
+	var (
+		conn   *sqlx.DB
+		index  *search.SQLiteSearchIndex
+		todos  *todo.TodoItemCollection
+		events []search.CalendarEvent
+	)
+
+	mustSearch := func(query string) []search.Result {
+		results, err := index.Search(query, todos, events)
+		if errors.Is(err, search.ErrFTS5Unavailable) {
+			Skip("SQLite was built without FTS5, run the tests with the sqlite_fts5 tag")
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return results
+	}
+
+	BeforeEach(func() {
+		var err error
+		conn, err = db.Connect(":memory:")
+		Expect(err).ToNot(HaveOccurred())
+
+		index = search.NewSQLiteSearchIndex(conn, context.Background())
+
+		todos = todo.NewTodoItemCollection([]*todo.TodoItem{
+			{Id: 1, Action: "Book dentist appointment"},
+			{Id: 2, Action: "Call the bank", Notes: "Ask about the dentist's bill"},
+			{Id: 3, Action: "Buy milk", Tags: []string{"@shops"}},
+		})
+
+		events = []search.CalendarEvent{
+			{Calendar: "work", Event: newEvent("standup", "Team standup", "", "Meeting room 2")},
+			{Calendar: "home", Event: newEvent("dentist", "Dentist", "Check up", "High street")},
+		}
+	})
+
+	AfterEach(func() {
+		conn.Close()
+	})
+
+	It("finds todo items by their action, notes and tags", func() {
+		Expect(mustSearch("bank")[0].TodoItem.Id).To(Equal(2))
+		Expect(mustSearch("bill")[0].TodoItem.Id).To(Equal(2))
+		Expect(mustSearch("shops")[0].TodoItem.Id).To(Equal(3))
+	})
+
+	It("finds calendar events by their summary, description and location", func() {
+		Expect(mustSearch("standup")[0].Event.Event.Id()).To(Equal("standup"))
+		Expect(mustSearch("check")[0].Event.Event.Id()).To(Equal("dentist"))
+		Expect(mustSearch("meeting room")[0].Event.Calendar).To(Equal("work"))
+	})
+
+	It("matches words which start with each word of the query", func() {
+		results := mustSearch("appoint dent")
+
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].TodoItem.Id).To(Equal(1))
+	})
+
+	It("ranks matches in an action or summary above matches elsewhere", func() {
+		results := mustSearch("dentist")
+
+		Expect(results).To(HaveLen(3))
+		Expect(results[len(results)-1].TodoItem.Id).To(Equal(2))
+		Expect(results[0].Rank).To(BeNumerically(">=", results[1].Rank))
+		Expect(results[1].Rank).To(BeNumerically(">", results[2].Rank))
+	})
+
+	It("marks the text which matched in the snippet", func() {
+		results := mustSearch("milk")
+
+		Expect(results[0].Snippet).To(Equal("Buy " + search.SnippetMatchStart + "milk" + search.SnippetMatchEnd))
+	})
+
+	It("does not treat the query as FTS5 syntax", func() {
+		Expect(mustSearch(`milk OR "bank`)).To(BeEmpty())
+		Expect(mustSearch(`NEAR(`)).To(BeEmpty())
+	})
+
+	It("returns nothing for an empty query", func() {
+		Expect(mustSearch(" ")).To(BeEmpty())
+	})
+
+	It("can be searched more than once", func() {
+		mustSearch("milk")
+		Expect(mustSearch("milk")).To(HaveLen(1))
+	})
+
+	It("does not leave the index in the database", func() {
+		mustSearch("milk")
+
+		var count int
+		err := conn.Get(&count, `SELECT COUNT(*) FROM sqlite_temp_master WHERE name LIKE 'search_index%'`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(0))
+	})
+})
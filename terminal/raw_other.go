@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package terminal
+
+func makeRaw(fd int) (func() error, error) {
+	return nil, ErrNotSupported
+}
+
+func size(fd int) (int, int, error) {
+	return 0, 0, ErrNotSupported
+}
@@ -0,0 +1,226 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ErrNotSupported is returned when full screen
+// interfaces can't be drawn on this platform
+var ErrNotSupported = errors.New("full screen interfaces aren't supported on this platform")
+
+const (
+	escEnterFullScreen = "\x1b[?1049h\x1b[?25l"
+	escExitFullScreen  = "\x1b[?25h\x1b[?1049l"
+	escHome            = "\x1b[H"
+	escClearLine       = "\x1b[K"
+	escClearBelow      = "\x1b[J"
+)
+
+// Terminal draws a full screen interface in a terminal, and reads the
+// keys pressed in it. The terminal is in raw mode while it's open, so
+// keys are read as soon as they're pressed, and aren't echoed.
+type Terminal struct {
+	in      *os.File
+	out     io.Writer
+	restore func() error
+}
+
+// Open puts the terminal in raw mode and switches to the alternate
+// screen, so that what was on the screen comes back when it's closed
+func Open(in *os.File, out io.Writer) (*Terminal, error) {
+	term := &Terminal{in: in, out: out}
+
+	err := term.Resume()
+	if err != nil {
+		return nil, err
+	}
+
+	return term, nil
+}
+
+// Close puts the terminal back the way it was before it was opened
+func (t *Terminal) Close() error {
+	return t.Suspend()
+}
+
+// Suspend puts the terminal back the way it was before it was opened,
+// so that another program, such as an editor, can use it until Resume
+func (t *Terminal) Suspend() error {
+	if t.restore == nil {
+		return nil
+	}
+
+	fmt.Fprint(t.out, escExitFullScreen)
+
+	err := t.restore()
+	t.restore = nil
+	return err
+}
+
+// Resume puts the terminal back in raw mode on the alternate screen
+func (t *Terminal) Resume() error {
+	restore, err := makeRaw(int(t.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("open terminal: %w", err)
+	}
+
+	t.restore = restore
+	fmt.Fprint(t.out, escEnterFullScreen)
+	return nil
+}
+
+// Size returns the width and height of the terminal, in columns and rows
+func (t *Terminal) Size() (int, int, error) {
+	return size(int(t.in.Fd()))
+}
+
+// ReadKey waits a short time for a key to be pressed, and returns
+// KeyNone when one isn't, so the caller can do other work in between
+func (t *Terminal) ReadKey() (Key, error) {
+	buf := make([]byte, 64)
+	n, err := t.in.Read(buf)
+	if err == io.EOF {
+		return KeyNone, nil
+	}
+	if err != nil {
+		return KeyNone, err
+	}
+
+	return ParseKey(buf[:n]), nil
+}
+
+// Draw replaces what is on the screen with the lines, which
+// should already fit in the width and height of the terminal
+func (t *Terminal) Draw(lines []string) error {
+	builder := strings.Builder{}
+	builder.WriteString(escHome)
+
+	for i, line := range lines {
+		if i > 0 {
+			builder.WriteString("\r\n")
+		}
+		builder.WriteString(line)
+		builder.WriteString(escClearLine)
+	}
+	builder.WriteString(escClearBelow)
+
+	_, err := io.WriteString(t.out, builder.String())
+	return err
+}
+
+// Clear clears the screen, so that what is written next is drawn over it
+func Clear(out io.Writer) error {
+	_, err := io.WriteString(out, escHome+escClearBelow)
+	return err
+}
+
+// Fit cuts the text short, or pads it with spaces, so
+// that it takes up exactly the number of columns
+func Fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	return runewidth.FillRight(runewidth.Truncate(text, width, "…"), width)
+}
+
+// Key is a key pressed in the terminal. Printable keys are the text they
+// type, which can be more than one character when text is pasted. Other
+// keys begin with a NUL, which can't be typed, so they can't be confused
+// with text.
+type Key string
+
+const (
+	KeyNone      Key = ""
+	KeyUp        Key = "\x00up"
+	KeyDown      Key = "\x00down"
+	KeyLeft      Key = "\x00left"
+	KeyRight     Key = "\x00right"
+	KeyHome      Key = "\x00home"
+	KeyEnd       Key = "\x00end"
+	KeyPageUp    Key = "\x00pgup"
+	KeyPageDown  Key = "\x00pgdown"
+	KeyEnter     Key = "\x00enter"
+	KeyEscape    Key = "\x00esc"
+	KeyBackspace Key = "\x00backspace"
+	KeyTab       Key = "\x00tab"
+	KeyCtrlC     Key = "\x00ctrl+c"
+	KeyCtrlU     Key = "\x00ctrl+u"
+	KeyUnknown   Key = "\x00unknown"
+)
+
+// IsText is true when the key is text which was typed
+func (k Key) IsText() bool {
+	return k != KeyNone && k[0] != 0
+}
+
+var escapeSequences = map[string]Key{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1bOH":  KeyHome,
+	"\x1bOF":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+}
+
+// ParseKey works out which key the bytes read from a terminal are
+func ParseKey(input []byte) Key {
+	if len(input) == 0 {
+		return KeyNone
+	}
+
+	switch input[0] {
+	case 0x1b:
+		if len(input) == 1 {
+			return KeyEscape
+		}
+		if key, ok := escapeSequences[string(input)]; ok {
+			return key
+		}
+		return KeyUnknown
+	case '\r', '\n':
+		return KeyEnter
+	case 0x7f, 0x08:
+		return KeyBackspace
+	case '\t':
+		return KeyTab
+	case 0x03:
+		return KeyCtrlC
+	case 0x15:
+		return KeyCtrlU
+	}
+
+	text := strings.Builder{}
+	for len(input) > 0 {
+		r, size := utf8.DecodeRune(input)
+		input = input[size:]
+
+		if r == utf8.RuneError || r < 0x20 || r == 0x7f {
+			continue
+		}
+		text.WriteRune(r)
+	}
+
+	if text.Len() == 0 {
+		return KeyUnknown
+	}
+
+	return Key(text.String())
+}
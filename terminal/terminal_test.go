@@ -0,0 +1,53 @@
+package terminal_test
+
+import (
+	"github.com/AP-Hunt/what-next/m/terminal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseKey", func() {
+	DescribeTable("works out which key was pressed",
+		func(input string, expected terminal.Key) {
+			Expect(terminal.ParseKey([]byte(input))).To(Equal(expected))
+		},
+		Entry("nothing", "", terminal.KeyNone),
+		Entry("a letter", "a", terminal.Key("a")),
+		Entry("text which was pasted", "foo bar", terminal.Key("foo bar")),
+		Entry("text which isn't ASCII", "é", terminal.Key("é")),
+		Entry("up", "\x1b[A", terminal.KeyUp),
+		Entry("down in application mode", "\x1bOB", terminal.KeyDown),
+		Entry("page down", "\x1b[6~", terminal.KeyPageDown),
+		Entry("escape", "\x1b", terminal.KeyEscape),
+		Entry("an escape sequence it doesn't know", "\x1b[15~", terminal.KeyUnknown),
+		Entry("enter", "\r", terminal.KeyEnter),
+		Entry("backspace", "\x7f", terminal.KeyBackspace),
+		Entry("ctrl+c", "\x03", terminal.KeyCtrlC),
+	)
+})
+
+var _ = Describe("Key", func() {
+	It("is text when it was typed", func() {
+		Expect(terminal.Key("up").IsText()).To(BeTrue())
+		Expect(terminal.KeyUp.IsText()).To(BeFalse())
+		Expect(terminal.KeyNone.IsText()).To(BeFalse())
+	})
+})
+
+var _ = Describe("Fit", func() {
+	It("pads text which is shorter than the width", func() {
+		Expect(terminal.Fit("foo", 5)).To(Equal("foo  "))
+	})
+
+	It("cuts text which is longer than the width short", func() {
+		Expect(terminal.Fit("foo bar", 5)).To(Equal("foo …"))
+	})
+
+	It("counts wide characters as two columns", func() {
+		Expect(terminal.Fit("⏳ foo", 4)).To(Equal("⏳ …"))
+	})
+
+	It("is empty when there's no width", func() {
+		Expect(terminal.Fit("foo", 0)).To(Equal(""))
+	})
+})
@@ -70,3 +70,12 @@ func (c *TodoItemCollection) SortByDueDateAsc() *TodoItemCollection {
 
 	return NewTodoItemCollection(items)
 }
+
+// SortStable sorts the items using the less function, keeping
+// items which are equal to each other in their current order
+func (c *TodoItemCollection) SortStable(less func(a *TodoItem, b *TodoItem) bool) *TodoItemCollection {
+	items := append([]*TodoItem{}, c.items...)
+	slices.SortStableFunc(items, less)
+
+	return NewTodoItemCollection(items)
+}
@@ -0,0 +1,38 @@
+package todo
+
+// IsWaitingOn returns true when the item with the id is blocked by
+// the other item, either directly or through the items blocking it
+func (c *TodoItemCollection) IsWaitingOn(id int, other int) bool {
+	itemsById := map[int]*TodoItem{}
+	for _, item := range c.items {
+		itemsById[item.Id] = item
+	}
+
+	visited := map[int]bool{}
+	waiting := []int{id}
+
+	for len(waiting) > 0 {
+		current := waiting[0]
+		waiting = waiting[1:]
+
+		item, ok := itemsById[current]
+		if !ok || visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		for _, blockedById := range item.BlockedBy {
+			if blockedById == other {
+				return true
+			}
+			waiting = append(waiting, blockedById)
+		}
+	}
+
+	return false
+}
+
+// IsNotBlocked is a filter func which matches items that aren't waiting on other items
+func IsNotBlocked(item *TodoItem) bool {
+	return !item.IsBlocked()
+}
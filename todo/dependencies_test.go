@@ -0,0 +1,33 @@
+package todo_test
+
+import (
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dependencies", func() {
+	deploy := &TodoItem{Id: 1, BlockedBy: []int{2}}
+	approval := &TodoItem{Id: 2, BlockedBy: []int{3}}
+	review := &TodoItem{Id: 3}
+	other := &TodoItem{Id: 4}
+	collection := NewTodoItemCollection([]*TodoItem{deploy, approval, review, other})
+
+	Describe("IsWaitingOn", func() {
+		It("is true for the items an item is blocked by, directly or not", func() {
+			Expect(collection.IsWaitingOn(deploy.Id, approval.Id)).To(BeTrue())
+			Expect(collection.IsWaitingOn(deploy.Id, review.Id)).To(BeTrue())
+		})
+
+		It("is false for other items", func() {
+			Expect(collection.IsWaitingOn(review.Id, deploy.Id)).To(BeFalse())
+			Expect(collection.IsWaitingOn(deploy.Id, other.Id)).To(BeFalse())
+		})
+	})
+
+	Describe("IsNotBlocked", func() {
+		It("matches the items which aren't blocked", func() {
+			Expect(collection.Filter(IsNotBlocked).Enumerate()).To(ConsistOf(review, other))
+		})
+	})
+})
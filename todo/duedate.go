@@ -2,26 +2,307 @@ package todo
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/AP-Hunt/what-next/m/calendar"
 	"github.com/araddon/dateparse"
 	"github.com/golang-module/carbon/v2"
 )
 
-func ParseDueDate(input string) (time.Time, error) {
-	switch input {
-	case "@tod", "@today":
-		return carbon.Now().EndOfDay().Carbon2Time(), nil
+// dueDateForm is one of the forms a due date can be given in. Forms
+// which pick out a day are due at the end of it, unless a time is given
+// after them.
+type dueDateForm struct {
+	pattern     *regexp.Regexp
+	examples    []string
+	description string
 
-	case "@tom", "@tmrw", "@tomorrow":
-		return carbon.Tomorrow().EndOfDay().Carbon2Time(), nil
+	// takesTime is true when the form picks out a
+	// day, which a time can be given after
+	takesTime bool
 
-	default:
-		date, err := dateparse.ParseLocal(input)
+	resolve func(matched []string, now time.Time) time.Time
+}
+
+var dueDateWeekdays = map[string]time.Weekday{}
+
+func init() {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		dueDateWeekdays[name] = weekday
+		dueDateWeekdays[name[:3]] = weekday
+	}
+}
+
+const dueDateWeekdayPattern = `(monday|mon|tuesday|tue|wednesday|wed|thursday|thu|friday|fri|saturday|sat|sunday|sun)`
+
+var dueDateForms = []dueDateForm{
+	{
+		pattern:     regexp.MustCompile(`^(@tod|@today|today)$`),
+		examples:    []string{"today", "@today", "@tod"},
+		description: "the end of today",
+		takesTime:   true,
+		resolve: func(matched []string, now time.Time) time.Time {
+			return endOfDay(now)
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(@tom|@tmrw|@tomorrow|tomorrow)$`),
+		examples:    []string{"tomorrow", "@tomorrow", "@tom", "@tmrw"},
+		description: "the end of tomorrow",
+		takesTime:   true,
+		resolve: func(matched []string, now time.Time) time.Time {
+			return endOfDay(now.AddDate(0, 0, 1))
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^` + dueDateWeekdayPattern + `$`),
+		examples:    []string{"friday", "fri"},
+		description: "the end of the next time that day comes around, which can be today",
+		takesTime:   true,
+		resolve: func(matched []string, now time.Time) time.Time {
+			return endOfDay(now.AddDate(0, 0, daysUntilWeekday(now, dueDateWeekdays[matched[1]], false)))
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^next\s+` + dueDateWeekdayPattern + `$`),
+		examples:    []string{"next tuesday", "next tue"},
+		description: "the end of the next time that day comes around after today",
+		takesTime:   true,
+		resolve: func(matched []string, now time.Time) time.Time {
+			return endOfDay(now.AddDate(0, 0, daysUntilWeekday(now, dueDateWeekdays[matched[1]], true)))
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(?:in\s+(\d+)\s*(day|week|month)s?|\+(\d+)(d|w))$`),
+		examples:    []string{"in 2 days", "in 1 week", "in 3 months", "+3d", "+2w"},
+		description: "the end of the day that many days, weeks or months from today",
+		takesTime:   true,
+		resolve: func(matched []string, now time.Time) time.Time {
+			amount, unit := matched[1], matched[2]
+			if amount == "" {
+				amount, unit = matched[3], matched[4]
+			}
+			n, _ := strconv.Atoi(amount)
+
+			switch unit {
+			case "week", "w":
+				return endOfDay(now.AddDate(0, 0, 7*n))
+			case "month":
+				return endOfDay(carbon.Time2Carbon(now).AddMonthsNoOverflow(n).Carbon2Time())
+			default:
+				return endOfDay(now.AddDate(0, 0, n))
+			}
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(?:in\s+(\d+)\s*(hour|minute|min)s?|\+(\d+)(h))$`),
+		examples:    []string{"in 2 hours", "in 30 minutes", "+4h"},
+		description: "that many hours or minutes from now",
+		resolve: func(matched []string, now time.Time) time.Time {
+			amount, unit := matched[1], matched[2]
+			if amount == "" {
+				amount, unit = matched[3], matched[4]
+			}
+			n, _ := strconv.Atoi(amount)
+
+			if unit == "hour" || unit == "h" {
+				return now.Add(time.Duration(n) * time.Hour)
+			}
+			return now.Add(time.Duration(n) * time.Minute)
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(eod|end of (the )?day)$`),
+		examples:    []string{"end of day", "eod"},
+		description: "the end of today",
+		resolve: func(matched []string, now time.Time) time.Time {
+			return endOfDay(now)
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(eow|end of (the )?week)$`),
+		examples:    []string{"end of week", "eow"},
+		description: "the end of Sunday this week, as weeks start on Monday",
+		resolve: func(matched []string, now time.Time) time.Time {
+			return endOfDay(now.AddDate(0, 0, daysUntilWeekday(now, time.Sunday, false)))
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(eom|end of (the )?month)$`),
+		examples:    []string{"end of month", "eom"},
+		description: "the end of the last day of this month",
+		resolve: func(matched []string, now time.Time) time.Time {
+			return carbon.Time2Carbon(now).EndOfMonth().Carbon2Time()
+		},
+	},
+	{
+		pattern:     regexp.MustCompile(`^(eoy|end of (the )?year)$`),
+		examples:    []string{"end of year", "eoy"},
+		description: "the end of the 31st of December this year",
+		resolve: func(matched []string, now time.Time) time.Time {
+			return carbon.Time2Carbon(now).EndOfYear().Carbon2Time()
+		},
+	},
+}
+
+// regexDueDateTime matches a time at the end of a due date, such as
+// "3pm", "3:30pm", "15:00" or "noon", which can be preceded by "at"
+var regexDueDateTime = regexp.MustCompile(`^(.*?)\s*(?:\bat\s+)?(?:(\d{1,2})(?::(\d{2}))?\s*(am|pm)|(\d{1,2}):(\d{2})|(noon))$`)
+
+// ParseDueDate parses a due date in any of the forms described by
+// DueDateHelp, relative to now. Anything else is parsed as a date and
+// time by github.com/araddon/dateparse. Due dates are in local time.
+func ParseDueDate(input string, now time.Time) (time.Time, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	now = now.Local()
+
+	if dueDate, ok := parseDueDateForm(normalized, now, false); ok {
+		return dueDate, nil
+	}
+
+	if matched := regexDueDateTime.FindStringSubmatch(normalized); matched != nil {
+		hour, minute, err := parseDueDateTime(matched)
 		if err != nil {
-			return time.Unix(0, 0), fmt.Errorf("invalid due date format: %s", err)
+			return time.Unix(0, 0), err
+		}
+
+		day := now
+		if matched[1] != "" {
+			var ok bool
+			day, ok = parseDueDateForm(matched[1], now, true)
+			if !ok {
+				return parseDateTime(input)
+			}
+		}
+
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local), nil
+	}
+
+	return parseDateTime(input)
+}
+
+// ParseSinceDate parses the date a period looking back from now begins
+// at, such as the date given to --completed-since. Days are the last time
+// they came around, which can be today, and yesterday can be given too.
+// Forms which pick out a day begin at midnight on it, unless a time is
+// given after them, and anything else is parsed by ParseDueDate.
+func ParseSinceDate(input string, now time.Time) (time.Time, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(input)), " ")
+	now = now.Local()
+
+	if normalized == "yesterday" {
+		return calendar.StartOfDay(now.AddDate(0, 0, -1)), nil
+	}
+
+	if weekday, ok := dueDateWeekdays[normalized]; ok {
+		return calendar.StartOfDay(now.AddDate(0, 0, -daysSinceWeekday(now, weekday))), nil
+	}
+
+	if day, ok := parseDueDateForm(normalized, now, true); ok {
+		return calendar.StartOfDay(day), nil
+	}
+
+	return ParseDueDate(input, now)
+}
+
+// DueDateHelp describes the forms ParseDueDate understands,
+// as a list with some examples of each
+func DueDateHelp() string {
+	builder := strings.Builder{}
+	builder.WriteString("Due dates are in local time, and can be given as:\n")
+
+	for _, form := range dueDateForms {
+		fmt.Fprintf(&builder, "* %s: %s\n", strings.Join(form.examples, ", "), form.description)
+	}
+
+	builder.WriteString("* a time, such as 3pm, 3:30pm, 15:00 or noon: that time today\n")
+	builder.WriteString("\n")
+	builder.WriteString("A time can also be given after today, tomorrow, a day or a number of days, weeks or months, ")
+	builder.WriteString("such as \"friday 3pm\", \"next tuesday at 09:30\" or \"+3d noon\".\n")
+	builder.WriteString("Anything else is read as a date and time, such as \"2026-10-23 15:00\".\n")
+
+	return builder.String()
+}
+
+func parseDueDateForm(input string, now time.Time, takesTime bool) (time.Time, bool) {
+	for _, form := range dueDateForms {
+		if takesTime && !form.takesTime {
+			continue
 		}
 
-		return date, nil
+		if matched := form.pattern.FindStringSubmatch(input); matched != nil {
+			return form.resolve(matched, now), true
+		}
 	}
+
+	return time.Time{}, false
+}
+
+// parseDueDateTime returns the hour and minute of a time matched by regexDueDateTime
+func parseDueDateTime(matched []string) (int, int, error) {
+	if matched[7] != "" {
+		return 12, 0, nil
+	}
+
+	hourInput, minuteInput := matched[2], matched[3]
+	if hourInput == "" {
+		hourInput, minuteInput = matched[5], matched[6]
+	}
+
+	hour, _ := strconv.Atoi(hourInput)
+	minute := 0
+	if minuteInput != "" {
+		minute, _ = strconv.Atoi(minuteInput)
+	}
+
+	switch matched[4] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid due date time: %d%s isn't a time", hour, matched[4])
+		}
+		hour = hour % 12
+		if matched[4] == "pm" {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid due date time: %02d:%02d isn't a time", hour, minute)
+	}
+
+	return hour, minute, nil
+}
+
+func parseDateTime(input string) (time.Time, error) {
+	date, err := dateparse.ParseLocal(input)
+	if err != nil {
+		return time.Unix(0, 0), fmt.Errorf("invalid due date format: %s", err)
+	}
+
+	return date, nil
+}
+
+func endOfDay(t time.Time) time.Time {
+	return carbon.Time2Carbon(t).EndOfDay().Carbon2Time()
+}
+
+// daysUntilWeekday returns the number of days until the next time the
+// weekday comes around. Today counts, unless afterToday is true.
+func daysUntilWeekday(now time.Time, weekday time.Weekday, afterToday bool) int {
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	if days == 0 && afterToday {
+		days = 7
+	}
+
+	return days
+}
+
+// daysSinceWeekday returns the number of days since the last
+// time the weekday came around, which is 0 when it's today
+func daysSinceWeekday(now time.Time, weekday time.Weekday) int {
+	return (int(now.Weekday()) - int(weekday) + 7) % 7
 }
@@ -10,10 +10,20 @@ import (
 	"github.com/golang-module/carbon/v2"
 )
 
+func endOf(year int, month time.Month, day int) time.Time {
+	return carbon.Time2Carbon(time.Date(year, month, day, 0, 0, 0, 0, time.Local)).EndOfDay().Carbon2Time()
+}
+
+func at(year int, month time.Month, day int, hour int, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+}
+
 var _ = Describe("Duedate", func() {
+	now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
 	DescribeTable("ParseDueDate",
 		func(input string, expected time.Time, shouldError bool) {
-			actual, err := ParseDueDate(input)
+			actual, err := ParseDueDate(input, now)
 
 			if shouldError {
 				Expect(err).To(HaveOccurred())
@@ -23,15 +33,76 @@ var _ = Describe("Duedate", func() {
 
 			Expect(actual).To(Equal(expected))
 		},
-		Entry("using @today", "@today", carbon.Now().EndOfDay().Carbon2Time(), false),
-		Entry("using @tod", "@tod", carbon.Now().EndOfDay().Carbon2Time(), false),
-		Entry("using @tomorrow", "@tomorrow", carbon.Tomorrow().EndOfDay().Carbon2Time(), false),
-		Entry("using @tom", "@tom", carbon.Tomorrow().EndOfDay().Carbon2Time(), false),
-		Entry("using @tmrw", "@tmrw", carbon.Tomorrow().EndOfDay().Carbon2Time(), false),
+		Entry("using @today", "@today", carbon.Time2Carbon(now).EndOfDay().Carbon2Time(), false),
+		Entry("using @tod", "@tod", carbon.Time2Carbon(now).EndOfDay().Carbon2Time(), false),
+		Entry("using @tomorrow", "@tomorrow", carbon.Time2Carbon(now).AddDay().EndOfDay().Carbon2Time(), false),
+		Entry("using @tom", "@tom", carbon.Time2Carbon(now).AddDay().EndOfDay().Carbon2Time(), false),
+		Entry("using @tmrw", "@tmrw", carbon.Time2Carbon(now).AddDay().EndOfDay().Carbon2Time(), false),
+
+		Entry("using today", "today", carbon.Time2Carbon(now).EndOfDay().Carbon2Time(), false),
+		Entry("using tomorrow", "Tomorrow", carbon.Time2Carbon(now).AddDay().EndOfDay().Carbon2Time(), false),
+
+		Entry("using a day, for the next time it comes around", "friday", endOf(2022, time.October, 21), false),
+		Entry("using a short day", "fri", endOf(2022, time.October, 21), false),
+		Entry("using today's day, for today", "monday", endOf(2022, time.October, 17), false),
+		Entry("using next and a day, for the next time it comes around after today", "next monday", endOf(2022, time.October, 24), false),
+		Entry("using next and a day later in the week", "next  Tuesday", endOf(2022, time.October, 18), false),
+
+		Entry("using in and a number of days", "in 2 days", endOf(2022, time.October, 19), false),
+		Entry("using in and a number of weeks", "in 1 week", endOf(2022, time.October, 24), false),
+		Entry("using in and a number of months", "in 3 months", endOf(2023, time.January, 17), false),
+		Entry("using a number of days", "+3d", endOf(2022, time.October, 20), false),
+		Entry("using a number of weeks", "+2w", endOf(2022, time.October, 31), false),
+		Entry("using in and a number of hours", "in 2 hours", at(2022, time.October, 17, 11, 30), false),
+		Entry("using in and a number of minutes", "in 45 minutes", at(2022, time.October, 17, 10, 15), false),
+		Entry("using a number of hours", "+4h", at(2022, time.October, 17, 13, 30), false),
+
+		Entry("using end of day", "end of day", endOf(2022, time.October, 17), false),
+		Entry("using eod", "eod", endOf(2022, time.October, 17), false),
+		Entry("using end of week, for Sunday", "end of the week", endOf(2022, time.October, 23), false),
+		Entry("using eow", "eow", endOf(2022, time.October, 23), false),
+		Entry("using end of month", "end of month", endOf(2022, time.October, 31), false),
+		Entry("using eom", "eom", endOf(2022, time.October, 31), false),
+		Entry("using eoy", "eoy", endOf(2022, time.December, 31), false),
+
+		Entry("using a time, for today", "3pm", at(2022, time.October, 17, 15, 0), false),
+		Entry("using a 24 hour time", "at 15:45", at(2022, time.October, 17, 15, 45), false),
+		Entry("using noon", "noon", at(2022, time.October, 17, 12, 0), false),
+		Entry("using a day and a time", "friday 3:30pm", at(2022, time.October, 21, 15, 30), false),
+		Entry("using next, a day and a time", "next tuesday 3pm", at(2022, time.October, 18, 15, 0), false),
+		Entry("using tomorrow at a time", "tomorrow at 9am", at(2022, time.October, 18, 9, 0), false),
+		Entry("using 12am", "@tomorrow 12am", at(2022, time.October, 18, 0, 0), false),
+		Entry("using a number of days and a time", "+3d noon", at(2022, time.October, 20, 12, 0), false),
+		Entry("using a time which doesn't exist", "friday 13pm", time.Unix(0, 0), true),
 
 		// Deliberately not testing a variety of date strings.
 		// If any of the above shortcuts aren't used, it falls back to using
 		// github.com/araddon/dateparse for date parsing
 		Entry("using a date string", "2022-01-01T14:12:11", time.Date(2022, 1, 1, 14, 12, 11, 0, time.Local), false),
+		Entry("using a date and time string", "2022-10-21 15:00", time.Date(2022, 10, 21, 15, 0, 0, 0, time.Local), false),
+		Entry("using something which isn't a date", "someday", time.Unix(0, 0), true),
 	)
+
+	DescribeTable("ParseSinceDate",
+		func(input string, expected time.Time) {
+			actual, err := ParseSinceDate(input, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("using today, for the start of today", "today", at(2022, time.October, 17, 0, 0)),
+		Entry("using yesterday, for the start of yesterday", "Yesterday", at(2022, time.October, 16, 0, 0)),
+		Entry("using today's day, for the start of today", "monday", at(2022, time.October, 17, 0, 0)),
+		Entry("using a day, for the last time it came around", "fri", at(2022, time.October, 14, 0, 0)),
+		Entry("using a day and a time", "today 9am", at(2022, time.October, 17, 9, 0)),
+		Entry("using a number of hours", "in 2 hours", at(2022, time.October, 17, 11, 30)),
+		Entry("using a date string", "2022-10-01", at(2022, time.October, 1, 0, 0)),
+	)
+
+	It("describes every form in DueDateHelp", func() {
+		help := DueDateHelp()
+
+		for _, example := range []string{"@tomorrow", "friday", "next tuesday", "in 2 days", "+3d", "eom", "end of week", "3pm"} {
+			Expect(help).To(ContainSubstring(example))
+		}
+	})
 })
@@ -0,0 +1,34 @@
+package todo
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hako/durafmt"
+)
+
+// ParseDuration parses a duration given in a human readable form, e.g. '30m' or '1h10m'
+func ParseDuration(input string) (time.Duration, error) {
+	parsedDuration, err := durafmt.ParseString(input)
+	if err != nil {
+		return 0, err
+	}
+
+	return parsedDuration.Duration(), nil
+}
+
+// FormatDuration formats a duration in the form ParseDuration
+// accepts, without any trailing zero units, e.g. '1h10m'
+func FormatDuration(duration time.Duration) string {
+	formatted := duration.String()
+
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+
+	return formatted
+}
@@ -0,0 +1,68 @@
+package todo
+
+import "time"
+
+// Filters decide which items a collection keeps. They
+// can be passed to Filter, and combined with AnyOf.
+
+// IsIncomplete matches the items which aren't complete
+func IsIncomplete(item *TodoItem) bool {
+	return !item.Completed
+}
+
+// HasNoDueDate matches the items without a due date
+func HasNoDueDate(item *TodoItem) bool {
+	return item.DueDate == nil
+}
+
+// IsOverdueAt returns a filter which matches the items which
+// aren't complete, and were due before the given time
+func IsOverdueAt(now time.Time) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		return !item.Completed && item.DueDate != nil && item.DueDate.Before(now)
+	}
+}
+
+// DueBefore returns a filter which matches the items due before the given time
+func DueBefore(t time.Time) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		return item.DueDate != nil && item.DueDate.Before(t)
+	}
+}
+
+// DueAfter returns a filter which matches the items due after the given time
+func DueAfter(t time.Time) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		return item.DueDate != nil && item.DueDate.After(t)
+	}
+}
+
+// HasDurationAtMost returns a filter which matches the items which
+// take no longer than the given duration. Items without a duration
+// aren't matched, because how long they take isn't known.
+func HasDurationAtMost(max time.Duration) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		return item.Duration != nil && *item.Duration <= max
+	}
+}
+
+// CompletedSince returns a filter which matches the
+// items which were completed after the given time
+func CompletedSince(t time.Time) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		return item.Completed && item.CompletedAt != nil && item.CompletedAt.After(t)
+	}
+}
+
+// AnyOf returns a filter which matches the items matched by any of the filters
+func AnyOf(filters ...func(*TodoItem) bool) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		for _, filter := range filters {
+			if filter(item) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
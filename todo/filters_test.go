@@ -0,0 +1,57 @@
+package todo_test
+
+import (
+	"time"
+
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filters", func() {
+	now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+	yesterday := now.AddDate(0, 0, -1)
+	tomorrow := now.AddDate(0, 0, 1)
+	short := 15 * time.Minute
+	long := 2 * time.Hour
+
+	overdue := &TodoItem{Id: 1, DueDate: &yesterday, Duration: &short}
+	completedOverdue := &TodoItem{Id: 2, DueDate: &yesterday, Completed: true, CompletedAt: &now}
+	dueTomorrow := &TodoItem{Id: 3, DueDate: &tomorrow, Duration: &long}
+	noDueDate := &TodoItem{Id: 4, Completed: true, CompletedAt: &yesterday}
+	collection := NewTodoItemCollection([]*TodoItem{overdue, completedOverdue, dueTomorrow, noDueDate})
+
+	It("IsIncomplete matches items which aren't complete", func() {
+		Expect(collection.Filter(IsIncomplete).Enumerate()).To(ConsistOf(overdue, dueTomorrow))
+	})
+
+	It("HasNoDueDate matches items without a due date", func() {
+		Expect(collection.Filter(HasNoDueDate).Enumerate()).To(ConsistOf(noDueDate))
+	})
+
+	It("IsOverdueAt matches incomplete items which were due before the time", func() {
+		Expect(collection.Filter(IsOverdueAt(now)).Enumerate()).To(ConsistOf(overdue))
+	})
+
+	It("DueBefore and DueAfter match items due before and after the time, and not those without a due date", func() {
+		Expect(collection.Filter(DueBefore(now)).Enumerate()).To(ConsistOf(overdue, completedOverdue))
+		Expect(collection.Filter(DueAfter(now)).Enumerate()).To(ConsistOf(dueTomorrow))
+	})
+
+	It("HasDurationAtMost matches items which take no longer than the duration, and not those without one", func() {
+		Expect(collection.Filter(HasDurationAtMost(15 * time.Minute)).Enumerate()).To(ConsistOf(overdue))
+		Expect(collection.Filter(HasDurationAtMost(3 * time.Hour)).Enumerate()).To(ConsistOf(overdue, dueTomorrow))
+	})
+
+	It("CompletedSince matches items completed after the time", func() {
+		Expect(collection.Filter(CompletedSince(now.Add(-time.Hour))).Enumerate()).To(ConsistOf(completedOverdue))
+	})
+
+	It("AnyOf matches items matched by any of the filters", func() {
+		Expect(collection.Filter(AnyOf(IsIncomplete, CompletedSince(now.Add(-time.Hour)))).Enumerate()).To(ConsistOf(overdue, completedOverdue, dueTomorrow))
+	})
+
+	It("can be chained to match items matched by all of the filters", func() {
+		Expect(collection.Filter(IsIncomplete).Filter(DueAfter(now)).Enumerate()).To(ConsistOf(dueTomorrow))
+	})
+})
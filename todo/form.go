@@ -0,0 +1,167 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const editFormDueDateLayout = "2006-01-02 15:04"
+
+const editFormHeader = `# Edit todo item %d, then save and close the editor.
+# Leave the due date, duration or recurrence empty to clear it.
+# Give each link on a line of its own, or leave a single empty link to remove them all.
+# Everything after 'notes:' is kept as the notes.
+# Lines starting with '#' are ignored, except in the notes.
+`
+
+// FormatEditForm formats the editable fields of a todo item as
+// a form, which can be read back in with ParseEditForm
+func FormatEditForm(item TodoItem) string {
+	due := ""
+	if item.DueDate != nil {
+		due = item.DueDate.Local().Format(editFormDueDateLayout)
+	}
+
+	duration := ""
+	if item.Duration != nil {
+		duration = FormatDuration(*item.Duration)
+	}
+
+	priority := item.Priority
+	if !priority.IsValid() {
+		priority = DefaultPriority
+	}
+
+	every := ""
+	if item.Recurrence != nil {
+		every = *item.Recurrence
+	}
+
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, editFormHeader, item.Id)
+	fmt.Fprintf(&builder, "action: %s\n", item.Action)
+	fmt.Fprintf(&builder, "due: %s\n", due)
+	fmt.Fprintf(&builder, "duration: %s\n", duration)
+	fmt.Fprintf(&builder, "priority: %s\n", priority)
+	fmt.Fprintf(&builder, "every: %s\n", every)
+
+	if len(item.Links) == 0 {
+		fmt.Fprintln(&builder, "link:")
+	}
+	for _, link := range item.Links {
+		fmt.Fprintf(&builder, "link: %s\n", link)
+	}
+
+	fmt.Fprintf(&builder, "notes:\n%s\n", item.Notes)
+
+	return builder.String()
+}
+
+// ParseEditForm reads a form written by FormatEditForm, and returns
+// a copy of the item with the fields from the form. Fields which are
+// missing from the form are left as they are. The links in the form
+// replace the item's links, when there are any 'link:' lines.
+func ParseEditForm(form string, item TodoItem, now time.Time) (TodoItem, error) {
+	lines := strings.Split(form, "\n")
+	var links []string
+
+	for number, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if !found {
+			return TodoItem{}, fmt.Errorf("line %d: expected 'field: value'", number+1)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "action":
+			if value == "" {
+				return TodoItem{}, fmt.Errorf("line %d: action cannot be empty", number+1)
+			}
+			item.Action = value
+
+		case "due":
+			if value == "" {
+				item.DueDate = nil
+				continue
+			}
+
+			dueDate, err := ParseDueDate(value, now)
+			if err != nil {
+				return TodoItem{}, fmt.Errorf("line %d: %s", number+1, err)
+			}
+			item.DueDate = &dueDate
+
+		case "duration":
+			if value == "" {
+				item.Duration = nil
+				continue
+			}
+
+			duration, err := ParseDuration(value)
+			if err != nil {
+				return TodoItem{}, fmt.Errorf("line %d: %s", number+1, err)
+			}
+			item.Duration = &duration
+
+		case "priority":
+			priority, err := ParsePriority(value)
+			if err != nil {
+				return TodoItem{}, fmt.Errorf("line %d: %s", number+1, err)
+			}
+			item.Priority = priority
+
+		case "every":
+			if value == "" {
+				item.Recurrence = nil
+				continue
+			}
+
+			recurrence, err := ParseRecurrence(value)
+			if err != nil {
+				return TodoItem{}, fmt.Errorf("line %d: %s", number+1, err)
+			}
+			item.Recurrence = &recurrence
+
+		case "link":
+			if links == nil {
+				links = []string{}
+			}
+
+			if value == "" {
+				continue
+			}
+
+			link, err := ParseLink(value)
+			if err != nil {
+				return TodoItem{}, fmt.Errorf("line %d: %s", number+1, err)
+			}
+			links = append(links, link)
+
+		case "notes":
+			notes := append([]string{value}, lines[number+1:]...)
+			item.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+			return withLinks(item, links), nil
+
+		default:
+			return TodoItem{}, fmt.Errorf("line %d: unknown field '%s'", number+1, strings.TrimSpace(field))
+		}
+	}
+
+	return withLinks(item, links), nil
+}
+
+// withLinks replaces the item's links, unless links is nil
+func withLinks(item TodoItem, links []string) TodoItem {
+	if links != nil {
+		item.Links = []string{}
+		item.AddLinks(links)
+	}
+
+	return item
+}
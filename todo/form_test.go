@@ -0,0 +1,130 @@
+package todo_test
+
+import (
+	"time"
+
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Form", func() {
+	now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+	dueDate := time.Date(2022, time.October, 20, 17, 0, 0, 0, time.Local)
+	duration := 90 * time.Minute
+
+	item := TodoItem{
+		Id:       3,
+		Action:   "write the report",
+		DueDate:  &dueDate,
+		Duration: &duration,
+		Priority: PriorityHigh,
+	}
+
+	Describe("FormatEditForm", func() {
+		It("contains each editable field", func() {
+			form := FormatEditForm(item)
+
+			Expect(form).To(ContainSubstring("action: write the report\n"))
+			Expect(form).To(ContainSubstring("due: 2022-10-20 17:00\n"))
+			Expect(form).To(ContainSubstring("duration: 1h30m\n"))
+			Expect(form).To(ContainSubstring("priority: high\n"))
+		})
+
+		It("can be read back in without changing the item", func() {
+			parsed, err := ParseEditForm(FormatEditForm(item), item, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Action).To(Equal(item.Action))
+			Expect(*parsed.DueDate).To(BeTemporally("==", dueDate))
+			Expect(*parsed.Duration).To(Equal(duration))
+			Expect(parsed.Priority).To(Equal(PriorityHigh))
+		})
+
+		It("can read notes and links back in without changing them", func() {
+			withNotes := item
+			withNotes.Notes = "first line\n\nthird line"
+			withNotes.Links = []string{"https://example.com/pull/1", "https://example.com/issues/2"}
+
+			parsed, err := ParseEditForm(FormatEditForm(withNotes), withNotes, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Notes).To(Equal(withNotes.Notes))
+			Expect(parsed.Links).To(Equal(withNotes.Links))
+		})
+	})
+
+	Describe("ParseEditForm", func() {
+		It("changes the fields in the form, ignoring comments", func() {
+			form := "# a comment\naction: write the summary\ndue: @tomorrow\nduration: 45m\npriority: 5\n"
+
+			parsed, err := ParseEditForm(form, item, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Id).To(Equal(3))
+			Expect(parsed.Action).To(Equal("write the summary"))
+			Expect(*parsed.DueDate).To(BeTemporally("==", time.Date(2022, time.October, 18, 23, 59, 59, 999999999, time.Local)))
+			Expect(*parsed.Duration).To(Equal(45 * time.Minute))
+			Expect(parsed.Priority).To(Equal(PriorityLowest))
+		})
+
+		It("clears the due date and duration when they are empty", func() {
+			parsed, err := ParseEditForm("due:\nduration: \n", item, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.DueDate).To(BeNil())
+			Expect(parsed.Duration).To(BeNil())
+			Expect(parsed.Action).To(Equal(item.Action))
+		})
+
+		It("sets and clears the recurrence", func() {
+			parsed, err := ParseEditForm("every: weekday\n", item, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*parsed.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"))
+
+			parsed, err = ParseEditForm("every:\n", parsed, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Recurrence).To(BeNil())
+		})
+
+		It("replaces the links with the ones in the form, and keeps everything after notes", func() {
+			withLinks := item
+			withLinks.Links = []string{"https://example.com/pull/1"}
+
+			form := "link: https://example.com/pull/2\nlink: https://example.com/issues/3\nnotes: first line\n\n# not a comment\nlast line\n"
+			parsed, err := ParseEditForm(form, withLinks, now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Links).To(Equal([]string{"https://example.com/pull/2", "https://example.com/issues/3"}))
+			Expect(parsed.Notes).To(Equal("first line\n\n# not a comment\nlast line"))
+		})
+
+		It("removes every link when there is a single empty link, and keeps them when there are no links", func() {
+			withLinks := item
+			withLinks.Links = []string{"https://example.com/pull/1"}
+
+			parsed, err := ParseEditForm("link:\n", withLinks, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Links).To(BeEmpty())
+
+			parsed, err = ParseEditForm("action: foo\n", withLinks, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Links).To(Equal(withLinks.Links))
+		})
+
+		DescribeTable("returns an error for invalid forms",
+			func(form string, message string) {
+				_, err := ParseEditForm(form, item, now)
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("an empty action", "action:\n", "action cannot be empty"),
+			Entry("an invalid due date", "due: not a date\n", "invalid due date"),
+			Entry("an invalid duration", "duration: 1d\n", "unknown unit"),
+			Entry("an invalid priority", "priority: urgent\n", "invalid priority"),
+			Entry("an invalid recurrence", "every: blue moon\n", "invalid recurrence"),
+			Entry("an invalid link", "link: not a link\n", "invalid link"),
+			Entry("an unknown field", "colour: blue\n", "unknown field 'colour'"),
+			Entry("a line without a field", "just some text\n", "line 1"),
+		)
+	})
+})
@@ -0,0 +1,85 @@
+package todo
+
+// TodoItemTreeNode is an item in a collection, with how
+// deep it is in the tree of subtasks the collection makes
+type TodoItemTreeNode struct {
+	Item  *TodoItem
+	Depth int
+}
+
+// Subtasks returns the items in the collection whose parent is the item with the id
+func (c *TodoItemCollection) Subtasks(id int) []*TodoItem {
+	subtasks := []*TodoItem{}
+	for _, item := range c.items {
+		if item.ParentId != nil && *item.ParentId == id && item.Id != id {
+			subtasks = append(subtasks, item)
+		}
+	}
+
+	return subtasks
+}
+
+// HasIncompleteSubtasks returns true when any of the
+// item's subtasks in the collection aren't complete
+func (c *TodoItemCollection) HasIncompleteSubtasks(id int) bool {
+	for _, subtask := range c.Subtasks(id) {
+		if !subtask.Completed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Leaves returns the items which don't have any incomplete subtasks.
+// The work of the other items is done through their subtasks.
+func (c *TodoItemCollection) Leaves() *TodoItemCollection {
+	return c.Filter(func(item *TodoItem) bool {
+		return !c.HasIncompleteSubtasks(item.Id)
+	})
+}
+
+// Tree orders the items so that each is followed by its subtasks,
+// keeping the order of the collection amongst siblings. Items whose
+// parent isn't in the collection are at the top of the tree.
+func (c *TodoItemCollection) Tree() []TodoItemTreeNode {
+	inCollection := map[int]bool{}
+	for _, item := range c.items {
+		inCollection[item.Id] = true
+	}
+
+	roots := []*TodoItem{}
+	for _, item := range c.items {
+		if item.ParentId == nil || !inCollection[*item.ParentId] {
+			roots = append(roots, item)
+		}
+	}
+
+	nodes := []TodoItemTreeNode{}
+	visited := map[*TodoItem]bool{}
+
+	var visit func(item *TodoItem, depth int)
+	visit = func(item *TodoItem, depth int) {
+		if visited[item] {
+			return
+		}
+		visited[item] = true
+
+		nodes = append(nodes, TodoItemTreeNode{Item: item, Depth: depth})
+		for _, subtask := range c.Subtasks(item.Id) {
+			visit(subtask, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		visit(root, 0)
+	}
+
+	// Items in a loop of parents have no root to be
+	// reached from, but still need to be in the tree
+	for _, item := range c.items {
+		visit(item, 0)
+	}
+
+	return nodes
+}
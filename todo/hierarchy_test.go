@@ -0,0 +1,82 @@
+package todo_test
+
+import (
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Hierarchy", func() {
+	parentOf := func(item *TodoItem) *int {
+		return &item.Id
+	}
+
+	var (
+		project    *TodoItem
+		design     *TodoItem
+		build      *TodoItem
+		buildApi   *TodoItem
+		other      *TodoItem
+		collection *TodoItemCollection
+	)
+
+	BeforeEach(func() {
+		project = &TodoItem{Id: 1, Action: "project"}
+		other = &TodoItem{Id: 2, Action: "other"}
+		design = &TodoItem{Id: 3, Action: "design", ParentId: parentOf(project), Completed: true}
+		build = &TodoItem{Id: 4, Action: "build", ParentId: parentOf(project)}
+		buildApi = &TodoItem{Id: 5, Action: "build the api", ParentId: parentOf(build)}
+
+		collection = NewTodoItemCollection([]*TodoItem{buildApi, project, other, design, build})
+	})
+
+	Describe("Subtasks", func() {
+		It("returns the items whose parent is the item", func() {
+			Expect(collection.Subtasks(project.Id)).To(Equal([]*TodoItem{design, build}))
+			Expect(collection.Subtasks(other.Id)).To(BeEmpty())
+		})
+	})
+
+	Describe("Leaves", func() {
+		It("returns the items without incomplete subtasks", func() {
+			Expect(collection.Leaves().Enumerate()).To(Equal([]*TodoItem{buildApi, other, design}))
+		})
+
+		It("includes items once all of their subtasks are complete", func() {
+			buildApi.Completed = true
+			build.Completed = true
+
+			Expect(collection.Leaves().Enumerate()).To(ContainElement(project))
+		})
+	})
+
+	Describe("Tree", func() {
+		It("puts each item before its subtasks, with their depths", func() {
+			tree := collection.Tree()
+
+			items := []*TodoItem{}
+			depths := []int{}
+			for _, node := range tree {
+				items = append(items, node.Item)
+				depths = append(depths, node.Depth)
+			}
+
+			Expect(items).To(Equal([]*TodoItem{project, design, build, buildApi, other}))
+			Expect(depths).To(Equal([]int{0, 1, 1, 2, 0}))
+		})
+
+		It("puts items whose parent isn't in the collection at the top", func() {
+			tree := NewTodoItemCollection([]*TodoItem{buildApi, other}).Tree()
+
+			Expect(tree).To(Equal([]TodoItemTreeNode{{Item: buildApi, Depth: 0}, {Item: other, Depth: 0}}))
+		})
+
+		It("still contains items in a loop of parents", func() {
+			a := &TodoItem{Id: 10}
+			b := &TodoItem{Id: 11, ParentId: parentOf(a)}
+			a.ParentId = parentOf(b)
+
+			Expect(NewTodoItemCollection([]*TodoItem{a, b}).Tree()).To(HaveLen(2))
+		})
+	})
+})
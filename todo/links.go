@@ -0,0 +1,75 @@
+package todo
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// ParseLink checks that the input is an absolute URL, such as
+// the link to a pull request or ticket, and returns it trimmed
+func ParseLink(input string) (string, error) {
+	input = strings.TrimSpace(input)
+
+	parsed, err := url.Parse(input)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid link '%s', expected a URL such as https://example.com/pull/1", input)
+	}
+
+	return input, nil
+}
+
+// ParseLinks parses each of the inputs with ParseLink, leaving out duplicates
+func ParseLinks(inputs []string) ([]string, error) {
+	links := []string{}
+	for _, input := range inputs {
+		link, err := ParseLink(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+	}
+
+	return links, nil
+}
+
+// AddLinks adds each of the links which the item doesn't already have
+func (t *TodoItem) AddLinks(links []string) {
+	for _, link := range links {
+		if !slices.Contains(t.Links, link) {
+			t.Links = append(t.Links, link)
+		}
+	}
+}
+
+// RemoveLinks removes each of the links from the item
+func (t *TodoItem) RemoveLinks(links []string) {
+	kept := []string{}
+	for _, link := range t.Links {
+		if !slices.Contains(links, link) {
+			kept = append(kept, link)
+		}
+	}
+
+	t.Links = kept
+}
+
+// AddNote adds the note to the end of the item's notes, on a line of its own
+func (t *TodoItem) AddNote(note string) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return
+	}
+
+	if t.Notes == "" {
+		t.Notes = note
+		return
+	}
+
+	t.Notes = t.Notes + "\n" + note
+}
@@ -0,0 +1,36 @@
+package todo_test
+
+import (
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Links", func() {
+	Describe("ParseLinks", func() {
+		It("trims links and leaves out duplicates", func() {
+			links, err := ParseLinks([]string{" https://example.com/pull/1", "https://example.com/pull/1", "https://example.com/pull/2"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(links).To(Equal([]string{"https://example.com/pull/1", "https://example.com/pull/2"}))
+		})
+
+		It("returns an error for links which aren't absolute URLs", func() {
+			_, err := ParseLinks([]string{"https://example.com/pull/1", "example.com/pull/2"})
+
+			Expect(err).To(MatchError(ContainSubstring("invalid link 'example.com/pull/2'")))
+		})
+	})
+
+	Describe("AddNote", func() {
+		It("adds each note on a line of its own, ignoring empty notes", func() {
+			item := TodoItem{}
+
+			item.AddNote("first")
+			item.AddNote("  ")
+			item.AddNote("second ")
+
+			Expect(item.Notes).To(Equal("first\nsecond"))
+		})
+	})
+})
@@ -9,19 +9,67 @@ type TodoItem struct {
 	Duration    *time.Duration
 	Completed   bool
 	CompletedAt *time.Time `db:"completed_at"`
+	Priority    Priority
+	ArchivedAt  *time.Time `db:"archived_at"`
+
+	// Recurrence is the RRULE which the due date
+	// of the item's next instance is found with
+	Recurrence *string
+
+	// ParentId is the id of the item this is a subtask of
+	ParentId *int `db:"parent_id"`
+
+	// Notes are free text, which can span many lines
+	Notes string
+
+	// Links are URLs the item relates to, such as pull
+	// requests or tickets, in the order they were added
+	Links []string `db:"-"`
+
+	// Tags are stored in their own table, and are always normalized
+	Tags []string `db:"-"`
+
+	// BlockedBy are the ids of the items which must be completed
+	// before this one can be started. Items which are already
+	// complete, or have been archived, are left out.
+	BlockedBy []int `db:"-"`
 }
 
-func (t *TodoItem) IsOverdue() bool {
+// IsOverdue returns true when the item was due before now
+func (t *TodoItem) IsOverdue(now time.Time) bool {
 	if t.DueDate == nil {
 		return false
 	}
 
-	return t.DueDate.Before(time.Now())
+	return t.DueDate.Before(now)
 }
 
-func (t *TodoItem) Complete() {
-	t.Completed = true
+// IsBlocked returns true when the item is waiting on other items
+func (t *TodoItem) IsBlocked() bool {
+	return len(t.BlockedBy) > 0
+}
 
-	now := time.Now()
+// Complete marks the item as complete, having been completed now
+func (t *TodoItem) Complete(now time.Time) {
+	t.Completed = true
 	t.CompletedAt = &now
-}
\ No newline at end of file
+}
+
+// Reopen undoes Complete
+func (t *TodoItem) Reopen() {
+	t.Completed = false
+	t.CompletedAt = nil
+}
+
+func (t *TodoItem) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// Archive archives the item, having been archived now
+func (t *TodoItem) Archive(now time.Time) {
+	t.ArchivedAt = &now
+}
+
+func (t *TodoItem) Unarchive() {
+	t.ArchivedAt = nil
+}
@@ -1,6 +1,8 @@
 package todo_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -8,7 +10,20 @@ import (
 )
 
 var _ = Describe("Model", func() {
+	now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
 	Describe("TodoItem", func() {
+		Describe("IsOverdue", func() {
+			It("is true when the item was due before now", func() {
+				earlier := now.Add(-time.Minute)
+				later := now.Add(time.Minute)
+
+				Expect((&todo.TodoItem{DueDate: &earlier}).IsOverdue(now)).To(BeTrue())
+				Expect((&todo.TodoItem{DueDate: &later}).IsOverdue(now)).To(BeFalse())
+				Expect((&todo.TodoItem{}).IsOverdue(now)).To(BeFalse())
+			})
+		})
+
 		Describe("Complete", func() {
 			It("sets the completed at date at the same time as the completed flag", func(){
 				item := todo.TodoItem{}
@@ -16,10 +31,37 @@ var _ = Describe("Model", func() {
 				Expect(item.Completed).To(BeFalse())
 				Expect(item.CompletedAt).To(BeNil())
 
-				item.Complete()
+				item.Complete(now)
 
 				Expect(item.Completed).To(BeTrue())
-				Expect(item.CompletedAt).ToNot(BeNil())
+				Expect(item.CompletedAt).To(Equal(&now))
+			})
+		})
+
+		Describe("Reopen", func() {
+			It("clears the completed flag and completed at date", func() {
+				item := todo.TodoItem{}
+				item.Complete(now)
+
+				item.Reopen()
+
+				Expect(item.Completed).To(BeFalse())
+				Expect(item.CompletedAt).To(BeNil())
+			})
+		})
+
+		Describe("Archive", func() {
+			It("sets the archived at date, which Unarchive clears", func() {
+				item := todo.TodoItem{}
+				Expect(item.IsArchived()).To(BeFalse())
+
+				item.Archive(now)
+				Expect(item.IsArchived()).To(BeTrue())
+				Expect(item.ArchivedAt).To(Equal(&now))
+
+				item.Unarchive()
+				Expect(item.IsArchived()).To(BeFalse())
+				Expect(item.ArchivedAt).To(BeNil())
 			})
 		})
 	})
@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Priority is how important a todo item is, from
+// PriorityHighest (1) to PriorityLowest (5)
+type Priority int
+
+const (
+	PriorityHighest Priority = iota + 1
+	PriorityHigh
+	PriorityMedium
+	PriorityLow
+	PriorityLowest
+)
+
+// DefaultPriority is the priority of items which aren't given one
+const DefaultPriority = PriorityMedium
+
+var priorityNames = map[Priority]string{
+	PriorityHighest: "highest",
+	PriorityHigh:    "high",
+	PriorityMedium:  "medium",
+	PriorityLow:     "low",
+	PriorityLowest:  "lowest",
+}
+
+// ParsePriority parses a priority given as a number from 1 to 5, optionally
+// prefixed with "p" (e.g. "p1"), or by name (e.g. "high")
+func ParsePriority(input string) (Priority, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	for priority, name := range priorityNames {
+		if input == name {
+			return priority, nil
+		}
+	}
+
+	number, err := strconv.Atoi(strings.TrimPrefix(input, "p"))
+	if err == nil && Priority(number).IsValid() {
+		return Priority(number), nil
+	}
+
+	return 0, fmt.Errorf("invalid priority '%s', expected 1-5 or highest, high, medium, low or lowest", input)
+}
+
+func (p Priority) IsValid() bool {
+	return p >= PriorityHighest && p <= PriorityLowest
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(p))
+}
@@ -0,0 +1,29 @@
+package todo_test
+
+import (
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Priority", func() {
+	DescribeTable("ParsePriority",
+		func(input string, expected Priority, shouldError bool) {
+			actual, err := ParsePriority(input)
+
+			if shouldError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("using a number", "1", PriorityHighest, false),
+		Entry("using a p-prefixed number", "p4", PriorityLow, false),
+		Entry("using a name", "High", PriorityHigh, false),
+		Entry("using medium", "medium", PriorityMedium, false),
+		Entry("using a number out of range", "6", Priority(0), true),
+		Entry("using an unknown name", "urgent", Priority(0), true),
+	)
+})
@@ -0,0 +1,212 @@
+package todo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/golang-module/carbon/v2"
+)
+
+var regexRecurrenceInterval *regexp.Regexp = regexp.MustCompile(`^(\d+)\s*(day|week|month|year)s?$`)
+
+var recurrenceFrequencies = map[string]calendar.Frequency{
+	"day":     calendar.FrequencyDaily,
+	"daily":   calendar.FrequencyDaily,
+	"week":    calendar.FrequencyWeekly,
+	"weekly":  calendar.FrequencyWeekly,
+	"month":   calendar.FrequencyMonthly,
+	"monthly": calendar.FrequencyMonthly,
+	"year":    calendar.FrequencyYearly,
+	"yearly":  calendar.FrequencyYearly,
+}
+
+var recurrenceWeekdays = map[string]string{
+	"monday":    "MO",
+	"tuesday":   "TU",
+	"wednesday": "WE",
+	"thursday":  "TH",
+	"friday":    "FR",
+	"saturday":  "SA",
+	"sunday":    "SU",
+}
+
+// ParseRecurrence parses how often an item recurs, and returns it as an
+// RRULE. It can be given as an RRULE, such as "FREQ=WEEKLY;BYDAY=FR", or
+// in a friendly form: "day", "weekday", "week", "month", "year", the name
+// of a day (or a comma separated list of them), or an interval such as
+// "2 weeks".
+func ParseRecurrence(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	friendly := strings.ToLower(input)
+
+	rrule := ""
+	switch {
+	case strings.Contains(strings.ToUpper(input), "FREQ="):
+		rrule = strings.TrimPrefix(strings.ToUpper(input), "RRULE:")
+
+	case friendly == "weekday":
+		rrule = "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+
+	case recurrenceFrequencies[friendly] != "":
+		rrule = fmt.Sprintf("FREQ=%s", recurrenceFrequencies[friendly])
+
+	case regexRecurrenceInterval.MatchString(friendly):
+		matched := regexRecurrenceInterval.FindStringSubmatch(friendly)
+		interval, _ := strconv.Atoi(matched[1])
+		rrule = fmt.Sprintf("FREQ=%s;INTERVAL=%d", recurrenceFrequencies[matched[2]], interval)
+
+	default:
+		days := []string{}
+		for _, day := range strings.Split(friendly, ",") {
+			icalDay, ok := recurrenceWeekdays[strings.TrimSpace(day)]
+			if !ok {
+				return "", fmt.Errorf("invalid recurrence '%s', expected an RRULE, day, weekday, week, month, year, the name of a day, or an interval such as '2 weeks'", input)
+			}
+			days = append(days, icalDay)
+		}
+		rrule = fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s", strings.Join(days, ","))
+	}
+
+	rule, err := calendar.ParseRecurrenceRule(rrule, time.Local)
+	if err != nil {
+		return "", err
+	}
+
+	// Each instance of a recurring item starts the rule again from its
+	// own due date, so a count of occurrences would never be reached
+	if rule.Count > 0 {
+		return "", fmt.Errorf("COUNT can't be used in the recurrence of a todo item, use UNTIL instead")
+	}
+
+	return rrule, nil
+}
+
+// IsRecurring returns true when completing the item creates its next instance
+func (t *TodoItem) IsRecurring() bool {
+	return t.Recurrence != nil
+}
+
+// NextInstance returns the next instance of a recurring item, which is
+// a copy of the item that is due at the first occurrence of its
+// recurrence after both its due date and now. It returns false when
+// the item doesn't recur, or its recurrence has ended.
+func (t *TodoItem) NextInstance(now time.Time) (TodoItem, bool, error) {
+	if !t.IsRecurring() {
+		return TodoItem{}, false, nil
+	}
+
+	rule, err := calendar.ParseRecurrenceRule(*t.Recurrence, time.Local)
+	if err != nil {
+		return TodoItem{}, false, err
+	}
+
+	dtstart := now.Local()
+	if t.DueDate != nil {
+		dtstart = t.DueDate.Local()
+	}
+
+	after := dtstart
+	if now.After(after) {
+		after = now
+	}
+
+	dueDate, found := nextOccurrence(rule, dtstart, after)
+	if !found {
+		return TodoItem{}, false, nil
+	}
+
+	recurrence := *t.Recurrence
+	next := TodoItem{
+		Action:     t.Action,
+		DueDate:    &dueDate,
+		Duration:   t.Duration,
+		Priority:   t.Priority,
+		Recurrence: &recurrence,
+		ParentId:   t.ParentId,
+		Notes:      t.Notes,
+		Tags:       append([]string{}, t.Tags...),
+		Links:      append([]string{}, t.Links...),
+	}
+
+	return next, true, nil
+}
+
+// NextInstanceOf returns the next instance which was added to the
+// collection when the recurring item was completed, or nil when there
+// isn't one. It's only found while it's as it was added, incomplete and
+// due when the item's recurrence made it due, so an instance which has
+// since been changed is left alone.
+func (c *TodoItemCollection) NextInstanceOf(item TodoItem) (*TodoItem, error) {
+	if !item.Completed || item.CompletedAt == nil {
+		return nil, nil
+	}
+
+	expected, recurs, err := item.NextInstance(*item.CompletedAt)
+	if err != nil || !recurs {
+		return nil, err
+	}
+
+	for _, candidate := range c.items {
+		if candidate.Id <= item.Id || candidate.Completed || candidate.IsArchived() {
+			continue
+		}
+
+		if candidate.Action != expected.Action || candidate.Recurrence == nil || *candidate.Recurrence != *expected.Recurrence {
+			continue
+		}
+
+		if (candidate.ParentId == nil) != (expected.ParentId == nil) || (candidate.ParentId != nil && *candidate.ParentId != *expected.ParentId) {
+			continue
+		}
+
+		if candidate.DueDate != nil && candidate.DueDate.Equal(*expected.DueDate) {
+			return candidate, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FirstDueDate returns the due date of a recurring item which is added
+// without one. That is the end of the first day from today which matches
+// the days the recurrence picks out, or the end of today when it doesn't
+// pick out any days, such as "week".
+func FirstDueDate(rrule string, now time.Time) (time.Time, error) {
+	rule, err := calendar.ParseRecurrenceRule(rrule, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	endOfToday := carbon.Time2Carbon(now.Local()).EndOfDay().Carbon2Time()
+	if len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 && len(rule.ByMonth) == 0 {
+		return endOfToday, nil
+	}
+
+	endOfYesterday := endOfToday.AddDate(0, 0, -1)
+	dueDate, found := nextOccurrence(rule, endOfYesterday, endOfYesterday)
+	if !found {
+		return time.Time{}, fmt.Errorf("recurrence '%s' has already ended", rrule)
+	}
+
+	return dueDate, nil
+}
+
+// nextOccurrence finds the first occurrence of the rule, starting from
+// dtstart, which is after the given time
+func nextOccurrence(rule *calendar.RecurrenceRule, dtstart time.Time, after time.Time) (time.Time, bool) {
+	// Far enough ahead to find the next occurrence of
+	// any rule, including those on the 29th of February
+	end := after.AddDate(8*rule.Interval, 0, 1)
+
+	for _, occurrence := range rule.Occurrences(dtstart, end) {
+		if occurrence.After(after) {
+			return occurrence, true
+		}
+	}
+
+	return time.Time{}, false
+}
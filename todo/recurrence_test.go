@@ -0,0 +1,166 @@
+package todo_test
+
+import (
+	"time"
+
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recurrence", func() {
+	// A Monday
+	now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+	Describe("ParseRecurrence", func() {
+		DescribeTable("turns friendly forms in to RRULEs",
+			func(input string, expected string) {
+				rrule, err := ParseRecurrence(input)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rrule).To(Equal(expected))
+			},
+			Entry("day", "day", "FREQ=DAILY"),
+			Entry("weekday", "Weekday", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"),
+			Entry("month", "month", "FREQ=MONTHLY"),
+			Entry("the name of a day", "friday", "FREQ=WEEKLY;BYDAY=FR"),
+			Entry("a list of days", "monday, thursday", "FREQ=WEEKLY;BYDAY=MO,TH"),
+			Entry("an interval", "2 weeks", "FREQ=WEEKLY;INTERVAL=2"),
+			Entry("an RRULE", "RRULE:freq=weekly;byday=fr", "FREQ=WEEKLY;BYDAY=FR"),
+		)
+
+		It("returns an error for recurrences it doesn't understand", func() {
+			_, err := ParseRecurrence("fortnightly-ish")
+			Expect(err).To(MatchError(ContainSubstring("invalid recurrence 'fortnightly-ish'")))
+		})
+
+		It("does not allow COUNT", func() {
+			_, err := ParseRecurrence("FREQ=DAILY;COUNT=3")
+			Expect(err).To(MatchError(ContainSubstring("COUNT")))
+		})
+	})
+
+	Describe("NextInstance", func() {
+		duration := 15 * time.Minute
+		friday := time.Date(2022, time.October, 21, 17, 0, 0, 0, time.Local)
+		every := "FREQ=WEEKLY;BYDAY=FR"
+
+		item := TodoItem{
+			Id:         4,
+			Action:     "submit timesheet",
+			DueDate:    &friday,
+			Duration:   &duration,
+			Priority:   PriorityHigh,
+			Completed:  true,
+			Recurrence: &every,
+			Tags:       []string{"@work"},
+		}
+
+		It("copies the item, due at the next occurrence after its due date", func() {
+			next, recurs, err := item.NextInstance(now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recurs).To(BeTrue())
+			Expect(next.Id).To(Equal(0))
+			Expect(next.Completed).To(BeFalse())
+			Expect(next.Action).To(Equal("submit timesheet"))
+			Expect(*next.DueDate).To(Equal(time.Date(2022, time.October, 28, 17, 0, 0, 0, time.Local)))
+			Expect(*next.Duration).To(Equal(duration))
+			Expect(next.Priority).To(Equal(PriorityHigh))
+			Expect(*next.Recurrence).To(Equal(every))
+			Expect(next.Tags).To(Equal([]string{"@work"}))
+		})
+
+		It("skips occurrences which have already passed when the item is completed late", func() {
+			threeWeeksLater := now.AddDate(0, 0, 21)
+
+			next, _, err := item.NextInstance(threeWeeksLater)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*next.DueDate).To(Equal(time.Date(2022, time.November, 11, 17, 0, 0, 0, time.Local)))
+		})
+
+		It("does not recur once the recurrence has ended", func() {
+			until := "FREQ=WEEKLY;BYDAY=FR;UNTIL=20221025T000000"
+			ending := item
+			ending.Recurrence = &until
+
+			_, recurs, err := ending.NextInstance(now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recurs).To(BeFalse())
+		})
+
+		It("does not recur for items without a recurrence", func() {
+			_, recurs, err := (&TodoItem{Id: 1, DueDate: &friday}).NextInstance(now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recurs).To(BeFalse())
+		})
+	})
+
+	Describe("NextInstanceOf", func() {
+		friday := time.Date(2022, time.October, 21, 17, 0, 0, 0, time.Local)
+		nextFriday := time.Date(2022, time.October, 28, 17, 0, 0, 0, time.Local)
+		every := "FREQ=WEEKLY;BYDAY=FR"
+
+		completed := TodoItem{Id: 4, Action: "submit timesheet", DueDate: &friday, Completed: true, CompletedAt: &now, Recurrence: &every}
+
+		It("finds the next instance added when the item was completed", func() {
+			next := &TodoItem{Id: 7, Action: "submit timesheet", DueDate: &nextFriday, Recurrence: &every}
+			collection := NewTodoItemCollection([]*TodoItem{&completed, {Id: 5, Action: "something else"}, next})
+
+			found, err := collection.NextInstanceOf(completed)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(Equal(next))
+		})
+
+		It("does not find next instances which have been changed", func() {
+			completedNext := &TodoItem{Id: 7, Action: "submit timesheet", DueDate: &nextFriday, Recurrence: &every, Completed: true, CompletedAt: &now}
+			movedNext := &TodoItem{Id: 8, Action: "submit timesheet", DueDate: &friday, Recurrence: &every}
+			renamedNext := &TodoItem{Id: 9, Action: "submit expenses", DueDate: &nextFriday, Recurrence: &every}
+			collection := NewTodoItemCollection([]*TodoItem{&completed, completedNext, movedNext, renamedNext})
+
+			found, err := collection.NextInstanceOf(completed)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+
+		It("does not find anything for items which aren't complete", func() {
+			next := &TodoItem{Id: 7, Action: "submit timesheet", DueDate: &nextFriday, Recurrence: &every}
+			incomplete := completed
+			incomplete.Completed = false
+			incomplete.CompletedAt = nil
+
+			found, err := NewTodoItemCollection([]*TodoItem{&incomplete, next}).NextInstanceOf(incomplete)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+	})
+
+	Describe("FirstDueDate", func() {
+		It("is the end of the first day the recurrence picks out", func() {
+			dueDate, err := FirstDueDate("FREQ=WEEKLY;BYDAY=FR", now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dueDate.Format(time.RFC3339)).To(Equal(time.Date(2022, time.October, 21, 23, 59, 59, 0, time.Local).Format(time.RFC3339)))
+		})
+
+		It("includes today", func() {
+			dueDate, err := FirstDueDate("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dueDate.Day()).To(Equal(17))
+		})
+
+		It("is the end of today when the recurrence doesn't pick out any days", func() {
+			dueDate, err := FirstDueDate("FREQ=WEEKLY;INTERVAL=2", now)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dueDate.Day()).To(Equal(17))
+			Expect(dueDate.Hour()).To(Equal(23))
+		})
+	})
+})
@@ -17,7 +17,13 @@ type TodoRepositoryInterface interface {
 	Add(item TodoItem) (TodoItem, error)
 	Get(id int) (TodoItem, error)
 	List() (*TodoItemCollection, error)
+	ListArchived() (*TodoItemCollection, error)
 	Update(item TodoItem) (TodoItem, error)
+	Delete(id int) error
+	Tag(id int, tags []string) (TodoItem, error)
+	Untag(id int, tags []string) (TodoItem, error)
+	Block(id int, blockedBy []int) (TodoItem, error)
+	Unblock(id int, blockedBy []int) (TodoItem, error)
 }
 
 type TodoSQLRepository struct {
@@ -40,12 +46,16 @@ func (repo *TodoSQLRepository) Add(item TodoItem) (TodoItem, error) {
 				d := int(*item.Duration)
 				duration = &d
 			}
+			priority := item.Priority
+			if priority == 0 {
+				priority = DefaultPriority
+			}
 			row := tx.QueryRowx(
 				`
 				INSERT INTO todo_items
-					(action, due_date, duration, completed, completed_at)
+					(action, due_date, duration, completed, completed_at, priority, recurrence, parent_id, notes)
 				VALUES
-					(?, ?, ?, ?, ?)
+					(?, ?, ?, ?, ?, ?, ?, ?, ?)
 		
 				RETURNING *
 				`,
@@ -54,10 +64,29 @@ func (repo *TodoSQLRepository) Add(item TodoItem) (TodoItem, error) {
 				duration,
 				item.Completed,
 				item.CompletedAt,
+				priority,
+				item.Recurrence,
+				item.ParentId,
+				item.Notes,
 			)
 
 			newItem := TodoItem{}
 			err := row.StructScan(&newItem)
+			if err != nil {
+				return nil, err
+			}
+
+			err = addTags(tx, repo.ctx, newItem.Id, item.Tags)
+			if err != nil {
+				return nil, err
+			}
+
+			err = saveLinks(tx, repo.ctx, newItem.Id, item.Links)
+			if err != nil {
+				return nil, err
+			}
+
+			err = loadRelated(tx, repo.ctx, []*TodoItem{&newItem})
 			return &newItem, err
 		},
 		repo.conn,
@@ -79,12 +108,32 @@ func (repo *TodoSQLRepository) Get(id int) (TodoItem, error) {
 		return TodoItem{}, err
 	}
 
+	err = loadRelated(repo.conn, repo.ctx, []*TodoItem{&item})
+	if err != nil {
+		return TodoItem{}, err
+	}
+
 	return item, nil
 }
 
+// List lists every item which hasn't been archived
 func (repo *TodoSQLRepository) List() (*TodoItemCollection, error) {
+	return repo.list("SELECT * FROM todo_items WHERE archived_at IS NULL")
+}
+
+// ListArchived lists every item which has been archived
+func (repo *TodoSQLRepository) ListArchived() (*TodoItemCollection, error) {
+	return repo.list("SELECT * FROM todo_items WHERE archived_at IS NOT NULL")
+}
+
+func (repo *TodoSQLRepository) list(query string) (*TodoItemCollection, error) {
 	items := []*TodoItem{}
-	err := repo.conn.Select(&items, "SELECT * FROM todo_items")
+	err := repo.conn.SelectContext(repo.ctx, &items, query)
+	if err != nil {
+		return NewTodoItemCollection(nil), err
+	}
+
+	err = loadRelated(repo.conn, repo.ctx, items)
 	if err != nil {
 		return NewTodoItemCollection(nil), err
 	}
@@ -92,6 +141,9 @@ func (repo *TodoSQLRepository) List() (*TodoItemCollection, error) {
 	return NewTodoItemCollection(items), nil
 }
 
+// Update saves every field of the item, including its links, except
+// for its tags, which are changed with Tag and Untag, and the items
+// it is blocked by, which are changed with Block and Unblock
 func (repo *TodoSQLRepository) Update(item TodoItem) (TodoItem, error) {
 	updated, err := db.InTransaction(
 		func(tx *sqlx.Tx) (*TodoItem, error) {
@@ -106,7 +158,12 @@ func (repo *TodoSQLRepository) Update(item TodoItem) (TodoItem, error) {
 						due_date = ?,
 						duration = ?,
 						completed = ?,
-                         completed_at = ?
+                         completed_at = ?,
+						priority = ?,
+						archived_at = ?,
+						recurrence = ?,
+						parent_id = ?,
+						notes = ?
 					WHERE 
 						id = ?
 
@@ -117,9 +174,23 @@ func (repo *TodoSQLRepository) Update(item TodoItem) (TodoItem, error) {
 				item.Duration,
 				item.Completed,
 				item.CompletedAt,
+				item.Priority,
+				item.ArchivedAt,
+				item.Recurrence,
+				item.ParentId,
+				item.Notes,
 				item.Id,
 			).StructScan(&updatedItem)
+			if err != nil {
+				return nil, err
+			}
 
+			err = saveLinks(tx, repo.ctx, item.Id, item.Links)
+			if err != nil {
+				return nil, err
+			}
+
+			err = loadRelated(tx, repo.ctx, []*TodoItem{&updatedItem})
 			return &updatedItem, err
 		},
 		repo.conn,
@@ -132,3 +203,354 @@ func (repo *TodoSQLRepository) Update(item TodoItem) (TodoItem, error) {
 
 	return *updated, nil
 }
+
+// Delete permanently deletes the item. Its subtasks are kept, and no
+// longer have a parent. It returns ItemNotFoundError when there is no
+// item with the id.
+func (repo *TodoSQLRepository) Delete(id int) error {
+	deleted, err := db.InTransaction(
+		func(tx *sqlx.Tx) (*int64, error) {
+			_, err := tx.ExecContext(repo.ctx, "DELETE FROM todo_item_tags WHERE todo_item_id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = tx.ExecContext(repo.ctx, "DELETE FROM todo_item_dependencies WHERE todo_item_id = ? OR blocked_by_id = ?", id, id)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = tx.ExecContext(repo.ctx, "DELETE FROM todo_item_links WHERE todo_item_id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = tx.ExecContext(repo.ctx, "UPDATE todo_items SET parent_id = NULL WHERE parent_id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := tx.ExecContext(repo.ctx, "DELETE FROM todo_items WHERE id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			return &rowsAffected, err
+		},
+		repo.conn,
+		repo.ctx,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if *deleted == 0 {
+		return ItemNotFoundError
+	}
+
+	return nil
+}
+
+// Tag adds the tags to the item, creating any tags which don't exist yet
+func (repo *TodoSQLRepository) Tag(id int, tags []string) (TodoItem, error) {
+	tagged, err := db.InTransaction(
+		func(tx *sqlx.Tx) (*TodoItem, error) {
+			item := TodoItem{}
+			err := tx.GetContext(repo.ctx, &item, "SELECT * FROM todo_items WHERE id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			err = addTags(tx, repo.ctx, id, tags)
+			if err != nil {
+				return nil, err
+			}
+
+			err = loadRelated(tx, repo.ctx, []*TodoItem{&item})
+			return &item, err
+		},
+		repo.conn,
+		repo.ctx,
+	)
+
+	if err != nil {
+		return TodoItem{}, err
+	}
+
+	return *tagged, nil
+}
+
+// Untag removes the tags from the item
+func (repo *TodoSQLRepository) Untag(id int, tags []string) (TodoItem, error) {
+	untagged, err := db.InTransaction(
+		func(tx *sqlx.Tx) (*TodoItem, error) {
+			item := TodoItem{}
+			err := tx.GetContext(repo.ctx, &item, "SELECT * FROM todo_items WHERE id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, tag := range NormalizeTags(tags) {
+				_, err := tx.ExecContext(
+					repo.ctx,
+					`
+					DELETE FROM todo_item_tags
+					WHERE
+						todo_item_id = ?
+						AND tag_id IN (SELECT id FROM tags WHERE name = ?)
+					`,
+					id,
+					tag,
+				)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			err = loadRelated(tx, repo.ctx, []*TodoItem{&item})
+			return &item, err
+		},
+		repo.conn,
+		repo.ctx,
+	)
+
+	if err != nil {
+		return TodoItem{}, err
+	}
+
+	return *untagged, nil
+}
+
+// Block records that the item can't be started until
+// each of the items in blockedBy are complete
+func (repo *TodoSQLRepository) Block(id int, blockedBy []int) (TodoItem, error) {
+	return repo.changeDependencies(
+		id,
+		blockedBy,
+		"INSERT OR IGNORE INTO todo_item_dependencies (todo_item_id, blocked_by_id) VALUES (?, ?)",
+	)
+}
+
+// Unblock removes the records that the item is
+// waiting on each of the items in blockedBy
+func (repo *TodoSQLRepository) Unblock(id int, blockedBy []int) (TodoItem, error) {
+	return repo.changeDependencies(
+		id,
+		blockedBy,
+		"DELETE FROM todo_item_dependencies WHERE todo_item_id = ? AND blocked_by_id = ?",
+	)
+}
+
+// changeDependencies runs the statement for each of the ids in
+// blockedBy, with the item's id and that id as its parameters
+func (repo *TodoSQLRepository) changeDependencies(id int, blockedBy []int, statement string) (TodoItem, error) {
+	changed, err := db.InTransaction(
+		func(tx *sqlx.Tx) (*TodoItem, error) {
+			item := TodoItem{}
+			err := tx.GetContext(repo.ctx, &item, "SELECT * FROM todo_items WHERE id = ?", id)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, blockedById := range blockedBy {
+				_, err := tx.ExecContext(repo.ctx, statement, id, blockedById)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			err = loadRelated(tx, repo.ctx, []*TodoItem{&item})
+			return &item, err
+		},
+		repo.conn,
+		repo.ctx,
+	)
+
+	if err != nil {
+		return TodoItem{}, err
+	}
+
+	return *changed, nil
+}
+
+func addTags(tx *sqlx.Tx, ctx context.Context, id int, tags []string) error {
+	for _, tag := range NormalizeTags(tags) {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tag)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`
+			INSERT OR IGNORE INTO todo_item_tags
+				(todo_item_id, tag_id)
+			SELECT ?, id FROM tags WHERE name = ?
+			`,
+			id,
+			tag,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadRelated sets the fields of each of the
+// items which are stored in other tables
+func loadRelated(queryer sqlx.QueryerContext, ctx context.Context, items []*TodoItem) error {
+	err := loadTags(queryer, ctx, items)
+	if err != nil {
+		return err
+	}
+
+	err = loadBlockers(queryer, ctx, items)
+	if err != nil {
+		return err
+	}
+
+	return loadLinks(queryer, ctx, items)
+}
+
+type todoItemTag struct {
+	TodoItemId int `db:"todo_item_id"`
+	Name       string
+}
+
+// loadTags sets the tags of each of the items from the database
+func loadTags(queryer sqlx.QueryerContext, ctx context.Context, items []*TodoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemsById := map[int]*TodoItem{}
+	for _, item := range items {
+		item.Tags = []string{}
+		itemsById[item.Id] = item
+	}
+
+	// Loading every item's tags and picking out the ones needed avoids
+	// running in to SQLite's limit on the number of query parameters
+	itemTags := []todoItemTag{}
+	err := sqlx.SelectContext(
+		ctx,
+		queryer,
+		&itemTags,
+		`
+		SELECT todo_item_tags.todo_item_id, tags.name
+		FROM todo_item_tags
+		INNER JOIN tags ON tags.id = todo_item_tags.tag_id
+		ORDER BY tags.name
+		`,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, itemTag := range itemTags {
+		if item, ok := itemsById[itemTag.TodoItemId]; ok {
+			item.Tags = append(item.Tags, itemTag.Name)
+		}
+	}
+
+	return nil
+}
+
+type todoItemDependency struct {
+	TodoItemId  int `db:"todo_item_id"`
+	BlockedById int `db:"blocked_by_id"`
+}
+
+// loadBlockers sets the ids of the items each of the items is blocked
+// by. Items which are complete or archived don't block anything.
+func loadBlockers(queryer sqlx.QueryerContext, ctx context.Context, items []*TodoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemsById := map[int]*TodoItem{}
+	for _, item := range items {
+		item.BlockedBy = []int{}
+		itemsById[item.Id] = item
+	}
+
+	dependencies := []todoItemDependency{}
+	err := sqlx.SelectContext(
+		ctx,
+		queryer,
+		&dependencies,
+		`
+		SELECT todo_item_dependencies.todo_item_id, todo_item_dependencies.blocked_by_id
+		FROM todo_item_dependencies
+		INNER JOIN todo_items ON todo_items.id = todo_item_dependencies.blocked_by_id
+		WHERE
+			todo_items.completed = 0
+			AND todo_items.archived_at IS NULL
+		ORDER BY todo_item_dependencies.blocked_by_id
+		`,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range dependencies {
+		if item, ok := itemsById[dependency.TodoItemId]; ok {
+			item.BlockedBy = append(item.BlockedBy, dependency.BlockedById)
+		}
+	}
+
+	return nil
+}
+
+// saveLinks replaces the links of the item with the given links
+func saveLinks(tx *sqlx.Tx, ctx context.Context, id int, links []string) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM todo_item_links WHERE todo_item_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO todo_item_links (todo_item_id, url) VALUES (?, ?)", id, link)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type todoItemLink struct {
+	TodoItemId int `db:"todo_item_id"`
+	Url        string
+}
+
+// loadLinks sets the links of each of the items from the database
+func loadLinks(queryer sqlx.QueryerContext, ctx context.Context, items []*TodoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	itemsById := map[int]*TodoItem{}
+	for _, item := range items {
+		item.Links = []string{}
+		itemsById[item.Id] = item
+	}
+
+	links := []todoItemLink{}
+	err := sqlx.SelectContext(ctx, queryer, &links, "SELECT todo_item_id, url FROM todo_item_links ORDER BY id")
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if item, ok := itemsById[link.TodoItemId]; ok {
+			item.Links = append(item.Links, link.Url)
+		}
+	}
+
+	return nil
+}
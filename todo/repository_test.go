@@ -87,6 +87,165 @@ var _ = Describe("Repository", func() {
 		Expect(collection.Len()).To(Equal(2))
 	})
 
+	It("gives new items the default priority when they don't have one", func() {
+		addedItem, err := repo.Add(todo.TodoItem{Action: "Item 1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addedItem.Priority).To(Equal(todo.DefaultPriority))
+
+		addedItem, err = repo.Add(todo.TodoItem{Action: "Item 2", Priority: todo.PriorityHighest})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addedItem.Priority).To(Equal(todo.PriorityHighest))
+	})
+
+	It("stores the recurrence of items", func() {
+		every := "FREQ=WEEKLY;BYDAY=FR"
+		addedItem, err := repo.Add(todo.TodoItem{Action: "Item 1", Recurrence: &every})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*addedItem.Recurrence).To(Equal(every))
+
+		addedItem.Recurrence = nil
+		updatedItem, err := repo.Update(addedItem)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedItem.Recurrence).To(BeNil())
+	})
+
+	It("stores the notes and links of items", func() {
+		addedItem, err := repo.Add(todo.TodoItem{
+			Action: "Item 1",
+			Notes:  "first line\nsecond line",
+			Links:  []string{"https://example.com/pull/2", "https://example.com/pull/1"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addedItem.Notes).To(Equal("first line\nsecond line"))
+		Expect(addedItem.Links).To(Equal([]string{"https://example.com/pull/2", "https://example.com/pull/1"}))
+
+		addedItem.Notes = ""
+		addedItem.Links = []string{"https://example.com/pull/1", "https://example.com/issues/3"}
+		_, err = repo.Update(addedItem)
+		Expect(err).ToNot(HaveOccurred())
+
+		fetched, err := repo.Get(addedItem.Id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched.Notes).To(Equal(""))
+		Expect(fetched.Links).To(Equal([]string{"https://example.com/pull/1", "https://example.com/issues/3"}))
+	})
+
+	It("stores the tags of new items", func() {
+		addedItem, err := repo.Add(todo.TodoItem{Action: "Item 1", Tags: []string{"+release", "@Laptop"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addedItem.Tags).To(Equal([]string{"+release", "@laptop"}))
+
+		_, err = repo.Add(todo.TodoItem{Action: "Item 2", Tags: []string{"@laptop"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		collection, err := repo.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(collection.Enumerate()[0].Tags).To(Equal([]string{"+release", "@laptop"}))
+		Expect(collection.Enumerate()[1].Tags).To(Equal([]string{"@laptop"}))
+	})
+
+	It("can tag and untag an item", func() {
+		addedItem, err := repo.Add(todo.TodoItem{Action: "Item 1", Tags: []string{"@laptop"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		tagged, err := repo.Tag(addedItem.Id, []string{"@phone", "@laptop"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tagged.Tags).To(Equal([]string{"@laptop", "@phone"}))
+
+		untagged, err := repo.Untag(addedItem.Id, []string{"@laptop"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(untagged.Tags).To(Equal([]string{"@phone"}))
+
+		fetched, err := repo.Get(addedItem.Id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched.Tags).To(Equal([]string{"@phone"}))
+	})
+
+	It("can block and unblock an item, ignoring complete items which block it", func() {
+		deploy, err := repo.Add(todo.TodoItem{Action: "deploy"})
+		Expect(err).ToNot(HaveOccurred())
+		approval, err := repo.Add(todo.TodoItem{Action: "get approval"})
+		Expect(err).ToNot(HaveOccurred())
+		review, err := repo.Add(todo.TodoItem{Action: "get a review"})
+		Expect(err).ToNot(HaveOccurred())
+
+		blocked, err := repo.Block(deploy.Id, []int{approval.Id, review.Id})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blocked.BlockedBy).To(Equal([]int{approval.Id, review.Id}))
+
+		review.Complete(time.Now())
+		_, err = repo.Update(review)
+		Expect(err).ToNot(HaveOccurred())
+
+		fetched, err := repo.Get(deploy.Id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fetched.BlockedBy).To(Equal([]int{approval.Id}))
+
+		unblocked, err := repo.Unblock(deploy.Id, []int{approval.Id})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unblocked.IsBlocked()).To(BeFalse())
+	})
+
+	It("does not tag items which don't exist", func() {
+		_, err := repo.Tag(999, []string{"@phone"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does not list archived items, except when listing archived items", func() {
+		archived, err := repo.Add(todo.TodoItem{Action: "Item 1"})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = repo.Add(todo.TodoItem{Action: "Item 2"})
+		Expect(err).ToNot(HaveOccurred())
+
+		archived.Archive(time.Now())
+		_, err = repo.Update(archived)
+		Expect(err).ToNot(HaveOccurred())
+
+		collection, err := repo.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(collection.Len()).To(Equal(1))
+		Expect(collection.Enumerate()[0].Action).To(Equal("Item 2"))
+
+		archivedCollection, err := repo.ListArchived()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(archivedCollection.Len()).To(Equal(1))
+		Expect(archivedCollection.Enumerate()[0].Action).To(Equal("Item 1"))
+		Expect(archivedCollection.Enumerate()[0].ArchivedAt).ToNot(BeNil())
+	})
+
+	It("can delete an item", func() {
+		addedItem, err := repo.Add(todo.TodoItem{Action: "Item 1", Tags: []string{"@phone"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = repo.Delete(addedItem.Id)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = repo.Get(addedItem.Id)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("stores the parents of subtasks, and removes them when the parent is deleted", func() {
+		parent, err := repo.Add(todo.TodoItem{Action: "Parent"})
+		Expect(err).ToNot(HaveOccurred())
+
+		subtask, err := repo.Add(todo.TodoItem{Action: "Subtask", ParentId: &parent.Id})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*subtask.ParentId).To(Equal(parent.Id))
+
+		err = repo.Delete(parent.Id)
+		Expect(err).ToNot(HaveOccurred())
+
+		subtask, err = repo.Get(subtask.Id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(subtask.ParentId).To(BeNil())
+	})
+
+	It("returns ItemNotFoundError when deleting an item which doesn't exist", func() {
+		err := repo.Delete(999)
+		Expect(err).To(Equal(todo.ItemNotFoundError))
+	})
+
 	It("update an item", func() {
 		item := todo.TodoItem{
 			Id:        0,
@@ -106,7 +265,8 @@ var _ = Describe("Repository", func() {
 		addedItem.Duration = &duration
 
 		addedItem.Action = "updated"
-		addedItem.Complete()
+		addedItem.Priority = todo.PriorityLow
+		addedItem.Complete(time.Now())
 
 		updatedItem, err := repo.Update(addedItem)
 
@@ -120,5 +280,6 @@ var _ = Describe("Repository", func() {
         Expect(updatedItem.CompletedAt).ToNot(BeNil())
         Expect(*updatedItem.CompletedAt).To(BeTemporally("~", time.Now(), 5 * time.Second))
 		Expect(updatedItem.Action).To(Equal("updated"))
+		Expect(updatedItem.Priority).To(Equal(todo.PriorityLow))
 	})
 })
@@ -0,0 +1,105 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Comparators order the items in a collection. They report
+// whether a comes before b, and can be passed to SortStable.
+
+// ByDueDate orders items by their due dates, soonest first.
+// Items without a due date come after those with one.
+func ByDueDate(a *TodoItem, b *TodoItem) bool {
+	return missingLast(hasDueDate, dueDateBefore)(a, b)
+}
+
+// ByDuration orders items by their durations, shortest first.
+// Items without a duration come after those with one.
+func ByDuration(a *TodoItem, b *TodoItem) bool {
+	return missingLast(hasDuration, shorter)(a, b)
+}
+
+// ByCreated orders items by when they were added, oldest first.
+// Ids are given out in order, so older items have smaller ids.
+func ByCreated(a *TodoItem, b *TodoItem) bool {
+	return a.Id < b.Id
+}
+
+// ByPriority orders items by their priorities, most important first
+func ByPriority(a *TodoItem, b *TodoItem) bool {
+	return a.Priority < b.Priority
+}
+
+// Descending reverses the order of a comparator
+func Descending(less func(a *TodoItem, b *TodoItem) bool) func(a *TodoItem, b *TodoItem) bool {
+	return func(a *TodoItem, b *TodoItem) bool {
+		return less(b, a)
+	}
+}
+
+// missingLast orders the items which have a value, as told by has, with
+// less, and puts the items which don't have one after them
+func missingLast(has func(*TodoItem) bool, less func(a *TodoItem, b *TodoItem) bool) func(a *TodoItem, b *TodoItem) bool {
+	return func(a *TodoItem, b *TodoItem) bool {
+		if !has(a) || !has(b) {
+			return has(a) && !has(b)
+		}
+
+		return less(a, b)
+	}
+}
+
+func hasDueDate(item *TodoItem) bool {
+	return item.DueDate != nil
+}
+
+func dueDateBefore(a *TodoItem, b *TodoItem) bool {
+	return a.DueDate.Before(*b.DueDate)
+}
+
+func hasDuration(item *TodoItem) bool {
+	return item.Duration != nil
+}
+
+func shorter(a *TodoItem, b *TodoItem) bool {
+	return *a.Duration < *b.Duration
+}
+
+// sortOrder is a way of sorting items. Items which don't have the value
+// they're sorted by, as told by has, come last whichever way they're sorted.
+type sortOrder struct {
+	less func(a *TodoItem, b *TodoItem) bool
+	has  func(*TodoItem) bool
+}
+
+var sortOrders = map[string]sortOrder{
+	"due":      {less: dueDateBefore, has: hasDueDate},
+	"duration": {less: shorter, has: hasDuration},
+	"created":  {less: ByCreated},
+	"priority": {less: ByPriority},
+}
+
+// SortOrders are the names ParseSortOrder understands
+var SortOrders = []string{"due", "duration", "created", "priority"}
+
+// ParseSortOrder returns the comparator for the name of a sort order,
+// which is one of SortOrders, in descending order when descending is
+// true. Items without a due date or duration come last either way.
+func ParseSortOrder(input string, descending bool) (func(a *TodoItem, b *TodoItem) bool, error) {
+	order, ok := sortOrders[strings.ToLower(strings.TrimSpace(input))]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort order '%s', expected one of %s", input, strings.Join(SortOrders, ", "))
+	}
+
+	less := order.less
+	if descending {
+		less = Descending(less)
+	}
+
+	if order.has == nil {
+		return less, nil
+	}
+
+	return missingLast(order.has, less), nil
+}
@@ -0,0 +1,77 @@
+package todo_test
+
+import (
+	"time"
+
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sorting", func() {
+	now := time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+	tomorrow := now.AddDate(0, 0, 1)
+	short := 15 * time.Minute
+	long := 2 * time.Hour
+
+	var (
+		first      *TodoItem
+		second     *TodoItem
+		third      *TodoItem
+		collection *TodoItemCollection
+	)
+
+	BeforeEach(func() {
+		first = &TodoItem{Id: 1, Priority: PriorityLow, Duration: &long}
+		second = &TodoItem{Id: 2, Priority: PriorityHighest, DueDate: &tomorrow}
+		third = &TodoItem{Id: 3, Priority: PriorityMedium, DueDate: &now, Duration: &short}
+		collection = NewTodoItemCollection([]*TodoItem{second, third, first})
+	})
+
+	It("ByDueDate puts the soonest first, and items without a due date last", func() {
+		Expect(collection.SortStable(ByDueDate).Enumerate()).To(Equal([]*TodoItem{third, second, first}))
+	})
+
+	It("ByDuration puts the shortest first, and items without a duration last", func() {
+		Expect(collection.SortStable(ByDuration).Enumerate()).To(Equal([]*TodoItem{third, first, second}))
+	})
+
+	It("ByCreated puts the oldest first", func() {
+		Expect(collection.SortStable(ByCreated).Enumerate()).To(Equal([]*TodoItem{first, second, third}))
+	})
+
+	It("ByPriority puts the most important first", func() {
+		Expect(collection.SortStable(ByPriority).Enumerate()).To(Equal([]*TodoItem{second, third, first}))
+	})
+
+	It("Descending reverses the order", func() {
+		Expect(collection.SortStable(Descending(ByPriority)).Enumerate()).To(Equal([]*TodoItem{first, third, second}))
+	})
+
+	Describe("ParseSortOrder", func() {
+		It("returns the comparator for each sort order", func() {
+			for _, order := range SortOrders {
+				less, err := ParseSortOrder(order, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(less).ToNot(BeNil())
+			}
+		})
+
+		It("returns a comparator in descending order, which still puts items without a due date last", func() {
+			less, err := ParseSortOrder("due", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(collection.SortStable(less).Enumerate()).To(Equal([]*TodoItem{second, third, first}))
+		})
+
+		It("returns a comparator in descending order, which still puts items without a duration last", func() {
+			less, err := ParseSortOrder("duration", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(collection.SortStable(less).Enumerate()).To(Equal([]*TodoItem{first, third, second}))
+		})
+
+		It("returns an error for an unknown sort order", func() {
+			_, err := ParseSortOrder("alphabetical", false)
+			Expect(err).To(MatchError(ContainSubstring("due, duration, created, priority")))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package todo
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// NormalizeTag trims the whitespace from around a tag and lower cases it,
+// so that "@Laptop" and "@laptop" are the same tag. Any prefix, such as
+// the "+" in "+release" or the "@" in "@laptop", is part of the tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes every tag, and removes empty and duplicate tags
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
+
+func (t *TodoItem) HasTag(tag string) bool {
+	return slices.Contains(t.Tags, NormalizeTag(tag))
+}
+
+// HasAllTags returns a filter which matches items with every one of the tags
+func HasAllTags(tags []string) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		for _, tag := range tags {
+			if !item.HasTag(tag) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// HasNoneOfTags returns a filter which matches items with none of the tags
+func HasNoneOfTags(tags []string) func(*TodoItem) bool {
+	return func(item *TodoItem) bool {
+		for _, tag := range tags {
+			if item.HasTag(tag) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
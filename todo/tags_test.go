@@ -0,0 +1,30 @@
+package todo_test
+
+import (
+	. "github.com/AP-Hunt/what-next/m/todo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tags", func() {
+	Describe("NormalizeTags", func() {
+		It("trims and lower cases tags, keeping their prefixes, and removes empty and duplicate tags", func() {
+			Expect(NormalizeTags([]string{" @Laptop", "+release", "", "@laptop"})).To(Equal([]string{"@laptop", "+release"}))
+		})
+	})
+
+	Describe("filtering by tags", func() {
+		laptopAndRelease := &TodoItem{Id: 1, Tags: []string{"@laptop", "+release"}}
+		laptop := &TodoItem{Id: 2, Tags: []string{"@laptop"}}
+		phone := &TodoItem{Id: 3, Tags: []string{"@phone"}}
+		collection := NewTodoItemCollection([]*TodoItem{laptopAndRelease, laptop, phone})
+
+		It("HasAllTags matches items with every tag", func() {
+			Expect(collection.Filter(HasAllTags([]string{"@Laptop", "+release"})).Enumerate()).To(ConsistOf(laptopAndRelease))
+		})
+
+		It("HasNoneOfTags matches items without any of the tags", func() {
+			Expect(collection.Filter(HasNoneOfTags([]string{"+release", "@phone"})).Enumerate()).To(ConsistOf(laptop))
+		})
+	})
+})
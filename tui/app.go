@@ -0,0 +1,349 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/clock"
+	"github.com/AP-Hunt/what-next/m/terminal"
+	"github.com/AP-Hunt/what-next/m/todo"
+)
+
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeAdd
+	modeFilter
+)
+
+// App is a full screen interface which shows the schedule, the todo
+// list and today's calendar side by side, and has keys to add, edit,
+// complete and filter todo items. It loads everything again every
+// refresh interval, so the schedule keeps up with the time.
+type App struct {
+	backend         BackendInterface
+	clock           clock.Clock
+	refreshInterval time.Duration
+
+	snapshot *Snapshot
+	loadedAt time.Time
+
+	mode  mode
+	input string
+
+	// filter picks out the todo items to list. It's kept
+	// while it's being typed, so that it can be put back.
+	filter         string
+	previousFilter string
+
+	// selected is the index of the selected item in the listed items,
+	// and offset is the index of the first one which fits on screen
+	selected int
+	offset   int
+
+	message string
+	quit    bool
+
+	lastDrawn []string
+}
+
+func NewApp(backend BackendInterface, clock clock.Clock, refreshInterval time.Duration) *App {
+	return &App{
+		backend:         backend,
+		clock:           clock,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// Run shows the interface on the screen until the user quits
+func (a *App) Run(screen ScreenInterface) error {
+	a.quit = false
+	a.reload()
+
+	for !a.quit {
+		err := a.draw(screen)
+		if err != nil {
+			return err
+		}
+
+		key, err := screen.ReadKey()
+		if err != nil {
+			return err
+		}
+
+		if key != terminal.KeyNone {
+			a.handleKey(key, screen)
+		}
+
+		if a.clock.Now().Sub(a.loadedAt) >= a.refreshInterval {
+			a.reload()
+		}
+	}
+
+	return nil
+}
+
+// reload loads everything again, keeping the same item selected.
+// The last snapshot is kept when loading fails.
+func (a *App) reload() {
+	selectedId := -1
+	if item := a.selectedItem(); item != nil {
+		selectedId = item.Id
+	}
+
+	a.loadedAt = a.clock.Now()
+
+	snapshot, err := a.backend.Load()
+	if err != nil {
+		a.message = fmt.Sprintf("Couldn't load your schedule: %s", err)
+		return
+	}
+	a.snapshot = snapshot
+
+	a.selected = 0
+	for i, node := range a.listedItems() {
+		if node.Item.Id == selectedId {
+			a.selected = i
+		}
+	}
+}
+
+func (a *App) handleKey(key terminal.Key, screen ScreenInterface) {
+	if key == terminal.KeyCtrlC {
+		a.quit = true
+		return
+	}
+
+	switch a.mode {
+	case modeAdd, modeFilter:
+		a.handlePromptKey(key)
+	default:
+		// Keys typed quickly can be read together, so they're handled one at a
+		// time, and the rest are typed in to the prompt when one is opened
+		runes := []rune(string(key))
+		if key.IsText() && len(runes) > 1 {
+			a.handleKey(terminal.Key(string(runes[0])), screen)
+			a.handleKey(terminal.Key(string(runes[1:])), screen)
+			return
+		}
+		a.handleNormalKey(key, screen)
+	}
+}
+
+func (a *App) handleNormalKey(key terminal.Key, screen ScreenInterface) {
+	a.message = ""
+	listed := a.listedItems()
+
+	switch key {
+	case "q":
+		a.quit = true
+
+	case terminal.KeyUp, "k":
+		a.selected--
+	case terminal.KeyDown, "j":
+		a.selected++
+	case terminal.KeyPageUp:
+		a.selected -= 10
+	case terminal.KeyPageDown:
+		a.selected += 10
+	case terminal.KeyHome, "g":
+		a.selected = 0
+	case terminal.KeyEnd, "G":
+		a.selected = len(listed) - 1
+
+	case "a":
+		a.mode = modeAdd
+		a.input = ""
+
+	case "/":
+		a.mode = modeFilter
+		a.input = a.filter
+		a.previousFilter = a.filter
+
+	case terminal.KeyEscape:
+		a.filter = ""
+		a.selected = 0
+
+	case "r":
+		a.reload()
+
+	case "c", "x", " ":
+		if item := a.selectedItem(); item != nil {
+			a.toggleComplete(*item)
+		}
+
+	case "e":
+		if item := a.selectedItem(); item != nil {
+			a.edit(*item, screen)
+		}
+	}
+
+	a.clampSelection()
+}
+
+func (a *App) handlePromptKey(key terminal.Key) {
+	switch key {
+	case terminal.KeyEscape:
+		if a.mode == modeFilter {
+			a.filter = a.previousFilter
+		}
+		a.mode = modeNormal
+
+	case terminal.KeyEnter:
+		if a.mode == modeAdd {
+			a.add(strings.TrimSpace(a.input))
+		}
+		a.mode = modeNormal
+
+	case terminal.KeyBackspace:
+		runes := []rune(a.input)
+		if len(runes) > 0 {
+			a.input = string(runes[:len(runes)-1])
+		}
+
+	case terminal.KeyCtrlU:
+		a.input = ""
+
+	default:
+		if !key.IsText() {
+			return
+		}
+		a.input += string(key)
+	}
+
+	// The list is filtered as the filter is typed
+	if a.mode == modeFilter {
+		a.filter = a.input
+		a.selected = 0
+	}
+	a.clampSelection()
+}
+
+func (a *App) add(action string) {
+	if action == "" {
+		return
+	}
+
+	err := a.backend.Add(action)
+	if err != nil {
+		a.message = fmt.Sprintf("Couldn't add '%s': %s", action, err)
+		return
+	}
+
+	a.reload()
+	a.message = fmt.Sprintf("Added '%s'", action)
+}
+
+func (a *App) toggleComplete(item todo.TodoItem) {
+	var err error
+	if item.Completed {
+		err = a.backend.Reopen(item)
+	} else {
+		err = a.backend.Complete(item)
+	}
+
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+
+	a.reload()
+	if item.Completed {
+		a.message = fmt.Sprintf("Reopened '%s'", item.Action)
+	} else {
+		a.message = fmt.Sprintf("Completed '%s'", item.Action)
+	}
+}
+
+// edit hands the terminal over to the user's editor while they edit the item
+func (a *App) edit(item todo.TodoItem, screen ScreenInterface) {
+	err := screen.Suspend()
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+
+	editErr := a.backend.Edit(item)
+
+	err = screen.Resume()
+	if err != nil {
+		a.message = err.Error()
+		a.quit = true
+		return
+	}
+
+	// Everything has to be drawn again, because the editor drew over it
+	a.lastDrawn = nil
+
+	if editErr != nil {
+		a.message = fmt.Sprintf("Couldn't edit '%s': %s", item.Action, editErr)
+		return
+	}
+
+	a.reload()
+	a.message = fmt.Sprintf("Edited '%s'", item.Action)
+}
+
+// listedItems are the todo items in the list, as a tree of subtasks,
+// which are the items in the snapshot picked out by the filter.
+//
+// The filter is made of words which each have to be in the
+// item's action, except those beginning with @ or +, which
+// are tags the item has to have.
+func (a *App) listedItems() []todo.TodoItemTreeNode {
+	if a.snapshot == nil {
+		return []todo.TodoItemTreeNode{}
+	}
+
+	items := a.snapshot.TodoItems
+	for _, word := range strings.Fields(strings.ToLower(a.filter)) {
+		if strings.HasPrefix(word, "@") || strings.HasPrefix(word, "+") {
+			items = items.Filter(todo.HasAllTags([]string{word}))
+			continue
+		}
+
+		text := word
+		items = items.Filter(func(item *todo.TodoItem) bool {
+			return strings.Contains(strings.ToLower(item.Action), text)
+		})
+	}
+
+	return items.Tree()
+}
+
+func (a *App) selectedItem() *todo.TodoItem {
+	listed := a.listedItems()
+	if a.selected < 0 || a.selected >= len(listed) {
+		return nil
+	}
+
+	return listed[a.selected].Item
+}
+
+func (a *App) clampSelection() {
+	listed := a.listedItems()
+	if a.selected >= len(listed) {
+		a.selected = len(listed) - 1
+	}
+	if a.selected < 0 {
+		a.selected = 0
+	}
+}
+
+// draw draws the interface, when it looks different to the last time it was drawn
+func (a *App) draw(screen ScreenInterface) error {
+	width, height, err := screen.Size()
+	if err != nil {
+		return err
+	}
+
+	lines := a.render(width, height)
+	if strings.Join(lines, "\n") == strings.Join(a.lastDrawn, "\n") {
+		return nil
+	}
+
+	a.lastDrawn = lines
+	return screen.Draw(lines)
+}
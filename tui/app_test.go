@@ -0,0 +1,229 @@
+package tui_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/clock"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/terminal"
+	"github.com/AP-Hunt/what-next/m/todo"
+	"github.com/AP-Hunt/what-next/m/tui"
+	. "github.com/AP-Hunt/what-next/m/tui/fakes"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// steppingClock is a clock whose time is moved on by the test
+type steppingClock struct {
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	return c.now
+}
+
+var _ = Describe("App", func() {
+	var (
+		backend *FakeBackendInterface
+		screen  *FakeScreenInterface
+		app     *tui.App
+		now     time.Time
+		items   []*todo.TodoItem
+	)
+
+	pressKeys := func(keys ...terminal.Key) {
+		keys = append(keys, terminal.KeyCtrlC)
+		screen.ReadKeyStub = func() (terminal.Key, error) {
+			key := keys[0]
+			keys = keys[1:]
+			return key, nil
+		}
+	}
+
+	lastDrawn := func() string {
+		Expect(screen.DrawCallCount()).To(BeNumerically(">", 0))
+		return strings.Join(screen.DrawArgsForCall(screen.DrawCallCount()-1), "\n")
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+		standup := ical.NewEvent("standup")
+		standup.SetProperty(ical.ComponentProperty(ical.PropertySummary), "Standup")
+		standup.SetStartAt(now.Add(30 * time.Minute))
+		standup.SetEndAt(now.Add(45 * time.Minute))
+
+		untilNext := 30 * time.Minute
+		items = []*todo.TodoItem{
+			{Id: 1, Action: "foo", Tags: []string{"@pc"}},
+			{Id: 2, Action: "bar"},
+			{Id: 3, Action: "baz", Completed: true},
+		}
+
+		backend = &FakeBackendInterface{}
+		backend.LoadStub = func() (*tui.Snapshot, error) {
+			return &tui.Snapshot{
+				Now: now,
+				Schedule: &scheduler.Schedule{
+					NextCalendarEvents:               []*ical.VEvent{standup},
+					TimeUntilNextCalendarEvent:       &untilNext,
+					UsableTimeUntilNextCalendarEvent: &untilNext,
+					IsWorkingTime:                    true,
+					AchievableTasks:                  *todo.NewTodoItemCollection(items[:1]),
+				},
+				Events:    []*ical.VEvent{standup},
+				TodoItems: todo.NewTodoItemCollection(items),
+			}, nil
+		}
+
+		screen = &FakeScreenInterface{}
+		screen.SizeReturns(150, 30, nil)
+
+		app = tui.NewApp(backend, clock.NewFixedClock(now), time.Minute)
+	})
+
+	It("shows the schedule, the todo list and today's calendar", func() {
+		pressKeys()
+		Expect(app.Run(screen)).To(Succeed())
+
+		drawn := lastDrawn()
+		Expect(drawn).To(ContainSubstring("Next, in 30 minutes"))
+		Expect(drawn).To(ContainSubstring("• foo"))
+		Expect(drawn).To(ContainSubstring("[ ] bar"))
+		Expect(drawn).To(ContainSubstring("[✓] baz"))
+		Expect(drawn).To(ContainSubstring("1000-1015 Standup"))
+
+		for _, line := range screen.DrawArgsForCall(0) {
+			Expect(len([]rune(line))).To(BeNumerically("<=", 150))
+		}
+	})
+
+	It("completes the selected item, and loads everything again", func() {
+		pressKeys(terminal.KeyDown, "c")
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(backend.CompleteCallCount()).To(Equal(1))
+		Expect(backend.CompleteArgsForCall(0).Id).To(Equal(2))
+		Expect(backend.LoadCallCount()).To(Equal(2))
+		Expect(lastDrawn()).To(ContainSubstring("Completed 'bar'"))
+	})
+
+	It("reopens the selected item when it's complete", func() {
+		pressKeys("G", "c")
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(backend.ReopenCallCount()).To(Equal(1))
+		Expect(backend.ReopenArgsForCall(0).Id).To(Equal(3))
+	})
+
+	It("shows why an item couldn't be completed", func() {
+		backend.CompleteReturns(errors.New("todo item 1 has subtasks which aren't complete"))
+
+		pressKeys("c")
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(lastDrawn()).To(ContainSubstring("todo item 1 has subtasks which aren't complete"))
+	})
+
+	It("adds items typed in to the prompt", func() {
+		pressKeys("a", "new", " item", terminal.KeyBackspace, "m", terminal.KeyEnter)
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(backend.AddCallCount()).To(Equal(1))
+		Expect(backend.AddArgsForCall(0)).To(Equal("new item"))
+	})
+
+	It("doesn't add anything when the prompt is cancelled", func() {
+		pressKeys("a", "new item", terminal.KeyEscape)
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(backend.AddCallCount()).To(Equal(0))
+	})
+
+	It("filters the todo list by the words in actions and by tags", func() {
+		pressKeys("/", "ba", terminal.KeyEnter)
+		Expect(app.Run(screen)).To(Succeed())
+
+		drawn := lastDrawn()
+		Expect(drawn).To(ContainSubstring("Todo (2 matching 'ba')"))
+		Expect(drawn).ToNot(ContainSubstring("[ ] foo"))
+
+		pressKeys("/", terminal.KeyCtrlU, "@pc", terminal.KeyEnter)
+		Expect(app.Run(screen)).To(Succeed())
+
+		drawn = lastDrawn()
+		Expect(drawn).To(ContainSubstring("[ ] foo"))
+		Expect(drawn).ToNot(ContainSubstring("[ ] bar"))
+	})
+
+	It("completes the item selected in the filtered list", func() {
+		pressKeys("/", "bar", terminal.KeyEnter, "c")
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(backend.CompleteArgsForCall(0).Id).To(Equal(2))
+	})
+
+	It("hands the terminal to the editor while an item is edited", func() {
+		backend.EditStub = func(item todo.TodoItem) error {
+			Expect(screen.SuspendCallCount()).To(Equal(1))
+			Expect(screen.ResumeCallCount()).To(Equal(0))
+			return nil
+		}
+
+		pressKeys("e")
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(backend.EditCallCount()).To(Equal(1))
+		Expect(backend.EditArgsForCall(0).Id).To(Equal(1))
+		Expect(screen.ResumeCallCount()).To(Equal(1))
+	})
+
+	It("loads everything again once the refresh interval has passed on the clock", func() {
+		clk := &steppingClock{now: now}
+		app = tui.NewApp(backend, clk, time.Minute)
+
+		// Each key read takes 20 seconds on the clock, so the
+		// interval passes after the third and the sixth
+		keys := []terminal.Key{terminal.KeyNone, terminal.KeyNone, terminal.KeyNone, terminal.KeyNone, terminal.KeyNone, terminal.KeyCtrlC}
+		screen.ReadKeyStub = func() (terminal.Key, error) {
+			clk.now = clk.now.Add(20 * time.Second)
+			key := keys[0]
+			keys = keys[1:]
+			return key, nil
+		}
+
+		Expect(app.Run(screen)).To(Succeed())
+		Expect(backend.LoadCallCount()).To(Equal(3))
+	})
+
+	It("shows the time on the clock before anything has loaded", func() {
+		backend.LoadReturns(nil, errors.New("calendar unavailable"))
+		app = tui.NewApp(backend, clock.NewFixedClock(now), time.Minute)
+
+		pressKeys()
+		Expect(app.Run(screen)).To(Succeed())
+
+		Expect(lastDrawn()).To(ContainSubstring("Mon 17 Oct 09:30"))
+	})
+
+	It("keeps showing the last schedule when it can't be loaded again", func() {
+		load := backend.LoadStub
+		backend.LoadStub = func() (*tui.Snapshot, error) {
+			if backend.LoadCallCount() > 1 {
+				return nil, errors.New("calendar unavailable")
+			}
+			return load()
+		}
+
+		pressKeys("r")
+
+		Expect(app.Run(screen)).To(Succeed())
+
+		drawn := lastDrawn()
+		Expect(drawn).To(ContainSubstring("Couldn't load your schedule: calendar unavailable"))
+		Expect(drawn).To(ContainSubstring("1000-1015 Standup"))
+	})
+})
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/terminal"
+	"github.com/AP-Hunt/what-next/m/todo"
+	ical "github.com/arran4/golang-ical"
+)
+
+// Snapshot is everything the interface shows, as it was when it was loaded
+type Snapshot struct {
+	Now      time.Time
+	Schedule *scheduler.Schedule
+
+	// Events are the events on every calendar today, in the order they start
+	Events []*ical.VEvent
+
+	// TodoItems are the items to list, which are the incomplete
+	// items and those completed in the last 24 hours
+	TodoItems *todo.TodoItemCollection
+}
+
+// BackendInterface is how the interface loads the schedule
+// and todo list, and makes changes to the todo list
+//
+//counterfeiter:generate -o fakes/ . BackendInterface
+type BackendInterface interface {
+	Load() (*Snapshot, error)
+	Add(action string) error
+	Complete(item todo.TodoItem) error
+	Reopen(item todo.TodoItem) error
+
+	// Edit edits the item in the user's editor, which takes
+	// over the terminal until the user has finished
+	Edit(item todo.TodoItem) error
+}
+
+// ScreenInterface is the terminal the interface is drawn in
+//
+//counterfeiter:generate -o fakes/ . ScreenInterface
+type ScreenInterface interface {
+	Size() (int, int, error)
+	ReadKey() (terminal.Key, error)
+	Draw(lines []string) error
+	Suspend() error
+	Resume() error
+}
@@ -0,0 +1,325 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/terminal"
+	"github.com/AP-Hunt/what-next/m/views"
+	ical "github.com/arran4/golang-ical"
+	"github.com/fatih/color"
+	"github.com/hako/durafmt"
+)
+
+const columnSeparator = " │ "
+
+var (
+	titleStyle     = color.New(color.Bold)
+	dimStyle       = color.New(color.FgHiBlack)
+	selectedStyle  = color.New(color.ReverseVideo)
+	overdueStyle   = color.New(color.FgRed, color.Bold)
+	happeningStyle = color.New(color.FgGreen, color.Bold)
+	messageStyle   = color.New(color.FgYellow)
+)
+
+// line is a line of a panel, which is styled
+// after it has been cut to fit the panel
+type line struct {
+	text  string
+	style *color.Color
+}
+
+func plain(text string) line {
+	return line{text: text}
+}
+
+func styled(style *color.Color, text string) line {
+	return line{text: text, style: style}
+}
+
+func (l line) fit(width int) string {
+	text := terminal.Fit(l.text, width)
+	if l.style == nil {
+		return text
+	}
+
+	return l.style.Sprint(text)
+}
+
+// render draws the interface as lines which fill the screen: a header,
+// the schedule, todo list and calendar in columns, and a footer with
+// the last message and the keys which can be pressed
+func (a *App) render(width int, height int) []string {
+	if width < 40 || height < 8 {
+		return []string{terminal.Fit("The terminal is too small", width)}
+	}
+
+	lines := []string{}
+
+	now := a.clock.Now()
+	if a.snapshot != nil {
+		now = a.snapshot.Now
+	}
+	clock := now.Local().Format("Mon 2 Jan 15:04")
+	header := terminal.Fit("what-next", width-len(clock)) + clock
+	lines = append(lines, titleStyle.Sprint(header))
+	lines = append(lines, dimStyle.Sprint(strings.Repeat("─", width)))
+
+	bodyHeight := height - 4
+	available := width - 2*len([]rune(columnSeparator))
+	scheduleWidth := available * 3 / 10
+	calendarWidth := available * 3 / 10
+	todoWidth := available - scheduleWidth - calendarWidth
+
+	columns := [][]line{
+		a.schedulePanel(),
+		a.todoPanel(bodyHeight),
+		a.calendarPanel(),
+	}
+	widths := []int{scheduleWidth, todoWidth, calendarWidth}
+
+	for row := 0; row < bodyHeight; row++ {
+		cells := []string{}
+		for i, column := range columns {
+			cell := plain("")
+			if row < len(column) {
+				cell = column[row]
+			}
+			cells = append(cells, cell.fit(widths[i]))
+		}
+
+		lines = append(lines, strings.Join(cells, dimStyle.Sprint(columnSeparator)))
+	}
+
+	lines = append(lines, a.footer(width)...)
+	return lines
+}
+
+func (a *App) footer(width int) []string {
+	switch a.mode {
+	case modeAdd:
+		return []string{
+			dimStyle.Sprint(terminal.Fit("Enter to add the item, esc to cancel", width)),
+			terminal.Fit("Add: "+a.input+"█", width),
+		}
+	case modeFilter:
+		return []string{
+			dimStyle.Sprint(terminal.Fit("Words to look for, and @tags or +tags to have. Enter to keep the filter, esc to cancel", width)),
+			terminal.Fit("Filter: "+a.input+"█", width),
+		}
+	default:
+		return []string{
+			messageStyle.Sprint(terminal.Fit(a.message, width)),
+			dimStyle.Sprint(terminal.Fit("↑/↓ select · a add · e edit · c complete · / filter · esc clear filter · r refresh · q quit", width)),
+		}
+	}
+}
+
+func (a *App) schedulePanel() []line {
+	lines := []line{styled(titleStyle, "Schedule"), plain("")}
+	if a.snapshot == nil || a.snapshot.Schedule == nil {
+		return append(lines, plain("Loading…"))
+	}
+	schedule := a.snapshot.Schedule
+
+	if len(schedule.CurrentCalendarEvents) == 0 {
+		lines = append(lines, plain("No meetings right now"))
+	} else {
+		lines = append(lines, styled(titleStyle, "Now"))
+		for _, event := range schedule.CurrentCalendarEvents {
+			lines = append(lines, styled(happeningStyle, "  "+formatEvent(event)))
+		}
+	}
+	lines = append(lines, plain(""))
+
+	if len(schedule.NextCalendarEvents) == 0 {
+		lines = append(lines, plain("No more meetings today"))
+	} else {
+		title := "Next"
+		if schedule.TimeUntilNextCalendarEvent != nil {
+			title = fmt.Sprintf("Next, in %s", formatDuration(*schedule.TimeUntilNextCalendarEvent))
+		}
+
+		lines = append(lines, styled(titleStyle, title))
+		for _, event := range schedule.NextCalendarEvents {
+			lines = append(lines, plain("  "+formatEvent(event)))
+		}
+	}
+	lines = append(lines, plain(""))
+
+	if schedule.TimeLeftInWorkingDay != nil {
+		if schedule.EndOfWorkingDay == nil {
+			lines = append(lines, plain("You aren't working today"))
+		} else if *schedule.TimeLeftInWorkingDay <= 0 {
+			lines = append(lines, plain("Your working day is over"))
+		} else {
+			lines = append(lines, plain(fmt.Sprintf(
+				"Day ends at %s, %s left",
+				schedule.EndOfWorkingDay.Local().Format("15:04"),
+				formatDuration(*schedule.TimeLeftInWorkingDay),
+			)))
+			if !schedule.IsWorkingTime {
+				lines = append(lines, plain("It's not working time, so take a break"))
+			}
+		}
+	}
+
+	if freeTime := schedule.FreeTime(); schedule.IsWorkingTime && freeTime != nil {
+		lines = append(lines, plain(fmt.Sprintf("%s free for tasks", formatDuration(*freeTime))))
+	}
+	lines = append(lines, plain(""))
+
+	if !schedule.IsWorkingTime {
+		return lines
+	}
+
+	lines = append(lines, styled(titleStyle, "Tasks which fit"))
+	if schedule.AchievableTasks.Len() == 0 {
+		lines = append(lines, plain("  Nothing fits"))
+	}
+	for _, task := range schedule.AchievableTasks.Enumerate() {
+		lines = append(lines, plain("  • "+task.Action))
+	}
+
+	return lines
+}
+
+func (a *App) todoPanel(height int) []line {
+	listed := a.listedItems()
+
+	title := fmt.Sprintf("Todo (%d)", len(listed))
+	if a.filter != "" {
+		title = fmt.Sprintf("Todo (%d matching '%s')", len(listed), a.filter)
+	}
+	lines := []line{styled(titleStyle, title), plain("")}
+
+	if a.snapshot == nil {
+		return append(lines, plain("Loading…"))
+	}
+
+	if len(listed) == 0 {
+		if a.filter != "" {
+			return append(lines, plain("Nothing matches the filter"))
+		}
+		return append(lines, plain("Nothing to do 🎉️"))
+	}
+
+	// Scroll so the selected item is on screen
+	visible := height - len(lines)
+	if a.selected < a.offset {
+		a.offset = a.selected
+	}
+	if a.selected >= a.offset+visible {
+		a.offset = a.selected - visible + 1
+	}
+	if a.offset > len(listed)-visible {
+		a.offset = len(listed) - visible
+	}
+	if a.offset < 0 {
+		a.offset = 0
+	}
+
+	for i := a.offset; i < len(listed) && i < a.offset+visible; i++ {
+		node := listed[i]
+		item := node.Item
+
+		check := "[ ]"
+		if item.Completed {
+			check = "[✓]"
+		}
+
+		indent := ""
+		if node.Depth > 0 {
+			indent = strings.Repeat("  ", node.Depth-1) + "└ "
+		}
+
+		text := fmt.Sprintf("%s %s%s", check, indent, item.Action)
+		if item.DueDate != nil {
+			text = fmt.Sprintf("%s · %s", text, views.FormatRelativeDate(item.DueDate.Local()))
+		}
+		if item.Duration != nil {
+			text = fmt.Sprintf("%s · %s", text, formatDuration(*item.Duration))
+		}
+
+		var style *color.Color
+		switch {
+		case i == a.selected:
+			style = selectedStyle
+		case item.Completed:
+			style = dimStyle
+		case item.IsOverdue(a.snapshot.Now):
+			style = overdueStyle
+		}
+
+		lines = append(lines, styled(style, text))
+	}
+
+	return lines
+}
+
+func (a *App) calendarPanel() []line {
+	if a.snapshot == nil {
+		return []line{styled(titleStyle, "Today"), plain(""), plain("Loading…")}
+	}
+
+	now := a.snapshot.Now
+	lines := []line{styled(titleStyle, "Today, "+now.Local().Format("Mon 2 Jan")), plain("")}
+
+	if len(a.snapshot.Events) == 0 {
+		return append(lines, plain("Nothing on your calendars"))
+	}
+
+	for _, event := range a.snapshot.Events {
+		start, end, err := calendar.EventStartAndEnd(event)
+		if err != nil {
+			continue
+		}
+
+		text := formatEvent(event)
+		if isAllDay, _ := calendar.IsAllDayEvent(event); isAllDay {
+			text = "All day   " + eventSummary(event)
+		}
+
+		var style *color.Color
+		switch {
+		case !end.After(now):
+			style = dimStyle
+		case !start.After(now):
+			style = happeningStyle
+		}
+
+		lines = append(lines, styled(style, text))
+	}
+
+	return lines
+}
+
+// formatEvent formats an event the way the schedule does, such as "0930-1000 Standup"
+func formatEvent(event *ical.VEvent) string {
+	start, end, err := calendar.EventStartAndEnd(event)
+	if err != nil {
+		return eventSummary(event)
+	}
+
+	start, end = start.Local(), end.Local()
+	return fmt.Sprintf("%02d%02d-%02d%02d %s", start.Hour(), start.Minute(), end.Hour(), end.Minute(), eventSummary(event))
+}
+
+func eventSummary(event *ical.VEvent) string {
+	summary := event.GetProperty(ical.ComponentProperty(ical.PropertySummary))
+	if summary == nil {
+		return ""
+	}
+
+	return summary.Value
+}
+
+func formatDuration(duration time.Duration) string {
+	if duration < time.Minute {
+		return "less than a minute"
+	}
+
+	return durafmt.Parse(duration.Truncate(time.Minute)).LimitFirstN(2).String()
+}
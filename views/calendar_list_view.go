@@ -3,9 +3,11 @@ package views
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/AP-Hunt/what-next/m/calendar"
 	"github.com/alexeyco/simpletable"
+	"github.com/hako/durafmt"
 )
 
 type CalendarListView struct {
@@ -19,12 +21,16 @@ func (cl *CalendarListView) Draw(out io.Writer) error {
 	tbl.Header.Cells = []*simpletable.Cell{
 		{Align: simpletable.AlignLeft, Text: "name"},
 		{Align: simpletable.AlignLeft, Text: "URL"},
+		{Align: simpletable.AlignLeft, Text: "buffer before"},
+		{Align: simpletable.AlignLeft, Text: "buffer after"},
 	}
 
 	for _, cal := range cl.calendars {
 		row := []*simpletable.Cell{
 			{Align: simpletable.AlignLeft, Text: cal.DisplayName},
 			{Align: simpletable.AlignLeft, Text: cal.URL},
+			{Align: simpletable.AlignLeft, Text: formatBuffer(cal.BufferBefore)},
+			{Align: simpletable.AlignLeft, Text: formatBuffer(cal.BufferAfter)},
 		}
 
 		tbl.Body.Cells = append(tbl.Body.Cells, row)
@@ -34,6 +40,14 @@ func (cl *CalendarListView) Draw(out io.Writer) error {
 	return nil
 }
 
+func formatBuffer(buffer *time.Duration) string {
+	if buffer == nil {
+		return "global"
+	}
+
+	return durafmt.Parse(*buffer).LimitFirstN(2).String()
+}
+
 func (cl *CalendarListView) SetData(data interface{}) {
 	cl.calendars = data.([]calendar.CalendarRecord)
 }
@@ -41,6 +41,8 @@ func (c *CalendarView) Draw(out io.Writer) error {
 		{Align: simpletable.AlignLeft, Text: "Room"},
 	}
 
+	targetDay := calendar.StartOfDay(c.data.TargetDate)
+
 	titleWrapper := textwrap.NewTextWrap()
 	titleWrapper.SetWidth(30)
 
@@ -62,12 +64,12 @@ func (c *CalendarView) Draw(out io.Writer) error {
 			return fmt.Errorf("failed to extract start and end time for evt %s: %s", evtId, err)
 		}
 
-		startsToday, err := calendar.EventStartsToday(evt)
+		startsToday, err := calendar.EventStartsOnDay(evt, targetDay)
 		if err != nil {
 			return err
 		}
 
-		endsToday, err := calendar.EventEndsToday(evt)
+		endsToday, err := calendar.EventEndsOnDay(evt, targetDay)
 		if err != nil {
 			return err
 		}
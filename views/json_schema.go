@@ -0,0 +1,369 @@
+package views
+
+import (
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	ical "github.com/arran4/golang-ical"
+)
+
+// The types in this file are the schemas of the JSON documents
+// JSONViewEngine writes. They are documented in JSON.md, and fields
+// must only ever be added to them, so that scripts keep working.
+//
+// Times are ISO 8601 (RFC 3339) strings in local time, and durations
+// are whole numbers of seconds. Fields which may not have a value are
+// null when they don't, rather than being left out.
+
+type JSONTodoItem struct {
+	Id              int      `json:"id"`
+	Action          string   `json:"action"`
+	Completed       bool     `json:"completed"`
+	CompletedAt     *string  `json:"completed_at"`
+	Due             *string  `json:"due"`
+	Overdue         bool     `json:"overdue"`
+	DurationSeconds *int64   `json:"duration_seconds"`
+	Priority        int      `json:"priority"`
+	PriorityName    string   `json:"priority_name"`
+	Tags            []string `json:"tags"`
+	Recurrence      *string  `json:"recurrence"`
+	ParentId        *int     `json:"parent_id"`
+	BlockedBy       []int    `json:"blocked_by"`
+	Notes           string   `json:"notes"`
+	Links           []string `json:"links"`
+	ArchivedAt      *string  `json:"archived_at"`
+}
+
+type JSONEvent struct {
+	Uid             string  `json:"uid"`
+	Summary         string  `json:"summary"`
+	Description     string  `json:"description"`
+	Location        string  `json:"location"`
+	Start           *string `json:"start"`
+	End             *string `json:"end"`
+	DurationSeconds *int64  `json:"duration_seconds"`
+	AllDay          bool    `json:"all_day"`
+}
+
+type JSONTodoList struct {
+	TodoItems []JSONTodoItem `json:"todo_items"`
+}
+
+type JSONTodoItemDocument struct {
+	TodoItem JSONTodoItem `json:"todo_item"`
+}
+
+type JSONSchedule struct {
+	CurrentEvents               []JSONEvent    `json:"current_events"`
+	NextEvents                  []JSONEvent    `json:"next_events"`
+	SecondsUntilNextEvent       *int64         `json:"seconds_until_next_event"`
+	UsableSecondsUntilNextEvent *int64         `json:"usable_seconds_until_next_event"`
+	FreeSeconds                 *int64         `json:"free_seconds"`
+	IsWorkingTime               bool           `json:"is_working_time"`
+	EndOfWorkingDay             *string        `json:"end_of_working_day"`
+	SecondsLeftInWorkingDay     *int64         `json:"seconds_left_in_working_day"`
+	SecondsUntilLunch           *int64         `json:"seconds_until_lunch"`
+	AchievableTasks             []JSONTodoItem `json:"achievable_tasks"`
+}
+
+type JSONCalendar struct {
+	Date   string      `json:"date"`
+	Events []JSONEvent `json:"events"`
+}
+
+type JSONCalendarRecord struct {
+	Id                  int    `json:"id"`
+	Name                string `json:"name"`
+	URL                 string `json:"url"`
+	BufferBeforeSeconds *int64 `json:"buffer_before_seconds"`
+	BufferAfterSeconds  *int64 `json:"buffer_after_seconds"`
+}
+
+type JSONCalendarList struct {
+	Calendars []JSONCalendarRecord `json:"calendars"`
+}
+
+type JSONPlanEntry struct {
+	Kind            string        `json:"kind"`
+	Start           *string       `json:"start"`
+	End             *string       `json:"end"`
+	DurationSeconds *int64        `json:"duration_seconds"`
+	Event           *JSONEvent    `json:"event"`
+	Task            *JSONTodoItem `json:"task"`
+	Late            bool          `json:"late"`
+}
+
+type JSONPlan struct {
+	Start            *string         `json:"start"`
+	End              *string         `json:"end"`
+	Entries          []JSONPlanEntry `json:"entries"`
+	AllDayEvents     []JSONEvent     `json:"all_day_events"`
+	UnscheduledTasks []JSONTodoItem  `json:"unscheduled_tasks"`
+}
+
+type JSONSearchResult struct {
+	Kind     string        `json:"kind"`
+	Rank     float64       `json:"rank"`
+	Snippet  string        `json:"snippet"`
+	TodoItem *JSONTodoItem `json:"todo_item"`
+	Event    *JSONEvent    `json:"event"`
+	Calendar *string       `json:"calendar"`
+}
+
+type JSONSearchResults struct {
+	Query   string             `json:"query"`
+	Results []JSONSearchResult `json:"results"`
+}
+
+type JSONMessage struct {
+	Message string `json:"message"`
+}
+
+type JSONRemovedTodoItems struct {
+	RemovedIds []int `json:"removed_ids"`
+}
+
+func jsonTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Local().Format(time.RFC3339)
+	return &formatted
+}
+
+func jsonDuration(d *time.Duration) *int64 {
+	if d == nil {
+		return nil
+	}
+
+	seconds := int64(d.Seconds())
+	return &seconds
+}
+
+// jsonTodoItem converts a todo item, which is overdue when it was due before now
+func jsonTodoItem(item *todo.TodoItem, now time.Time) JSONTodoItem {
+	return JSONTodoItem{
+		Id:              item.Id,
+		Action:          item.Action,
+		Completed:       item.Completed,
+		CompletedAt:     jsonTime(item.CompletedAt),
+		Due:             jsonTime(item.DueDate),
+		Overdue:         item.IsOverdue(now) && !item.Completed,
+		DurationSeconds: jsonDuration(item.Duration),
+		Priority:        int(item.Priority),
+		PriorityName:    item.Priority.String(),
+		Tags:            nonNil(item.Tags),
+		Recurrence:      item.Recurrence,
+		ParentId:        item.ParentId,
+		BlockedBy:       nonNil(item.BlockedBy),
+		Notes:           item.Notes,
+		Links:           nonNil(item.Links),
+		ArchivedAt:      jsonTime(item.ArchivedAt),
+	}
+}
+
+func jsonTodoItems(items []*todo.TodoItem, now time.Time) []JSONTodoItem {
+	converted := []JSONTodoItem{}
+	for _, item := range items {
+		converted = append(converted, jsonTodoItem(item, now))
+	}
+
+	return converted
+}
+
+func jsonEvent(event *ical.VEvent) (JSONEvent, error) {
+	converted := JSONEvent{
+		Uid:         event.Id(),
+		Summary:     eventProperty(event, ical.PropertySummary),
+		Description: eventProperty(event, ical.PropertyDescription),
+		Location:    eventProperty(event, ical.PropertyLocation),
+	}
+
+	start, end, err := calendar.EventStartAndEnd(event)
+	if err != nil {
+		return JSONEvent{}, err
+	}
+
+	duration := end.Sub(start)
+	converted.Start = jsonTime(&start)
+	converted.End = jsonTime(&end)
+	converted.DurationSeconds = jsonDuration(&duration)
+
+	converted.AllDay, err = calendar.IsAllDayEvent(event)
+	if err != nil {
+		return JSONEvent{}, err
+	}
+
+	return converted, nil
+}
+
+// jsonEvents converts the events, ordered by when they start
+func jsonEvents(events []*ical.VEvent) ([]JSONEvent, error) {
+	sorted := append([]*ical.VEvent{}, events...)
+	err := calendar.SortEventsByStartDateAscending(sorted)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := []JSONEvent{}
+	for _, event := range sorted {
+		jsonEvt, err := jsonEvent(event)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, jsonEvt)
+	}
+
+	return converted, nil
+}
+
+func jsonSchedule(schedule *scheduler.Schedule) (JSONSchedule, error) {
+	currentEvents, err := jsonEvents(schedule.CurrentCalendarEvents)
+	if err != nil {
+		return JSONSchedule{}, err
+	}
+
+	nextEvents, err := jsonEvents(schedule.NextCalendarEvents)
+	if err != nil {
+		return JSONSchedule{}, err
+	}
+
+	return JSONSchedule{
+		CurrentEvents:               currentEvents,
+		NextEvents:                  nextEvents,
+		SecondsUntilNextEvent:       jsonDuration(schedule.TimeUntilNextCalendarEvent),
+		UsableSecondsUntilNextEvent: jsonDuration(schedule.UsableTimeUntilNextCalendarEvent),
+		FreeSeconds:                 jsonDuration(schedule.FreeTime()),
+		IsWorkingTime:               schedule.IsWorkingTime,
+		EndOfWorkingDay:             jsonTime(schedule.EndOfWorkingDay),
+		SecondsLeftInWorkingDay:     jsonDuration(schedule.TimeLeftInWorkingDay),
+		SecondsUntilLunch:           jsonDuration(schedule.TimeUntilLunch),
+		AchievableTasks:             jsonTodoItems(schedule.AchievableTasks.Enumerate(), schedule.Now),
+	}, nil
+}
+
+func jsonCalendar(data *CalendarViewData) (JSONCalendar, error) {
+	events, err := jsonEvents(data.Calendar.Events())
+	if err != nil {
+		return JSONCalendar{}, err
+	}
+
+	return JSONCalendar{
+		Date:   data.TargetDate.Format("2006-01-02"),
+		Events: events,
+	}, nil
+}
+
+func jsonCalendarList(records []calendar.CalendarRecord) JSONCalendarList {
+	calendars := []JSONCalendarRecord{}
+	for _, record := range records {
+		calendars = append(calendars, JSONCalendarRecord{
+			Id:                  record.Id,
+			Name:                record.DisplayName,
+			URL:                 record.URL,
+			BufferBeforeSeconds: jsonDuration(record.BufferBefore),
+			BufferAfterSeconds:  jsonDuration(record.BufferAfter),
+		})
+	}
+
+	return JSONCalendarList{Calendars: calendars}
+}
+
+func jsonPlan(plan *scheduler.Plan) (JSONPlan, error) {
+	entries := []JSONPlanEntry{}
+	for _, entry := range plan.Entries {
+		start, end, duration := entry.Start, entry.End, entry.Duration()
+		converted := JSONPlanEntry{
+			Kind:            "free",
+			Start:           jsonTime(&start),
+			End:             jsonTime(&end),
+			DurationSeconds: jsonDuration(&duration),
+		}
+
+		switch {
+		case entry.Event != nil:
+			event, err := jsonEvent(entry.Event)
+			if err != nil {
+				return JSONPlan{}, err
+			}
+			converted.Kind = "event"
+			converted.Event = &event
+
+		case entry.Task != nil:
+			task := jsonTodoItem(entry.Task, plan.Now)
+			converted.Kind = "task"
+			converted.Task = &task
+			converted.Late = entry.Late
+
+		case entry.Lunch:
+			converted.Kind = "lunch"
+		}
+
+		entries = append(entries, converted)
+	}
+
+	allDayEvents, err := jsonEvents(plan.AllDayEvents)
+	if err != nil {
+		return JSONPlan{}, err
+	}
+
+	return JSONPlan{
+		Start:            jsonTime(&plan.Start),
+		End:              jsonTime(&plan.End),
+		Entries:          entries,
+		AllDayEvents:     allDayEvents,
+		UnscheduledTasks: jsonTodoItems(plan.UnscheduledTasks.Enumerate(), plan.Now),
+	}, nil
+}
+
+func jsonSearchResults(data *SearchResultsViewData, now time.Time) (JSONSearchResults, error) {
+	results := []JSONSearchResult{}
+	for _, result := range data.Results {
+		converted := JSONSearchResult{
+			Rank:    result.Rank,
+			Snippet: unmarkedSnippet(result.Snippet),
+		}
+
+		if result.TodoItem != nil {
+			item := jsonTodoItem(result.TodoItem, now)
+			converted.Kind = "todo"
+			converted.TodoItem = &item
+		}
+
+		if result.Event != nil {
+			event, err := jsonEvent(result.Event.Event)
+			if err != nil {
+				return JSONSearchResults{}, err
+			}
+			calendarName := result.Event.Calendar
+			converted.Kind = "event"
+			converted.Event = &event
+			converted.Calendar = &calendarName
+		}
+
+		results = append(results, converted)
+	}
+
+	return JSONSearchResults{Query: data.Query, Results: results}, nil
+}
+
+func eventProperty(event *ical.VEvent, property ical.Property) string {
+	prop := event.GetProperty(ical.ComponentProperty(property))
+	if prop == nil {
+		return ""
+	}
+
+	return prop.Value
+}
+
+// nonNil makes sure empty lists are written as [] rather than null
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+
+	return values
+}
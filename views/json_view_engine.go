@@ -0,0 +1,70 @@
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONViewEngine writes the data of each view as a JSON document,
+// instead of drawing it, so that what-next can be scripted against.
+// The schemas of the documents are in json_schema.go.
+type JSONViewEngine struct {
+	out io.Writer
+}
+
+func NewJSONViewEngine(out io.Writer) *JSONViewEngine {
+	return &JSONViewEngine{
+		out: out,
+	}
+}
+
+func (ve *JSONViewEngine) Draw(view ViewInterface) error {
+	document, err := jsonDocument(view)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(ve.out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(document)
+}
+
+// jsonDocument converts the data of a view in to its JSON schema
+func jsonDocument(view ViewInterface) (interface{}, error) {
+	switch v := view.(type) {
+	case *ScheduleView:
+		return jsonSchedule(v.schedule)
+
+	case *StatusView:
+		return jsonSchedule(v.schedule)
+
+	case *TodoListView:
+		return JSONTodoList{TodoItems: jsonTodoItems(v.todoItems.Enumerate(), v.Now)}, nil
+
+	case *TodoItemView:
+		return JSONTodoItemDocument{TodoItem: jsonTodoItem(v.item, v.Now)}, nil
+
+	case *CalendarView:
+		return jsonCalendar(v.data)
+
+	case *CalendarListView:
+		return jsonCalendarList(v.calendars), nil
+
+	case *PlanView:
+		return jsonPlan(v.plan)
+
+	case *SearchResultsView:
+		return jsonSearchResults(v.data, v.Now)
+
+	case *MessageView:
+		return JSONMessage{Message: v.message}, nil
+
+	case *RemovedTodoItemsView:
+		return JSONRemovedTodoItems{RemovedIds: nonNil(v.ids)}, nil
+
+	default:
+		return nil, fmt.Errorf("%T can't be shown as JSON", view)
+	}
+}
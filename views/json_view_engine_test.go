@@ -0,0 +1,205 @@
+package views_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	"github.com/AP-Hunt/what-next/m/views"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSONViewEngine", func() {
+	var (
+		out    *bytes.Buffer
+		engine *views.JSONViewEngine
+		now    time.Time
+	)
+
+	draw := func(view views.ViewInterface) map[string]interface{} {
+		Expect(engine.Draw(view)).To(Succeed())
+
+		document := map[string]interface{}{}
+		Expect(json.Unmarshal(out.Bytes(), &document)).To(Succeed())
+		return document
+	}
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		engine = views.NewJSONViewEngine(out)
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+	})
+
+	It("writes todo lists with ISO 8601 times, durations in seconds, and nulls for missing values", func() {
+		duration := 30 * time.Minute
+		recurrence := "FREQ=WEEKLY"
+		view := &views.TodoListView{}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{
+			{Id: 1, Action: "foo", DueDate: &now, Duration: &duration, Priority: todo.PriorityHigh, Recurrence: &recurrence, Tags: []string{"@phone"}},
+			{Id: 2, Action: "bar", Priority: todo.PriorityMedium},
+		}))
+
+		items := draw(view)["todo_items"].([]interface{})
+		Expect(items).To(HaveLen(2))
+
+		first := items[0].(map[string]interface{})
+		Expect(first["id"]).To(BeEquivalentTo(1))
+		Expect(first["due"]).To(Equal(now.Format(time.RFC3339)))
+		Expect(first["duration_seconds"]).To(BeEquivalentTo(1800))
+		Expect(first["priority"]).To(BeEquivalentTo(2))
+		Expect(first["priority_name"]).To(Equal("high"))
+		Expect(first["recurrence"]).To(Equal("FREQ=WEEKLY"))
+		Expect(first["tags"]).To(Equal([]interface{}{"@phone"}))
+
+		second := items[1].(map[string]interface{})
+		Expect(second).To(HaveKeyWithValue("due", BeNil()))
+		Expect(second).To(HaveKeyWithValue("duration_seconds", BeNil()))
+		Expect(second["tags"]).To(Equal([]interface{}{}))
+		Expect(second["links"]).To(Equal([]interface{}{}))
+	})
+
+	It("writes whether todo items are overdue at the view's time, rather than the time they're written", func() {
+		earlier := now.Add(-time.Hour)
+		later := now.Add(time.Hour)
+		view := &views.TodoListView{Now: now}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{
+			{Id: 1, Action: "foo", DueDate: &earlier},
+			{Id: 2, Action: "bar", DueDate: &later},
+			{Id: 3, Action: "baz", DueDate: &earlier, Completed: true},
+		}))
+
+		items := draw(view)["todo_items"].([]interface{})
+		Expect(items[0].(map[string]interface{})["overdue"]).To(BeTrue())
+		Expect(items[1].(map[string]interface{})["overdue"]).To(BeFalse())
+		Expect(items[2].(map[string]interface{})["overdue"]).To(BeFalse())
+	})
+
+	It("writes a single todo item", func() {
+		view := &views.TodoItemView{}
+		view.SetData(&todo.TodoItem{Id: 3, Action: "foo", Notes: "bar"})
+
+		item := draw(view)["todo_item"].(map[string]interface{})
+		Expect(item["id"]).To(BeEquivalentTo(3))
+		Expect(item["notes"]).To(Equal("bar"))
+	})
+
+	It("writes schedules", func() {
+		event := ical.NewEvent("standup")
+		event.SetProperty(ical.ComponentProperty(ical.PropertySummary), "Standup")
+		event.SetStartAt(now.Add(30 * time.Minute))
+		event.SetEndAt(now.Add(45 * time.Minute))
+
+		untilNext := 30 * time.Minute
+		view := &views.ScheduleView{}
+		view.SetData(&scheduler.Schedule{
+			CurrentCalendarEvents:            []*ical.VEvent{},
+			NextCalendarEvents:               []*ical.VEvent{event},
+			TimeUntilNextCalendarEvent:       &untilNext,
+			UsableTimeUntilNextCalendarEvent: &untilNext,
+			AchievableTasks:                  *todo.NewTodoItemCollection([]*todo.TodoItem{{Id: 1, Action: "foo"}}),
+			IsWorkingTime:                    true,
+		})
+
+		document := draw(view)
+		Expect(document["current_events"]).To(Equal([]interface{}{}))
+		Expect(document["seconds_until_next_event"]).To(BeEquivalentTo(1800))
+		Expect(document["free_seconds"]).To(BeEquivalentTo(1800))
+		Expect(document["is_working_time"]).To(BeTrue())
+		Expect(document["end_of_working_day"]).To(BeNil())
+		Expect(document["achievable_tasks"]).To(HaveLen(1))
+
+		next := document["next_events"].([]interface{})[0].(map[string]interface{})
+		Expect(next["uid"]).To(Equal("standup"))
+		Expect(next["summary"]).To(Equal("Standup"))
+		Expect(next["start"]).To(Equal(now.Add(30 * time.Minute).Format(time.RFC3339)))
+		Expect(next["duration_seconds"]).To(BeEquivalentTo(900))
+		Expect(next["all_day"]).To(BeFalse())
+	})
+
+	It("writes a day's calendar with its events in order", func() {
+		cal := ical.NewCalendar()
+		later := cal.AddEvent("later")
+		later.SetStartAt(now.Add(2 * time.Hour))
+		later.SetEndAt(now.Add(3 * time.Hour))
+		earlier := cal.AddEvent("earlier")
+		earlier.SetStartAt(now)
+		earlier.SetEndAt(now.Add(time.Hour))
+
+		view := &views.CalendarView{}
+		view.SetData(&views.CalendarViewData{Calendar: cal, TargetDate: now})
+
+		document := draw(view)
+		Expect(document["date"]).To(Equal("2022-10-17"))
+
+		events := document["events"].([]interface{})
+		Expect(events[0].(map[string]interface{})["uid"]).To(Equal("earlier"))
+		Expect(events[1].(map[string]interface{})["uid"]).To(Equal("later"))
+	})
+
+	It("writes the list of calendars, with their buffers in seconds", func() {
+		before := 5 * time.Minute
+		view := &views.CalendarListView{}
+		view.SetData([]calendar.CalendarRecord{{Id: 1, DisplayName: "work", URL: "file:///work.ical", BufferBefore: &before}})
+
+		calendars := draw(view)["calendars"].([]interface{})
+		Expect(calendars[0]).To(Equal(map[string]interface{}{
+			"id":                    float64(1),
+			"name":                  "work",
+			"url":                   "file:///work.ical",
+			"buffer_before_seconds": float64(300),
+			"buffer_after_seconds":  nil,
+		}))
+	})
+
+	It("writes plans, with the kind of each entry", func() {
+		task := &todo.TodoItem{Id: 1, Action: "foo"}
+		view := &views.PlanView{}
+		view.SetData(&scheduler.Plan{
+			Start: now,
+			End:   now.Add(2 * time.Hour),
+			Entries: []scheduler.PlanEntry{
+				{Start: now, End: now.Add(time.Hour), Task: task, Late: true},
+				{Start: now.Add(time.Hour), End: now.Add(90 * time.Minute), Lunch: true},
+				{Start: now.Add(90 * time.Minute), End: now.Add(2 * time.Hour)},
+			},
+		})
+
+		entries := draw(view)["entries"].([]interface{})
+		kinds := []interface{}{}
+		for _, entry := range entries {
+			kinds = append(kinds, entry.(map[string]interface{})["kind"])
+		}
+
+		Expect(kinds).To(Equal([]interface{}{"task", "lunch", "free"}))
+		Expect(entries[0].(map[string]interface{})["task"].(map[string]interface{})["id"]).To(BeEquivalentTo(1))
+		Expect(entries[0].(map[string]interface{})["late"]).To(BeTrue())
+		Expect(entries[1].(map[string]interface{})["duration_seconds"]).To(BeEquivalentTo(1800))
+	})
+
+	It("writes messages", func() {
+		view := &views.MessageView{}
+		view.SetData("Todo item 1 isn't complete.")
+
+		Expect(draw(view)).To(Equal(map[string]interface{}{"message": "Todo item 1 isn't complete."}))
+	})
+
+	It("writes the ids of removed todo items", func() {
+		view := &views.RemovedTodoItemsView{}
+		view.SetData([]int{1, 2})
+
+		Expect(draw(view)["removed_ids"]).To(Equal([]interface{}{1.0, 2.0}))
+	})
+
+	It("returns an error for views it doesn't know", func() {
+		Expect(engine.Draw(&unknownView{})).To(MatchError(ContainSubstring("can't be shown as JSON")))
+	})
+})
+
+type unknownView struct {
+	views.TodoListView
+}
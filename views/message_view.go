@@ -0,0 +1,26 @@
+package views
+
+import (
+	"fmt"
+	"io"
+)
+
+// MessageView shows a message about what a command did, for
+// commands which have nothing else to show, such as when
+// there was nothing for them to do
+type MessageView struct {
+	message string
+}
+
+func (v *MessageView) Draw(out io.Writer) error {
+	_, err := fmt.Fprintln(out, v.message)
+	return err
+}
+
+func (v *MessageView) SetData(data interface{}) {
+	v.message = data.(string)
+}
+
+func (v *MessageView) Data() interface{} {
+	return v.message
+}
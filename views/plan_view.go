@@ -0,0 +1,108 @@
+package views
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	ical "github.com/arran4/golang-ical"
+	"github.com/fatih/color"
+	"github.com/hako/durafmt"
+)
+
+type PlanView struct {
+	plan *scheduler.Plan
+}
+
+func (p *PlanView) Draw(out io.Writer) error {
+	boldWhite := color.New(color.FgWhite, color.Bold)
+	eventStyle := color.New(color.FgCyan)
+	taskStyle := color.New(color.FgGreen)
+	lateStyle := color.New(color.FgRed, color.Bold)
+	freeStyle := color.New(color.FgHiBlack)
+
+	start := p.plan.Start.Local()
+	end := p.plan.End.Local()
+
+	boldWhite.Fprintf(
+		out,
+		"Your plan for %s, from %02d%02d until %02d%02d\n",
+		start.Format("Monday January _2"),
+		start.Hour(),
+		start.Minute(),
+		end.Hour(),
+		end.Minute(),
+	)
+	fmt.Fprintln(out)
+
+	if !p.plan.End.After(p.plan.Start) {
+		boldWhite.Fprintln(out, "You don't have any working time left today 🎉️")
+		fmt.Fprintln(out)
+	}
+
+	for _, evt := range p.plan.AllDayEvents {
+		fmt.Fprintf(out, "\t%-9s %s\n", "All day", eventStyle.Sprint(summary(evt)))
+	}
+
+	for _, entry := range p.plan.Entries {
+		entryStart := entry.Start.Local()
+		entryEnd := entry.End.Local()
+
+		description := ""
+		switch {
+		case entry.Event != nil:
+			description = eventStyle.Sprint(summary(entry.Event))
+		case entry.Task != nil && entry.Late:
+			description = lateStyle.Sprintf("#%d %s (late, due %s)", entry.Task.Id, entry.Task.Action, FormatRelativeDate(entry.Task.DueDate.Local()))
+		case entry.Task != nil:
+			description = taskStyle.Sprintf("#%d %s", entry.Task.Id, entry.Task.Action)
+		case entry.Lunch:
+			description = eventStyle.Sprint("Lunch")
+		default:
+			description = freeStyle.Sprintf("Free for %s", durafmt.Parse(entry.Duration()).LimitFirstN(2))
+		}
+
+		fmt.Fprintf(
+			out,
+			"\t%02d%02d-%02d%02d %s\n",
+			entryStart.Hour(),
+			entryStart.Minute(),
+			entryEnd.Hour(),
+			entryEnd.Minute(),
+			description,
+		)
+	}
+	fmt.Fprintln(out)
+
+	if p.plan.UnscheduledTasks.Len() > 0 {
+		boldWhite.Fprintln(out, "These tasks don't have a duration, or don't fit in to your day")
+		fmt.Fprintln(out)
+
+		todoListView := TodoListView{Now: p.plan.Now}
+		todoListView.SetData(&p.plan.UnscheduledTasks)
+
+		err := todoListView.Draw(out)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PlanView) SetData(data interface{}) {
+	p.plan = data.(*scheduler.Plan)
+}
+
+func (p *PlanView) Data() interface{} {
+	return p.plan
+}
+
+func summary(evt *ical.VEvent) string {
+	summaryProp := evt.GetProperty(ical.ComponentProperty(ical.PropertySummary))
+	if summaryProp == nil {
+		return ""
+	}
+
+	return summaryProp.Value
+}
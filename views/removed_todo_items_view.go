@@ -0,0 +1,30 @@
+package views
+
+import (
+	"fmt"
+	"io"
+)
+
+// RemovedTodoItemsView lists the ids of the todo items which were removed
+type RemovedTodoItemsView struct {
+	ids []int
+}
+
+func (v *RemovedTodoItemsView) Draw(out io.Writer) error {
+	for _, id := range v.ids {
+		_, err := fmt.Fprintf(out, "Todo item %d removed.\n", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *RemovedTodoItemsView) SetData(data interface{}) {
+	v.ids = data.([]int)
+}
+
+func (v *RemovedTodoItemsView) Data() interface{} {
+	return v.ids
+}
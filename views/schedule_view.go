@@ -26,6 +26,11 @@ func (s *ScheduleView) Draw(out io.Writer) error {
 		return err
 	}
 
+	err = s.drawWorkingDay(out)
+	if err != nil {
+		return err
+	}
+
 	err = s.drawAchievableTasks(out)
 	if err != nil {
 		return err
@@ -34,6 +39,32 @@ func (s *ScheduleView) Draw(out io.Writer) error {
 	return nil
 }
 
+func (s *ScheduleView) drawWorkingDay(out io.Writer) error {
+	if s.schedule.TimeLeftInWorkingDay == nil {
+		return nil
+	}
+
+	boldWhite := color.New(color.FgWhite, color.Bold)
+
+	if s.schedule.EndOfWorkingDay == nil {
+		boldWhite.Fprintln(out, "You aren't working today 🎉️")
+	} else if *s.schedule.TimeLeftInWorkingDay <= 0 {
+		boldWhite.Fprintln(out, "Your working day is over 🎉️")
+	} else {
+		end := s.schedule.EndOfWorkingDay.Local()
+		boldWhite.Fprintf(
+			out,
+			"You have %s left in your working day, which ends at %02d%02d\n",
+			durafmt.Parse(*s.schedule.TimeLeftInWorkingDay).LimitFirstN(2),
+			end.Hour(),
+			end.Minute(),
+		)
+	}
+	fmt.Fprintln(out)
+
+	return nil
+}
+
 func (s *ScheduleView) drawCurrentMeeting(out io.Writer) error {
 	boldWhite := color.New(color.FgWhite, color.Bold)
 
@@ -112,8 +143,14 @@ func (s *ScheduleView) drawAchievableTasks(out io.Writer) error {
 	boldWhite := color.New(color.FgWhite, color.Bold)
 
 	anyTasks := s.schedule.AchievableTasks.Len() > 0
+	freeTime := s.schedule.FreeTime()
 
-	if s.schedule.TimeUntilNextCalendarEvent == nil {
+	if !s.schedule.IsWorkingTime {
+		if s.schedule.TimeLeftInWorkingDay != nil && *s.schedule.TimeLeftInWorkingDay > 0 {
+			boldWhite.Fprintln(out, "It's not working time right now, so take a break")
+		}
+		return nil
+	} else if freeTime == nil {
 		if anyTasks {
 			boldWhite.Fprintln(out, "In the rest of your day, these are the tasks you could try to complete")
 		} else {
@@ -121,17 +158,32 @@ func (s *ScheduleView) drawAchievableTasks(out io.Writer) error {
 			return nil
 		}
 	} else {
+		until := "until your next meeting"
+		if freeTime == s.schedule.TimeUntilLunch {
+			until = "until lunch"
+		} else if freeTime == s.schedule.TimeLeftInWorkingDay {
+			until = "left in your working day"
+		}
+
+		durationStr := durafmt.Parse(*freeTime).LimitFirstN(2).String()
+		if freeTime == s.schedule.UsableTimeUntilNextCalendarEvent && *freeTime != *s.schedule.TimeUntilNextCalendarEvent {
+			// Show the raw gap as well, so the buffers don't make the time look wrong
+			durationStr = fmt.Sprintf(
+				"%s you can use of the %s",
+				durationStr,
+				durafmt.Parse(*s.schedule.TimeUntilNextCalendarEvent).LimitFirstN(2),
+			)
+		}
 
-		durationStr := durafmt.Parse(*s.schedule.TimeUntilNextCalendarEvent).LimitFirstN(2)
 		if anyTasks {
-			boldWhite.Fprintf(out, "In the %s until your next meeting, these are the tasks you could try to complete\n", durationStr)
+			boldWhite.Fprintf(out, "In the %s %s, these are the tasks you could try to complete\n", durationStr, until)
 		} else {
-			boldWhite.Fprintf(out, "In the %s until your next meeting, there are no achievable things on you todo list\n", durationStr)
+			boldWhite.Fprintf(out, "In the %s %s, there are no achievable things on you todo list\n", durationStr, until)
 		}
 	}
 	fmt.Fprintln(out)
 
-	todoListView := TodoListView{}
+	todoListView := TodoListView{Now: s.schedule.Now}
 	todoListView.SetData(&s.schedule.AchievableTasks)
 
 	err := todoListView.Draw(out)
@@ -0,0 +1,153 @@
+package views
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/search"
+	"github.com/alexeyco/simpletable"
+	ical "github.com/arran4/golang-ical"
+	"github.com/fatih/color"
+	"github.com/isbm/textwrap"
+)
+
+// SearchResultsView shows the todo items and calendar
+// events which matched a search together, most relevant first
+type SearchResultsView struct {
+	// Now is the time todo items which were due before it are overdue
+	Now time.Time
+
+	data *SearchResultsViewData
+}
+
+type SearchResultsViewData struct {
+	Query   string
+	Results []search.Result
+}
+
+func (v *SearchResultsView) Draw(out io.Writer) error {
+	if len(v.data.Results) == 0 {
+		fmt.Fprintf(out, "Nothing matched '%s'\n", v.data.Query)
+		return nil
+	}
+
+	tbl := simpletable.New()
+	tbl.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: "#"},
+			{Align: simpletable.AlignLeft, Text: "Type"},
+			{Align: simpletable.AlignLeft, Text: "When"},
+			{Align: simpletable.AlignLeft, Text: "What"},
+			{Align: simpletable.AlignLeft, Text: "Calendar"},
+		},
+	}
+	tbl.SetStyle(simpletable.StyleCompactLite)
+
+	overdueStyle := color.New(color.FgRed, color.Bold)
+	snippetStyle := color.New(color.FgHiBlack)
+
+	textWrapper := textwrap.NewTextWrap()
+	textWrapper.SetWidth(60)
+
+	for _, result := range v.data.Results {
+		id := ""
+		kind := ""
+		when := ""
+		title := ""
+		calendarName := ""
+
+		if result.TodoItem != nil {
+			item := result.TodoItem
+			id = strconv.Itoa(item.Id)
+			kind = "todo"
+			if item.Completed {
+				kind = "todo ✓"
+			}
+
+			title = item.Action
+
+			if item.DueDate != nil {
+				when = FormatRelativeDate(*item.DueDate)
+				if item.IsOverdue(v.Now) {
+					when = overdueStyle.Sprint(when)
+				}
+			}
+		}
+
+		if result.Event != nil {
+			kind = "event"
+			calendarName = result.Event.Calendar
+
+			if summary := result.Event.Event.GetProperty(ical.ComponentProperty(ical.PropertySummary)); summary != nil {
+				title = summary.Value
+			}
+
+			start, _, err := calendar.EventStartAndEnd(result.Event.Event)
+			if err != nil {
+				return err
+			}
+
+			when = FormatRelativeDate(start.Local())
+			if isAllDay, _ := calendar.IsAllDayEvent(result.Event.Event); isAllDay {
+				when = start.Local().Format("Mon 2 Jan 2006")
+			}
+		}
+
+		// The snippet is the title when that's what matched, otherwise
+		// it's shown under the title so it's clear why the result matched
+		snippet := strings.Join(strings.Fields(result.Snippet), " ")
+		what := highlightSnippet(textWrapper.Fill(snippet))
+		if unmarkedSnippet(snippet) != title {
+			what = textWrapper.Fill(title) + "\n" + snippetStyle.Sprint(what)
+		}
+
+		row := []*simpletable.Cell{
+			{Align: simpletable.AlignRight, Text: id},
+			{Align: simpletable.AlignLeft, Text: kind},
+			{Align: simpletable.AlignLeft, Text: when},
+			{Align: simpletable.AlignLeft, Text: what},
+			{Align: simpletable.AlignLeft, Text: calendarName},
+		}
+
+		tbl.Body.Cells = append(tbl.Body.Cells, row)
+	}
+
+	fmt.Fprintln(out, tbl.String())
+
+	return nil
+}
+
+// highlightSnippet replaces the markers around the text which
+// matched the query in a snippet with a highlight
+func highlightSnippet(snippet string) string {
+	matchStyle := color.New(color.FgYellow, color.Bold)
+
+	highlighted := ""
+	for _, part := range strings.Split(snippet, search.SnippetMatchStart) {
+		match, rest, found := strings.Cut(part, search.SnippetMatchEnd)
+		if !found {
+			highlighted += part
+			continue
+		}
+
+		highlighted += matchStyle.Sprint(match) + rest
+	}
+
+	return highlighted
+}
+
+func unmarkedSnippet(snippet string) string {
+	return strings.NewReplacer(search.SnippetMatchStart, "", search.SnippetMatchEnd, "").Replace(snippet)
+}
+
+func (v *SearchResultsView) SetData(data interface{}) {
+	v.data = data.(*SearchResultsViewData)
+}
+
+func (v *SearchResultsView) Data() interface{} {
+	return v.data
+}
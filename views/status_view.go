@@ -0,0 +1,201 @@
+package views
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	ical "github.com/arran4/golang-ical"
+	"github.com/mattn/go-runewidth"
+)
+
+// DefaultStatusFormat is the line StatusView shows when it isn't given a format
+const DefaultStatusFormat = "⏳ {until} until {next} · {tasks}||{tasks}"
+
+// StatusFormatAlternative separates the alternatives in a status format
+const StatusFormatAlternative = "||"
+
+// StatusView shows the schedule on a single line, for status
+// bars and shell prompts.
+//
+// The line is made from a format, in which placeholders such as {next}
+// are replaced with parts of the schedule. A format can have alternatives
+// separated by "||", and the first whose placeholders all have a value
+// is shown, so that a line about the next meeting can fall back to one
+// without it when there are no more meetings.
+type StatusView struct {
+	// Format is DefaultStatusFormat when it's empty
+	Format string
+
+	// MaxWidth is the number of columns the line is cut to
+	// fit in, or 0 when the line can be any width
+	MaxWidth int
+
+	schedule *scheduler.Schedule
+}
+
+var regexStatusPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// statusPlaceholders are the values which can be put in a status format.
+// They are empty when the schedule doesn't have them.
+var statusPlaceholders = map[string]func(schedule *scheduler.Schedule) (string, error){
+	"current": func(schedule *scheduler.Schedule) (string, error) {
+		return firstEventSummary(schedule.CurrentCalendarEvents), nil
+	},
+	"next": func(schedule *scheduler.Schedule) (string, error) {
+		return firstEventSummary(schedule.NextCalendarEvents), nil
+	},
+	"at": func(schedule *scheduler.Schedule) (string, error) {
+		if len(schedule.NextCalendarEvents) == 0 {
+			return "", nil
+		}
+
+		start, _, err := calendar.EventStartAndEnd(schedule.NextCalendarEvents[0])
+		if err != nil {
+			return "", err
+		}
+		return start.Local().Format("15:04"), nil
+	},
+	"until": func(schedule *scheduler.Schedule) (string, error) {
+		return formatShortDuration(schedule.TimeUntilNextCalendarEvent), nil
+	},
+	"free": func(schedule *scheduler.Schedule) (string, error) {
+		return formatShortDuration(schedule.FreeTime()), nil
+	},
+	"left": func(schedule *scheduler.Schedule) (string, error) {
+		return formatShortDuration(schedule.TimeLeftInWorkingDay), nil
+	},
+	"tasks": func(schedule *scheduler.Schedule) (string, error) {
+		switch count := schedule.AchievableTasks.Len(); count {
+		case 0:
+			return "no tasks fit", nil
+		case 1:
+			return "1 task fits", nil
+		default:
+			return fmt.Sprintf("%d tasks fit", count), nil
+		}
+	},
+	"count": func(schedule *scheduler.Schedule) (string, error) {
+		return fmt.Sprint(schedule.AchievableTasks.Len()), nil
+	},
+	"task": func(schedule *scheduler.Schedule) (string, error) {
+		if schedule.AchievableTasks.Len() == 0 {
+			return "", nil
+		}
+		return schedule.AchievableTasks.Enumerate()[0].Action, nil
+	},
+}
+
+// StatusPlaceholders are the names of the placeholders which can be used in a status format
+var StatusPlaceholders = []string{"current", "next", "at", "until", "free", "left", "tasks", "count", "task"}
+
+func (v *StatusView) Draw(out io.Writer) error {
+	line, err := v.Line()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, line)
+	return nil
+}
+
+// Line returns the line the view shows, without a line break
+func (v *StatusView) Line() (string, error) {
+	format := v.Format
+	if format == "" {
+		format = DefaultStatusFormat
+	}
+
+	alternatives := strings.Split(format, StatusFormatAlternative)
+	line := ""
+	for i, alternative := range alternatives {
+		expanded, complete, err := v.expand(alternative)
+		if err != nil {
+			return "", err
+		}
+
+		if complete || i == len(alternatives)-1 {
+			line = expanded
+			break
+		}
+	}
+
+	if v.MaxWidth > 0 {
+		line = runewidth.Truncate(line, v.MaxWidth, "…")
+	}
+
+	return line, nil
+}
+
+// expand replaces the placeholders in a format, and returns
+// whether every one of them had a value
+func (v *StatusView) expand(format string) (string, bool, error) {
+	complete := true
+	var expandErr error
+
+	expanded := regexStatusPlaceholder.ReplaceAllStringFunc(format, func(placeholder string) string {
+		name := regexStatusPlaceholder.FindStringSubmatch(placeholder)[1]
+
+		value, ok := statusPlaceholders[name]
+		if !ok {
+			expandErr = fmt.Errorf("unknown placeholder %s in status format, expected one of {%s}", placeholder, strings.Join(StatusPlaceholders, "}, {"))
+			return placeholder
+		}
+
+		replacement, err := value(v.schedule)
+		if err != nil {
+			expandErr = err
+			return placeholder
+		}
+
+		if replacement == "" {
+			complete = false
+		}
+		return replacement
+	})
+
+	return expanded, complete, expandErr
+}
+
+// formatShortDuration formats a duration in as few characters
+// as it can, such as "1h5m", and is empty when it's nil
+func formatShortDuration(duration *time.Duration) string {
+	if duration == nil {
+		return ""
+	}
+
+	minutes := int(duration.Truncate(time.Minute).Minutes())
+	if minutes < 0 {
+		minutes = 0
+	}
+	hours, minutes := minutes/60, minutes%60
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
+
+func firstEventSummary(events []*ical.VEvent) string {
+	if len(events) == 0 {
+		return ""
+	}
+
+	return eventProperty(events[0], ical.PropertySummary)
+}
+
+func (v *StatusView) SetData(data interface{}) {
+	v.schedule = data.(*scheduler.Schedule)
+}
+
+func (v *StatusView) Data() interface{} {
+	return v.schedule
+}
@@ -0,0 +1,105 @@
+package views_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	"github.com/AP-Hunt/what-next/m/views"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StatusView", func() {
+	var (
+		now      time.Time
+		schedule *scheduler.Schedule
+	)
+
+	line := func(view *views.StatusView) string {
+		view.SetData(schedule)
+		line, err := view.Line()
+		Expect(err).ToNot(HaveOccurred())
+		return line
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+
+		event := ical.NewEvent("review")
+		event.SetProperty(ical.ComponentProperty(ical.PropertySummary), "Sprint review")
+		event.SetStartAt(now.Add(23 * time.Minute))
+		event.SetEndAt(now.Add(time.Hour))
+
+		untilNext := 23 * time.Minute
+		schedule = &scheduler.Schedule{
+			NextCalendarEvents:               []*ical.VEvent{event},
+			TimeUntilNextCalendarEvent:       &untilNext,
+			UsableTimeUntilNextCalendarEvent: &untilNext,
+			IsWorkingTime:                    true,
+			AchievableTasks: *todo.NewTodoItemCollection([]*todo.TodoItem{
+				{Id: 1, Action: "foo"},
+				{Id: 2, Action: "bar"},
+				{Id: 3, Action: "baz"},
+			}),
+		}
+	})
+
+	It("shows the time until the next meeting and the number of tasks which fit by default", func() {
+		Expect(line(&views.StatusView{})).To(Equal("⏳ 23m until Sprint review · 3 tasks fit"))
+	})
+
+	It("says when a single task fits", func() {
+		schedule.AchievableTasks = *todo.NewTodoItemCollection([]*todo.TodoItem{{Id: 1, Action: "foo"}})
+
+		Expect(line(&views.StatusView{Format: "{tasks}"})).To(Equal("1 task fits"))
+	})
+
+	It("falls back to the next alternative when a placeholder doesn't have a value", func() {
+		schedule.NextCalendarEvents = []*ical.VEvent{}
+		schedule.TimeUntilNextCalendarEvent = nil
+
+		Expect(line(&views.StatusView{})).To(Equal("3 tasks fit"))
+	})
+
+	It("shows the last alternative when none of them have every value", func() {
+		schedule.NextCalendarEvents = []*ical.VEvent{}
+
+		Expect(line(&views.StatusView{Format: "{next}||{current}"})).To(Equal(""))
+	})
+
+	It("uses the format it's given", func() {
+		Expect(line(&views.StatusView{Format: "{task} ({free}) then {next} at {at}"})).
+			To(Equal("foo (23m) then Sprint review at 09:53"))
+	})
+
+	It("formats durations over an hour with hours and minutes", func() {
+		untilNext := 65 * time.Minute
+		schedule.TimeUntilNextCalendarEvent = &untilNext
+
+		Expect(line(&views.StatusView{Format: "{until}"})).To(Equal("1h5m"))
+	})
+
+	It("cuts the line to the maximum width", func() {
+		Expect(line(&views.StatusView{MaxWidth: 15})).To(Equal("⏳ 23m until S…"))
+	})
+
+	It("returns an error for placeholders it doesn't know", func() {
+		view := &views.StatusView{Format: "{foo}"}
+		view.SetData(schedule)
+
+		_, err := view.Line()
+		Expect(err).To(MatchError(ContainSubstring("unknown placeholder {foo}")))
+	})
+
+	It("draws the line", func() {
+		out := &bytes.Buffer{}
+		view := &views.StatusView{Format: "{count} {tasks}"}
+		view.SetData(schedule)
+
+		Expect(view.Draw(out)).To(Succeed())
+		Expect(out.String()).To(Equal("3 3 tasks fit\n"))
+	})
+})
@@ -0,0 +1,155 @@
+package views
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/calendar"
+	ical "github.com/arran4/golang-ical"
+	"github.com/hako/durafmt"
+)
+
+// TemplateViewEngine renders the data of each view with a user's
+// text/template, instead of drawing the view. The template is given
+// the same data as the view, such as a *scheduler.Schedule for the
+// schedule, and can use the functions in TemplateFuncs.
+type TemplateViewEngine struct {
+	out      io.Writer
+	template *template.Template
+}
+
+func NewTemplateViewEngine(out io.Writer, tmpl *template.Template) *TemplateViewEngine {
+	return &TemplateViewEngine{
+		out:      out,
+		template: tmpl,
+	}
+}
+
+func (ve *TemplateViewEngine) Draw(view ViewInterface) error {
+	return ve.template.Execute(ve.out, view.Data())
+}
+
+// TemplateExtension is the extension of the named templates in the templates directory
+const TemplateExtension = ".tmpl"
+
+// ParseTemplateFile reads and parses the template in the file at the path
+func ParseTemplateFile(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading template: %s", err)
+	}
+
+	return ParseTemplate(filepath.Base(path), string(content))
+}
+
+// ParseNamedTemplate reads and parses the template with the name in the
+// templates directory, which is the file with the name and TemplateExtension
+func ParseNamedTemplate(dir string, name string) (*template.Template, error) {
+	path := filepath.Join(dir, name+TemplateExtension)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("there is no template named '%s', it should be at %s", name, path)
+	}
+
+	return ParseTemplateFile(path)
+}
+
+// ParseTemplate parses a template, with the functions in TemplateFuncs
+func ParseTemplate(name string, content string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %s", err)
+	}
+
+	return tmpl, nil
+}
+
+// TemplateFuncs are the functions templates can use to format what they show
+var TemplateFuncs = template.FuncMap{
+	// duration formats a time.Duration, or a *time.Duration which is
+	// empty when nil, to the two largest units, e.g. "1 hour 30 minutes"
+	"duration": func(d interface{}) (string, error) {
+		switch duration := d.(type) {
+		case time.Duration:
+			return durafmt.Parse(duration).LimitFirstN(2).String(), nil
+		case *time.Duration:
+			if duration == nil {
+				return "", nil
+			}
+			return durafmt.Parse(*duration).LimitFirstN(2).String(), nil
+		default:
+			return "", fmt.Errorf("duration needs a duration, not %T", d)
+		}
+	},
+
+	// due formats a due date the way the todo list does, e.g. "Tomorrow, 3:00PM",
+	// and is empty when the item doesn't have one
+	"due": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return FormatRelativeDate(t.Local())
+	},
+
+	// formatTime formats a time.Time, or a *time.Time which is empty
+	// when nil, with a Go time layout, e.g. {{ formatTime "15:04" .Start }}
+	"formatTime": func(layout string, t interface{}) (string, error) {
+		switch value := t.(type) {
+		case time.Time:
+			return value.Local().Format(layout), nil
+		case *time.Time:
+			if value == nil {
+				return "", nil
+			}
+			return value.Local().Format(layout), nil
+		default:
+			return "", fmt.Errorf("formatTime needs a time, not %T", t)
+		}
+	},
+
+	// event formats an event the way the schedule does, e.g. "0930-1000 Standup"
+	"event": func(event *ical.VEvent) (string, error) {
+		start, end, err := calendar.EventStartAndEnd(event)
+		if err != nil {
+			return "", err
+		}
+
+		start, end = start.Local(), end.Local()
+		return fmt.Sprintf(
+			"%02d%02d-%02d%02d %s",
+			start.Hour(),
+			start.Minute(),
+			end.Hour(),
+			end.Minute(),
+			eventProperty(event, ical.PropertySummary),
+		), nil
+	},
+
+	"summary": func(event *ical.VEvent) string {
+		return eventProperty(event, ical.PropertySummary)
+	},
+
+	"description": func(event *ical.VEvent) string {
+		return eventProperty(event, ical.PropertyDescription)
+	},
+
+	"location": func(event *ical.VEvent) string {
+		return eventProperty(event, ical.PropertyLocation)
+	},
+
+	"eventStart": func(event *ical.VEvent) (time.Time, error) {
+		start, _, err := calendar.EventStartAndEnd(event)
+		return start.Local(), err
+	},
+
+	"eventEnd": func(event *ical.VEvent) (time.Time, error) {
+		_, end, err := calendar.EventStartAndEnd(event)
+		return end.Local(), err
+	},
+
+	"join": strings.Join,
+}
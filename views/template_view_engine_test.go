@@ -0,0 +1,95 @@
+package views_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/scheduler"
+	"github.com/AP-Hunt/what-next/m/todo"
+	"github.com/AP-Hunt/what-next/m/views"
+	ical "github.com/arran4/golang-ical"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateViewEngine", func() {
+	var (
+		out *bytes.Buffer
+		now time.Time
+	)
+
+	render := func(content string, view views.ViewInterface) string {
+		tmpl, err := views.ParseTemplate("test", content)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(views.NewTemplateViewEngine(out, tmpl).Draw(view)).To(Succeed())
+		return out.String()
+	}
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		now = time.Date(2022, time.October, 17, 9, 30, 0, 0, time.Local)
+	})
+
+	It("renders the data the view is given", func() {
+		view := &views.TodoItemView{}
+		view.SetData(&todo.TodoItem{Id: 3, Action: "foo"})
+
+		Expect(render(`{{ .Id }}: {{ .Action }}`, view)).To(Equal("3: foo"))
+	})
+
+	It("formats durations and due dates", func() {
+		duration := 90 * time.Minute
+		view := &views.TodoListView{}
+		view.SetData(todo.NewTodoItemCollection([]*todo.TodoItem{
+			{Id: 1, Action: "foo", DueDate: &now, Duration: &duration},
+			{Id: 2, Action: "bar"},
+		}))
+
+		Expect(render(`{{ range .Enumerate }}{{ .Action }}|{{ duration .Duration }}|{{ formatTime "2006-01-02 15:04" .DueDate }};{{ end }}`, view)).
+			To(Equal("foo|1 hour 30 minutes|2022-10-17 09:30;bar||;"))
+	})
+
+	It("formats events", func() {
+		event := ical.NewEvent("standup")
+		event.SetProperty(ical.ComponentProperty(ical.PropertySummary), "Standup")
+		event.SetProperty(ical.ComponentProperty(ical.PropertyLocation), "Room 1")
+		event.SetStartAt(now)
+		event.SetEndAt(now.Add(15 * time.Minute))
+
+		view := &views.ScheduleView{}
+		view.SetData(&scheduler.Schedule{
+			NextCalendarEvents: []*ical.VEvent{event},
+		})
+
+		Expect(render(`{{ range .NextCalendarEvents }}{{ event . }} in {{ location . }}, ends {{ formatTime "15:04" (eventEnd .) }}{{ end }}`, view)).
+			To(Equal("0930-0945 Standup in Room 1, ends 09:45"))
+	})
+
+	It("returns an error when a template can't be parsed", func() {
+		_, err := views.ParseTemplate("test", `{{ .Id`)
+		Expect(err).To(MatchError(ContainSubstring("parsing template")))
+	})
+
+	Describe("ParseNamedTemplate", func() {
+		It("parses the template with the name in the directory", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "compact.tmpl"), []byte(`{{ .Action }}`), 0644)).To(Succeed())
+
+			tmpl, err := views.ParseNamedTemplate(dir, "compact")
+			Expect(err).ToNot(HaveOccurred())
+
+			view := &views.TodoItemView{}
+			view.SetData(&todo.TodoItem{Action: "foo"})
+			Expect(views.NewTemplateViewEngine(out, tmpl).Draw(view)).To(Succeed())
+			Expect(out.String()).To(Equal("foo"))
+		})
+
+		It("returns an error when there is no template with the name", func() {
+			_, err := views.ParseNamedTemplate(GinkgoT().TempDir(), "missing")
+			Expect(err).To(MatchError(ContainSubstring("there is no template named 'missing'")))
+		})
+	})
+})
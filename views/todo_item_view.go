@@ -0,0 +1,109 @@
+package views
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AP-Hunt/what-next/m/todo"
+	"github.com/fatih/color"
+	"github.com/hako/durafmt"
+)
+
+// TodoItemView shows every field of a single todo item,
+// including the notes and links the list leaves out
+type TodoItemView struct {
+	// Now is the time the item is overdue after
+	Now time.Time
+
+	item *todo.TodoItem
+}
+
+func (v *TodoItemView) Draw(out io.Writer) error {
+	boldWhite := color.New(color.FgWhite, color.Bold)
+	labelStyle := color.New(color.FgHiBlack)
+	overdueStyle := color.New(color.FgRed, color.Bold)
+	tagStyle := color.New(color.FgCyan)
+	blockedStyle := color.New(color.FgMagenta)
+
+	item := v.item
+
+	boldWhite.Fprintf(out, "#%d %s\n", item.Id, item.Action)
+	fmt.Fprintln(out)
+
+	field := func(label string, value string) {
+		fmt.Fprintf(out, "%s %s\n", labelStyle.Sprintf("%-11s", label+":"), value)
+	}
+
+	status := "To do"
+	if item.Completed {
+		status = "Complete"
+		if item.CompletedAt != nil {
+			status = fmt.Sprintf("Completed at %s", item.CompletedAt.Local().Format("15:04 on Monday January _2"))
+		}
+	}
+	if item.IsArchived() {
+		status += ", archived"
+	}
+	field("Status", status)
+	field("Priority", item.Priority.String())
+
+	if item.DueDate != nil {
+		due := item.DueDate.Local().Format("15:04 on Monday January _2 2006")
+		if item.IsOverdue(v.Now) {
+			due = overdueStyle.Sprint(due)
+		}
+		field("Due", due)
+	}
+
+	if item.Duration != nil {
+		field("Duration", durafmt.Parse(*item.Duration).String())
+	}
+
+	if item.IsRecurring() {
+		field("Repeats", *item.Recurrence)
+	}
+
+	if len(item.Tags) > 0 {
+		field("Tags", tagStyle.Sprint(strings.Join(item.Tags, " ")))
+	}
+
+	if item.ParentId != nil {
+		field("Subtask of", fmt.Sprintf("#%d", *item.ParentId))
+	}
+
+	if item.IsBlocked() {
+		blockers := []string{}
+		for _, blockedById := range item.BlockedBy {
+			blockers = append(blockers, fmt.Sprintf("#%d", blockedById))
+		}
+		field("Blocked by", blockedStyle.Sprint(strings.Join(blockers, ", ")))
+	}
+
+	if len(item.Links) > 0 {
+		fmt.Fprintln(out)
+		boldWhite.Fprintln(out, "Links")
+		for _, link := range item.Links {
+			fmt.Fprintf(out, "\t%s\n", link)
+		}
+	}
+
+	if item.Notes != "" {
+		fmt.Fprintln(out)
+		boldWhite.Fprintln(out, "Notes")
+		for _, line := range strings.Split(item.Notes, "\n") {
+			fmt.Fprintf(out, "\t%s\n", line)
+		}
+	}
+
+	return nil
+}
+
+func (v *TodoItemView) SetData(data interface{}) {
+	v.item = data.(*todo.TodoItem)
+}
+
+func (v *TodoItemView) Data() interface{} {
+	return v.item
+}
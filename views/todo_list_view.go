@@ -10,9 +10,16 @@ import (
 	"github.com/isbm/textwrap"
 	"io"
 	"strconv"
+	"strings"
+	"time"
 )
 
+const treeIndent = "\u2800\u2800"
+
 type TodoListView struct {
+	// Now is the time items which were due before it are overdue
+	Now time.Time
+
 	todoItems *todo.TodoItemCollection
 }
 
@@ -27,14 +34,19 @@ func (v *TodoListView) Draw(out io.Writer) error {
 		Cells: []*simpletable.Cell{
 			{Align: simpletable.AlignRight, Text: "#"},
 			{Align: simpletable.AlignCenter, Text: "✓?"},
+			{Align: simpletable.AlignLeft, Text: "Priority"},
 			{Align: simpletable.AlignLeft, Text: "Due"},
 			{Align: simpletable.AlignLeft, Text: "Duration"},
 			{Align: simpletable.AlignLeft, Text: "Action"},
+			{Align: simpletable.AlignLeft, Text: "Tags"},
 		},
 	}
 	tbl.SetStyle(simpletable.StyleCompactLite)
 
 	overdueStyle := color.New(color.FgRed, color.Bold)
+	highPriorityStyle := color.New(color.FgYellow, color.Bold)
+	tagStyle := color.New(color.FgCyan)
+	blockedStyle := color.New(color.FgMagenta)
 
 	textWrapper := textwrap.NewTextWrap()
 	textWrapper.SetWidth(75)
@@ -42,28 +54,52 @@ func (v *TodoListView) Draw(out io.Writer) error {
 	durationWrapper := textwrap.NewTextWrap()
 	durationWrapper.SetWidth(30)
 
-	for _, item := range v.todoItems.Enumerate() {
-		formattedAction := textWrapper.Fill(item.Action)
+	for _, node := range v.todoItems.Tree() {
+		item := node.Item
+
+		// Subtasks are indented under their parents. simpletable trims
+		// whitespace from each line of a cell, so the indent is made
+		// of blank braille patterns, which aren't whitespace.
+		indent := ""
+		if node.Depth > 0 {
+			indent = strings.Repeat(treeIndent, node.Depth-1) + "└ "
+		}
+
+		textWrapper.SetWidth(75 - len([]rune(indent)))
+		formattedAction := indent + strings.ReplaceAll(
+			textWrapper.Fill(item.Action),
+			"\n",
+			"\n"+strings.Repeat(treeIndent, node.Depth),
+		)
+
+		if item.IsBlocked() {
+			blockers := []string{}
+			for _, blockedById := range item.BlockedBy {
+				blockers = append(blockers, fmt.Sprintf("#%d", blockedById))
+			}
+
+			formattedAction += "\n" + strings.Repeat(treeIndent, node.Depth) +
+				blockedStyle.Sprintf("blocked by %s", strings.Join(blockers, ", "))
+		}
 
 		due := ""
 		if item.DueDate != nil {
-			carbonDate := carbon.Time2Carbon(*item.DueDate)
-
-			if carbonDate.IsYesterday() {
-				due = "Yesterday"
-			} else if carbonDate.IsToday() {
-				due = fmt.Sprintf("Today, %s", carbonDate.ToKitchenString())
-			} else if carbonDate.IsTomorrow() {
-				due = fmt.Sprintf("Tomorrow, %s", carbonDate.ToKitchenString())
-			} else {
-				due = carbonDate.Format("dS M y H:i")
-			}
+			due = FormatRelativeDate(*item.DueDate)
 		}
 
-		if item.IsOverdue() {
+		if item.IsOverdue(v.Now) {
 			due = overdueStyle.Sprint(due)
 		}
 
+		if item.IsRecurring() {
+			due = fmt.Sprintf("%s ↻", due)
+		}
+
+		priority := item.Priority.String()
+		if item.Priority == todo.PriorityHighest || item.Priority == todo.PriorityHigh {
+			priority = highPriorityStyle.Sprint(priority)
+		}
+
 		duration := ""
 		if item.Duration != nil {
 			duration = durafmt.Parse(*item.Duration).String()
@@ -74,9 +110,11 @@ func (v *TodoListView) Draw(out io.Writer) error {
 		row := []*simpletable.Cell{
 			{Align: simpletable.AlignRight, Text: strconv.Itoa(item.Id)},
 			{Align: simpletable.AlignCenter, Text: todoCompletedSymbolMap[item.Completed]},
+			{Align: simpletable.AlignLeft, Text: priority},
 			{Align: simpletable.AlignLeft, Text: due},
 			{Align: simpletable.AlignLeft, Text: formattedDuration},
 			{Align: simpletable.AlignLeft, Text: formattedAction},
+			{Align: simpletable.AlignLeft, Text: tagStyle.Sprint(strings.Join(item.Tags, " "))},
 		}
 
 		tbl.Body.Cells = append(tbl.Body.Cells, row)
@@ -87,6 +125,22 @@ func (v *TodoListView) Draw(out io.Writer) error {
 	return nil
 }
 
+// FormatRelativeDate formats a time relative to today when it's
+// close to today, and as a date and time otherwise
+func FormatRelativeDate(t time.Time) string {
+	carbonDate := carbon.Time2Carbon(t)
+
+	if carbonDate.IsYesterday() {
+		return "Yesterday"
+	} else if carbonDate.IsToday() {
+		return fmt.Sprintf("Today, %s", carbonDate.ToKitchenString())
+	} else if carbonDate.IsTomorrow() {
+		return fmt.Sprintf("Tomorrow, %s", carbonDate.ToKitchenString())
+	}
+
+	return carbonDate.Format("dS M y H:i")
+}
+
 func (v *TodoListView) SetData(data interface{}) {
 	v.todoItems = data.(*todo.TodoItemCollection)
 }
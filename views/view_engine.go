@@ -23,3 +23,19 @@ type StdOutViewEngine struct {
 func (ve *StdOutViewEngine) Draw(view ViewInterface) error {
 	return view.Draw(os.Stdout)
 }
+
+// TextViewEngine draws views as text to a writer, such
+// as the output of the command being run
+type TextViewEngine struct {
+	out io.Writer
+}
+
+func NewTextViewEngine(out io.Writer) *TextViewEngine {
+	return &TextViewEngine{
+		out: out,
+	}
+}
+
+func (ve *TextViewEngine) Draw(view ViewInterface) error {
+	return view.Draw(ve.out)
+}